@@ -5,6 +5,7 @@ go 1.24.4
 require (
 	github.com/gdamore/tcell/v2 v2.8.1
 	github.com/rivo/tview v0.0.0-20250625164341-a4a78f1e05cb
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+// DefaultAPIListen is the usual Clash API address
+const DefaultAPIListen = "127.0.0.1:9090"
+
+// v2rayAPITag tags the V2Ray / Xray API inbound, outbound and routing rule
+const v2rayAPITag = "api"
+
+// API is the local control API the core serves for traffic statistics and
+// connection listing: the Clash API for sing-box and mihomo, the StatsService
+// for V2Ray and Xray
+type API struct {
+	Listen string `json:"listen,omitempty"` // host:port, empty disables the API
+	Secret string `json:"secret,omitempty"` // Clash API bearer token; the V2Ray API has no authentication
+}
+
+// enabled reports whether the API is rendered
+func (a API) enabled() bool {
+	return a.Listen != ""
+}
+
+// Validate checks the listen address and that it does not take a proxy inbound port
+func (a API) Validate(in Inbound) error {
+	if !a.enabled() {
+		return nil
+	}
+	_, port, err := splitAPIListen(a.Listen)
+	if err != nil {
+		return err
+	}
+	// Ports clash across hosts as soon as one side listens on all addresses
+	in = in.WithDefaults()
+	if port == in.SocksPort || port == in.HTTPPort {
+		return fmt.Errorf("API port %d is used by a proxy inbound", port)
+	}
+	return nil
+}
+
+// splitAPIListen splits and checks a host:port API address
+func splitAPIListen(listen string) (string, int, error) {
+	host, portText, err := net.SplitHostPort(listen)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid API address %q: %w", listen, err)
+	}
+	if net.ParseIP(host) == nil {
+		return "", 0, fmt.Errorf("invalid API address %q: host must be an IP address", listen)
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil || port < 1 || port > 65535 {
+		return "", 0, fmt.Errorf("invalid API port in %q", listen)
+	}
+	return host, port, nil
+}
+
+// singBoxAPI enables the Clash API, which lists connections and traffic totals at /connections
+func singBoxAPI(cfg map[string]any, a API) {
+	clash := map[string]any{
+		"external_controller": a.Listen,
+	}
+	if a.Secret != "" {
+		clash["secret"] = a.Secret
+	}
+	cfg["experimental"] = map[string]any{
+		"clash_api": clash,
+	}
+}
+
+// v2rayAPI enables the StatsService with counters on every inbound, so traffic
+// is counted once however many outbounds a group or chain passes it through.
+// The API is served by a dokodemo-door inbound routed to the api outbound.
+func v2rayAPI(cfg map[string]any, a API) {
+	host, port, _ := splitAPIListen(a.Listen)
+
+	cfg["stats"] = map[string]any{}
+	cfg["api"] = map[string]any{
+		"tag":      v2rayAPITag,
+		"services": []string{"StatsService"},
+	}
+	cfg["policy"] = map[string]any{
+		"system": map[string]any{
+			"statsInboundUplink":   true,
+			"statsInboundDownlink": true,
+		},
+	}
+
+	cfg["inbounds"] = append(cfg["inbounds"].([]map[string]any), map[string]any{
+		"tag":      v2rayAPITag,
+		"listen":   host,
+		"port":     port,
+		"protocol": "dokodemo-door",
+		"settings": map[string]any{
+			"address": host,
+		},
+	})
+
+	routing := cfg["routing"].(map[string]any)
+	routing["rules"] = append([]map[string]any{
+		{
+			"type":        "field",
+			"inboundTag":  []string{v2rayAPITag},
+			"outboundTag": v2rayAPITag,
+		},
+	}, routing["rules"].([]map[string]any)...)
+}
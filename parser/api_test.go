@@ -0,0 +1,104 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAPI_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		api         API
+		inbound     Inbound
+		expectError bool
+	}{
+		{name: "disabled", api: API{}},
+		{name: "default", api: API{Listen: DefaultAPIListen}},
+		{name: "IPv6", api: API{Listen: "[::1]:9090"}},
+		{name: "missing port", api: API{Listen: "127.0.0.1"}, expectError: true},
+		{name: "host name", api: API{Listen: "localhost:9090"}, expectError: true},
+		{name: "port out of range", api: API{Listen: "127.0.0.1:70000"}, expectError: true},
+		{name: "SOCKS port", api: API{Listen: "127.0.0.1:1080"}, expectError: true},
+		{name: "HTTP port", api: API{Listen: "127.0.0.1:8080"}, inbound: Inbound{HTTPPort: 8080}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.api.Validate(tt.inbound)
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRender_API(t *testing.T) {
+	node, err := Parse("trojan://secret@example.com:443#Trojan")
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := DefaultOptions()
+	opts.API = API{Listen: "127.0.0.1:9091", Secret: "s3cret"}
+
+	t.Run("sing-box", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetSingBox, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+		clash := cfg["experimental"].(map[string]any)["clash_api"].(map[string]any)
+		if clash["external_controller"] != "127.0.0.1:9091" || clash["secret"] != "s3cret" {
+			t.Errorf("clash_api = %v", clash)
+		}
+	})
+
+	t.Run("mihomo", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetMihomo, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+		if cfg["external-controller"] != "127.0.0.1:9091" || cfg["secret"] != "s3cret" {
+			t.Errorf("external-controller = %v, secret = %v", cfg["external-controller"], cfg["secret"])
+		}
+	})
+
+	for _, target := range []Target{TargetV2Ray, TargetXray} {
+		t.Run(string(target), func(t *testing.T) {
+			cfg, err := RenderWithOptions(node, target, opts)
+			if err != nil {
+				t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+			}
+			if _, ok := cfg["stats"]; !ok {
+				t.Error("stats block missing")
+			}
+			api := cfg["api"].(map[string]any)
+			if api["tag"] != v2rayAPITag || !reflect.DeepEqual(api["services"], []string{"StatsService"}) {
+				t.Errorf("api = %v", api)
+			}
+			system := cfg["policy"].(map[string]any)["system"].(map[string]any)
+			if system["statsInboundUplink"] != true || system["statsInboundDownlink"] != true {
+				t.Errorf("policy.system = %v", system)
+			}
+
+			inbounds := cfg["inbounds"].([]map[string]any)
+			last := inbounds[len(inbounds)-1]
+			if last["tag"] != v2rayAPITag || last["protocol"] != "dokodemo-door" || last["listen"] != "127.0.0.1" || last["port"] != 9091 {
+				t.Errorf("API inbound = %v", last)
+			}
+
+			first := cfg["routing"].(map[string]any)["rules"].([]map[string]any)[0]
+			if first["outboundTag"] != v2rayAPITag || !reflect.DeepEqual(first["inboundTag"], []string{v2rayAPITag}) {
+				t.Errorf("first routing rule = %v, want the API rule", first)
+			}
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		cfg, err := Render(node, TargetV2Ray)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := cfg["api"]; ok {
+			t.Error("Render() should not enable the API by default")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package parser
+
+// chained reports whether the nodes render as hops of a chain
+func (o Options) chained() bool {
+	return o.chain
+}
+
+// chainTag returns the outbound tag of the i-th of n hops. The last hop, which
+// reaches the destination, keeps the proxy tag so routing is unchanged.
+func chainTag(i, n int) string {
+	if i == n-1 {
+		return OutboundProxy
+	}
+	return memberTag(i)
+}
+
+// v2rayDetour dials the outbound through the outbound tagged via. V2Ray uses
+// proxySettings with transportLayer so the hop keeps its own transport and
+// TLS; Xray ignores streamSettings under proxySettings, so it dials through
+// sockopt.dialerProxy instead.
+func v2rayDetour(out map[string]any, via string, target Target) {
+	if target != TargetXray {
+		out["proxySettings"] = map[string]any{
+			"tag":            via,
+			"transportLayer": true,
+		}
+		return
+	}
+
+	stream, _ := out["streamSettings"].(map[string]any)
+	if stream == nil {
+		stream = map[string]any{
+			"network":  "tcp",
+			"security": "none",
+		}
+		out["streamSettings"] = stream
+	}
+	stream["sockopt"] = map[string]any{
+		"dialerProxy": via,
+	}
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderChain_SingBox(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@relay.example.com:443#Relay", "trojan://secret@mid.example.com:443#Mid", "trojan://secret@exit.example.com:443#Exit")
+
+	cfg, err := RenderChain(nodes, TargetSingBox, DefaultOptions())
+	if err != nil {
+		t.Fatalf("RenderChain() unexpected error: %v", err)
+	}
+
+	outbounds := cfg["outbounds"].([]map[string]any)
+	tests := []struct {
+		server string
+		tag    string
+		detour any
+	}{
+		{server: "relay.example.com", tag: "proxy-1"},
+		{server: "mid.example.com", tag: "proxy-2", detour: "proxy-1"},
+		{server: "exit.example.com", tag: OutboundProxy, detour: "proxy-2"},
+	}
+	for i, tt := range tests {
+		out := outbounds[i]
+		if out["server"] != tt.server || out["tag"] != tt.tag || out["detour"] != tt.detour {
+			t.Errorf("outbound %d = server %v, tag %v, detour %v; want %s, %s, %v", i, out["server"], out["tag"], out["detour"], tt.server, tt.tag, tt.detour)
+		}
+	}
+	if final := cfg["route"].(map[string]any)["final"]; final != OutboundProxy {
+		t.Errorf("route.final = %v, want the exit hop", final)
+	}
+}
+
+func TestRenderChain_V2Ray(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@relay.example.com:443?sni=relay.example.com&fragment=tlshello%2C100-200%2C10-20#Relay", "ss://YWVzLTI1Ni1nY206cGFzc3dvcmQ=@exit.example.com:8388#Exit")
+
+	t.Run("v2ray", func(t *testing.T) {
+		cfg, err := RenderChain(nodes, TargetV2Ray, DefaultOptions())
+		if err != nil {
+			t.Fatalf("RenderChain() unexpected error: %v", err)
+		}
+		outbounds := cfg["outbounds"].([]map[string]any)
+		if outbounds[0]["tag"] != "proxy-1" || outbounds[0]["proxySettings"] != nil {
+			t.Errorf("first hop = %v, want proxy-1 dialed directly", outbounds[0])
+		}
+		proxySettings, _ := outbounds[1]["proxySettings"].(map[string]any)
+		if outbounds[1]["tag"] != OutboundProxy || proxySettings["tag"] != "proxy-1" || proxySettings["transportLayer"] != true {
+			t.Errorf("exit hop = %v, want proxySettings through proxy-1", outbounds[1])
+		}
+	})
+
+	t.Run("xray", func(t *testing.T) {
+		cfg, err := RenderChain(nodes, TargetXray, DefaultOptions())
+		if err != nil {
+			t.Fatalf("RenderChain() unexpected error: %v", err)
+		}
+		outbounds := cfg["outbounds"].([]map[string]any)
+		if dialer := outbounds[0]["streamSettings"].(map[string]any)["sockopt"].(map[string]any)["dialerProxy"]; dialer != xrayFragmentTag {
+			t.Errorf("first hop dialerProxy = %v, want the fragment outbound", dialer)
+		}
+		stream := outbounds[1]["streamSettings"].(map[string]any)
+		if dialer := stream["sockopt"].(map[string]any)["dialerProxy"]; dialer != "proxy-1" || stream["network"] != "tcp" {
+			t.Errorf("exit hop streamSettings = %v, want tcp dialed through proxy-1", stream)
+		}
+		if outbounds[1]["proxySettings"] != nil {
+			t.Error("Xray hops should not use proxySettings, it drops streamSettings")
+		}
+	})
+}
+
+func TestRenderChain_V2RayUnmatchedTraffic(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@relay.example.com:443?sni=relay.example.com#Relay", "ss://YWVzLTI1Ni1nY206cGFzc3dvcmQ=@exit.example.com:8388#Exit")
+
+	for _, target := range []Target{TargetV2Ray, TargetXray} {
+		t.Run(string(target), func(t *testing.T) {
+			cfg, err := RenderChain(nodes, target, DefaultOptions())
+			if err != nil {
+				t.Fatalf("RenderChain() unexpected error: %v", err)
+			}
+			if got := v2rayUnmatchedOutbound(cfg); got != OutboundProxy {
+				t.Errorf("unmatched traffic goes to %q, want the exit hop %q", got, OutboundProxy)
+			}
+		})
+	}
+}
+
+// v2rayUnmatchedOutbound returns where V2Ray sends a connection no domain or IP
+// rule matches: the first rule matching on the network alone, else the first outbound
+func v2rayUnmatchedOutbound(cfg map[string]any) string {
+	for _, rule := range cfg["routing"].(map[string]any)["rules"].([]map[string]any) {
+		if rule["domain"] != nil || rule["ip"] != nil || rule["port"] != nil || rule["inboundTag"] != nil {
+			continue
+		}
+		if network, _ := rule["network"].(string); strings.Contains(network, "tcp") {
+			if tag, ok := rule["outboundTag"].(string); ok {
+				return tag
+			}
+			return "balancer " + rule["balancerTag"].(string)
+		}
+	}
+	return cfg["outbounds"].([]map[string]any)[0]["tag"].(string)
+}
+
+func TestRenderChain_Mihomo(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@relay.example.com:443#Relay", "trojan://secret@exit.example.com:443#Exit")
+
+	cfg, err := RenderChain(nodes, TargetMihomo, DefaultOptions())
+	if err != nil {
+		t.Fatalf("RenderChain() unexpected error: %v", err)
+	}
+
+	proxies := cfg["proxies"].([]map[string]any)
+	if proxies[0]["name"] != "proxy-1" || proxies[0]["dialer-proxy"] != nil {
+		t.Errorf("first hop = %v", proxies[0])
+	}
+	if proxies[1]["name"] != OutboundProxy || proxies[1]["dialer-proxy"] != "proxy-1" {
+		t.Errorf("exit hop = %v, want dialer-proxy proxy-1", proxies[1])
+	}
+}
+
+func TestRenderChain_Errors(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@relay.example.com:443#Relay", "trojan://secret@exit.example.com:443#Exit")
+
+	if _, err := RenderChain(nodes[:1], TargetSingBox, DefaultOptions()); err == nil {
+		t.Error("RenderChain() should reject a single server")
+	}
+
+	opts := DefaultOptions()
+	opts.Group = Group{Type: GroupURLTest}
+	if _, err := RenderChain(nodes, TargetSingBox, opts); err == nil {
+		t.Error("RenderChain() should reject group options")
+	}
+}
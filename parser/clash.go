@@ -0,0 +1,192 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseClash converts the `proxies:` list of a Clash / Mihomo YAML config into nodes
+func ParseClash(data []byte) (*ImportResult, error) {
+	var doc struct {
+		Proxies []map[string]any `yaml:"proxies"`
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid Clash YAML: %w", err)
+	}
+	if len(doc.Proxies) == 0 {
+		return nil, fmt.Errorf("no proxies found in Clash config")
+	}
+
+	result := &ImportResult{}
+	for i, proxy := range doc.Proxies {
+		name := mapString(proxy, "name")
+		if name == "" {
+			name = fmt.Sprintf("proxy %d", i+1)
+		}
+
+		node, err := clashProxyToNode(proxy)
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		result.Nodes = append(result.Nodes, node)
+	}
+
+	return result, nil
+}
+
+// clashProxyToNode maps a single Clash proxy entry onto a ProxyNode
+func clashProxyToNode(p map[string]any) (*ProxyNode, error) {
+	node := &ProxyNode{
+		Remark: mapString(p, "name"),
+		Server: mapString(p, "server"),
+		Port:   mapInt(p, "port"),
+	}
+
+	proxyType := mapString(p, "type")
+	switch proxyType {
+	case "vmess":
+		node.Protocol = "vmess"
+		node.UUID = mapString(p, "uuid")
+		node.AlterID = mapInt(p, "alterId")
+		node.Security = mapString(p, "cipher")
+		if node.Security == "" {
+			node.Security = "auto"
+		}
+	case "vless":
+		node.Protocol = "vless"
+		node.UUID = mapString(p, "uuid")
+		node.Flow = mapString(p, "flow")
+	case "ss":
+		node.Protocol = "shadowsocks"
+		node.Method = mapString(p, "cipher")
+		node.Password = mapString(p, "password")
+		if plugin := mapString(p, "plugin"); plugin != "" {
+			return nil, fmt.Errorf("shadowsocks plugin %q is not supported", plugin)
+		}
+	case "trojan":
+		node.Protocol = "trojan"
+		node.Password = mapString(p, "password")
+	case "hysteria2":
+		node.Protocol = "hysteria2"
+		node.Password = mapString(p, "password")
+		node.UpMbps = clashMbps(p, "up")
+		node.DownMbps = clashMbps(p, "down")
+		if obfs := mapString(p, "obfs"); obfs != "" {
+			node.ObfsType = obfs
+			node.ObfsPassword = mapString(p, "obfs-password")
+		}
+	case "tuic":
+		node.Protocol = "tuic"
+		node.UUID = mapString(p, "uuid")
+		node.Password = mapString(p, "password")
+		node.CongestionControl = mapString(p, "congestion-controller")
+		node.UDPRelayMode = mapString(p, "udp-relay-mode")
+	case "":
+		return nil, fmt.Errorf("missing proxy type")
+	default:
+		return nil, fmt.Errorf("unsupported proxy type %q", proxyType)
+	}
+
+	node.Transport = clashTransport(p)
+	node.TLS = clashTLS(p, node)
+	node.Mux = clashMux(p)
+
+	if err := validateImported(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// clashTransport maps network and *-opts onto a Transport
+func clashTransport(p map[string]any) Transport {
+	t := Transport{Type: mapString(p, "network")}
+
+	switch t.Type {
+	case "", "tcp":
+		t.Type = "tcp"
+	case "http":
+		// Clash calls tcp with http header obfuscation "http"
+		t.Type = "tcp"
+		t.HeaderType = "http"
+		opts := mapMap(p, "http-opts")
+		t.Path = strings.Join(mapStrings(opts, "path"), ",")
+		t.Host = strings.Join(mapStrings(mapMap(opts, "headers"), "Host"), ",")
+	case "ws":
+		opts := mapMap(p, "ws-opts")
+		t.Path = mapString(opts, "path")
+		t.Host = mapString(mapMap(opts, "headers"), "Host")
+	case "h2":
+		opts := mapMap(p, "h2-opts")
+		t.Path = mapString(opts, "path")
+		t.Host = strings.Join(mapStrings(opts, "host"), ",")
+	case "grpc":
+		t.ServiceName = mapString(mapMap(p, "grpc-opts"), "grpc-service-name")
+	}
+
+	return t
+}
+
+// clashTLS maps tls, sni/servername, reality-opts and friends onto TLS
+func clashTLS(p map[string]any, node *ProxyNode) TLS {
+	enabled := mapBool(p, "tls")
+	switch node.Protocol {
+	case "trojan", "hysteria2", "tuic":
+		enabled = true // always TLS based
+	case "shadowsocks":
+		enabled = false
+	}
+	if !enabled {
+		return TLS{}
+	}
+
+	tls := TLS{
+		Enabled:     true,
+		ServerName:  mapString(p, "servername"),
+		ALPN:        mapStrings(p, "alpn"),
+		Fingerprint: mapString(p, "client-fingerprint"),
+		Insecure:    mapBool(p, "skip-cert-verify"),
+	}
+	if tls.ServerName == "" {
+		tls.ServerName = mapString(p, "sni")
+	}
+	if tls.ServerName == "" && node.Protocol != "vmess" && node.Protocol != "vless" {
+		tls.ServerName = node.Server
+	}
+
+	if opts := mapMap(p, "reality-opts"); opts != nil {
+		tls.Reality = Reality{
+			Enabled:   true,
+			PublicKey: mapString(opts, "public-key"),
+			ShortID:   mapString(opts, "short-id"),
+		}
+	}
+
+	return tls
+}
+
+// clashMux maps the smux block onto Mux
+func clashMux(p map[string]any) Mux {
+	opts := mapMap(p, "smux")
+	if opts == nil || !mapBool(opts, "enabled") {
+		return Mux{}
+	}
+	return Mux{
+		Enabled:        true,
+		Protocol:       mapString(opts, "protocol"),
+		MaxConnections: mapInt(opts, "max-connections"),
+	}
+}
+
+// clashMbps reads a bandwidth field such as 100 or "100 Mbps"
+func clashMbps(m map[string]any, key string) int {
+	fields := strings.Fields(mapString(m, key))
+	if len(fields) == 0 {
+		return 0
+	}
+	n, _ := strconv.Atoi(fields[0])
+	return n
+}
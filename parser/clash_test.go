@@ -0,0 +1,266 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+const testClashYAML = `
+port: 7890
+proxies:
+  - name: "vmess-ws"
+    type: vmess
+    server: vmess.example.com
+    port: 443
+    uuid: 11111111-1111-1111-1111-111111111111
+    alterId: 0
+    cipher: auto
+    tls: true
+    servername: cdn.example.com
+    network: ws
+    ws-opts:
+      path: /ws
+      headers:
+        Host: cdn.example.com
+  - name: vless-reality
+    type: vless
+    server: vless.example.com
+    port: "8443"
+    uuid: 12345678-1234-1234-1234-123456789012
+    flow: xtls-rprx-vision
+    tls: true
+    servername: www.microsoft.com
+    client-fingerprint: chrome
+    reality-opts:
+      public-key: PUBLICKEY
+      short-id: 6ba85179
+  - name: ss-node
+    type: ss
+    server: ss.example.com
+    port: 8388
+    cipher: aes-256-gcm
+    password: secret
+  - name: trojan-grpc
+    type: trojan
+    server: trojan.example.com
+    port: 443
+    password: trojanpass
+    sni: sni.example.com
+    skip-cert-verify: true
+    network: grpc
+    grpc-opts:
+      grpc-service-name: tunnel
+  - name: hy2
+    type: hysteria2
+    server: hy2.example.com
+    port: 8443
+    password: hy2pass
+    up: "50 Mbps"
+    down: 200
+    obfs: salamander
+    obfs-password: obfspass
+  - name: snell-node
+    type: snell
+    server: snell.example.com
+    port: 443
+  - name: ss-plugin
+    type: ss
+    server: ss.example.com
+    port: 8388
+    cipher: aes-256-gcm
+    password: secret
+    plugin: obfs
+  - name: no-port
+    type: trojan
+    server: trojan.example.com
+    password: trojanpass
+proxy-groups: []
+`
+
+func TestParseClash(t *testing.T) {
+	result, err := ParseClash([]byte(testClashYAML))
+	if err != nil {
+		t.Fatalf("ParseClash() unexpected error: %v", err)
+	}
+
+	if len(result.Nodes) != 5 {
+		t.Fatalf("ParseClash() returned %d nodes, want 5", len(result.Nodes))
+	}
+	if len(result.Skipped) != 3 {
+		t.Fatalf("ParseClash() skipped %v, want 3 entries", result.Skipped)
+	}
+	for i, prefix := range []string{"snell-node:", "ss-plugin:", "no-port:"} {
+		if !strings.HasPrefix(result.Skipped[i], prefix) {
+			t.Errorf("ParseClash() skipped[%d] = %q, want prefix %q", i, result.Skipped[i], prefix)
+		}
+	}
+
+	vmess := result.Nodes[0]
+	if vmess.Protocol != "vmess" || vmess.Remark != "vmess-ws" || vmess.Transport.Type != "ws" ||
+		vmess.Transport.Path != "/ws" || vmess.Transport.Host != "cdn.example.com" || vmess.TLS.ServerName != "cdn.example.com" {
+		t.Errorf("ParseClash() vmess node = %+v", vmess)
+	}
+
+	vless := result.Nodes[1]
+	if vless.Port != 8443 || vless.Flow != "xtls-rprx-vision" || !vless.TLS.Reality.Enabled ||
+		vless.TLS.Reality.PublicKey != "PUBLICKEY" || vless.TLS.Fingerprint != "chrome" {
+		t.Errorf("ParseClash() vless node = %+v", vless)
+	}
+
+	ss := result.Nodes[2]
+	if ss.Protocol != "shadowsocks" || ss.Method != "aes-256-gcm" || ss.Password != "secret" || ss.TLS.Enabled {
+		t.Errorf("ParseClash() ss node = %+v", ss)
+	}
+
+	trojan := result.Nodes[3]
+	if trojan.Transport.ServiceName != "tunnel" || trojan.TLS.ServerName != "sni.example.com" || !trojan.TLS.Insecure {
+		t.Errorf("ParseClash() trojan node = %+v", trojan)
+	}
+
+	hy2 := result.Nodes[4]
+	if hy2.UpMbps != 50 || hy2.DownMbps != 200 || hy2.ObfsType != "salamander" || hy2.ObfsPassword != "obfspass" {
+		t.Errorf("ParseClash() hysteria2 node = %+v", hy2)
+	}
+}
+
+func TestParseClash_Mapping(t *testing.T) {
+	tests := []struct {
+		name  string
+		proxy string
+		check func(t *testing.T, node *ProxyNode)
+	}{
+		{
+			name: "vmess http obfuscation",
+			proxy: `type: vmess
+    server: vmess.example.com
+    port: 80
+    uuid: 11111111-1111-1111-1111-111111111111
+    network: http
+    http-opts:
+      path: [/a, /b]
+      headers:
+        Host: [a.example.com]`,
+			check: func(t *testing.T, node *ProxyNode) {
+				want := Transport{Type: "tcp", HeaderType: "http", Path: "/a,/b", Host: "a.example.com"}
+				if node.Transport != want || node.Security != "auto" || node.TLS.Enabled {
+					t.Errorf("node = %+v, want transport %+v, cipher auto and no TLS", node, want)
+				}
+			},
+		},
+		{
+			name: "vless h2 with smux",
+			proxy: `type: vless
+    server: vless.example.com
+    port: 443
+    uuid: 12345678-1234-1234-1234-123456789012
+    tls: true
+    alpn: [h2]
+    network: h2
+    h2-opts:
+      path: /h2
+      host: [h2.example.com]
+    smux:
+      enabled: true
+      protocol: h2mux
+      max-connections: 4`,
+			check: func(t *testing.T, node *ProxyNode) {
+				if node.Transport.Type != "h2" || node.Transport.Path != "/h2" || node.Transport.Host != "h2.example.com" {
+					t.Errorf("transport = %+v", node.Transport)
+				}
+				if !node.TLS.Enabled || node.TLS.ServerName != "" || len(node.TLS.ALPN) != 1 {
+					t.Errorf("TLS = %+v, want enabled without a server name", node.TLS)
+				}
+				if node.Mux != (Mux{Enabled: true, Protocol: "h2mux", MaxConnections: 4}) {
+					t.Errorf("mux = %+v", node.Mux)
+				}
+			},
+		},
+		{
+			name: "disabled smux",
+			proxy: `type: trojan
+    server: trojan.example.com
+    port: 443
+    password: secret
+    smux:
+      enabled: false
+      protocol: smux`,
+			check: func(t *testing.T, node *ProxyNode) {
+				if node.Mux.Enabled || node.TLS.ServerName != "trojan.example.com" {
+					t.Errorf("node = %+v, want no mux and the server as SNI", node)
+				}
+			},
+		},
+		{
+			name: "tuic",
+			proxy: `type: tuic
+    server: tuic.example.com
+    port: 443
+    uuid: 11111111-1111-1111-1111-111111111111
+    password: secret
+    congestion-controller: bbr
+    udp-relay-mode: native
+    sni: sni.example.com`,
+			check: func(t *testing.T, node *ProxyNode) {
+				if node.CongestionControl != "bbr" || node.UDPRelayMode != "native" || node.TLS.ServerName != "sni.example.com" {
+					t.Errorf("node = %+v", node)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseClash([]byte("proxies:\n  - name: " + tt.name + "\n    " + tt.proxy + "\n"))
+			if err != nil {
+				t.Fatalf("ParseClash() unexpected error: %v", err)
+			}
+			if len(result.Nodes) != 1 {
+				t.Fatalf("ParseClash() = %d nodes, skipped %v", len(result.Nodes), result.Skipped)
+			}
+			tt.check(t, result.Nodes[0])
+		})
+	}
+}
+
+func TestParseClash_NodesEncodeToParsableLinks(t *testing.T) {
+	result, err := ParseClash([]byte(testClashYAML))
+	if err != nil {
+		t.Fatalf("ParseClash() unexpected error: %v", err)
+	}
+
+	for _, node := range result.Nodes {
+		link, err := Encode(node)
+		if err != nil {
+			t.Errorf("Encode(%s) unexpected error: %v", node.Remark, err)
+			continue
+		}
+
+		parsed, err := Parse(link)
+		if err != nil {
+			t.Errorf("Parse(Encode(%s)) unexpected error: %v (link %s)", node.Remark, err, link)
+			continue
+		}
+		if parsed.Remark != node.Remark || parsed.Server != node.Server || parsed.Port != node.Port || parsed.Protocol != node.Protocol {
+			t.Errorf("Parse(Encode(%s)) = %+v, want %+v", node.Remark, parsed, node)
+		}
+	}
+}
+
+func TestParseClash_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty document", ""},
+		{"not yaml", "proxies: [unterminated"},
+		{"no proxies", "port: 7890\nmode: rule\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseClash([]byte(tt.input)); err == nil {
+				t.Errorf("ParseClash(%q) expected error but got none", tt.input)
+			}
+		})
+	}
+}
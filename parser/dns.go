@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultRemoteDNS = "https://1.1.1.1/dns-query"
+	DefaultDirectDNS = "https://9.9.9.9/dns-query"
+
+	// bootstrapDNS resolves the host name of DoH / DoT servers given by name
+	bootstrapDNS = "9.9.9.9"
+
+	fakeIPRange4 = "198.18.0.0/15"
+	fakeIPRange6 = "fc00::/18"
+)
+
+// DNSStrategies are the accepted DNS.Strategy values, empty keeps the core default
+var DNSStrategies = []string{"ipv4-only", "ipv6-only", "prefer-ipv4", "prefer-ipv6"}
+
+// DNS describes how the core resolves names. Server addresses are plain
+// (1.1.1.1, udp://1.1.1.1:53), tcp://, tls:// (DoT) or https:// (DoH).
+type DNS struct {
+	Remote   string `json:"remote,omitempty"`   // queried through the proxy, default Cloudflare DoH
+	Direct   string `json:"direct,omitempty"`   // for direct domains and the proxy server itself, default Quad9 DoH
+	FakeIP   bool   `json:"fake_ip,omitempty"`  // answer A/AAAA queries from 198.18.0.0/15
+	Strategy string `json:"strategy,omitempty"` // one of DNSStrategies
+}
+
+// dnsServer is a parsed DNS server address
+type dnsServer struct {
+	Scheme string // udp, tcp, tls or https
+	Host   string
+	Port   int
+	URL    string // the address as written, normalised for plain servers
+}
+
+// WithDefaults fills in the remote and direct resolvers when they are unset
+func (d DNS) WithDefaults() DNS {
+	if d.Remote == "" {
+		d.Remote = DefaultRemoteDNS
+	}
+	if d.Direct == "" {
+		d.Direct = DefaultDirectDNS
+	}
+	return d
+}
+
+// Validate checks the server addresses and strategy after defaults are applied
+func (d DNS) Validate() error {
+	d = d.WithDefaults()
+
+	if _, err := parseDNSServer(d.Remote); err != nil {
+		return fmt.Errorf("remote DNS: %w", err)
+	}
+	if _, err := parseDNSServer(d.Direct); err != nil {
+		return fmt.Errorf("direct DNS: %w", err)
+	}
+	if d.Strategy != "" && !containsString(DNSStrategies, d.Strategy) {
+		return fmt.Errorf("invalid DNS strategy: %s", d.Strategy)
+	}
+	return nil
+}
+
+// parseDNSServer splits a DNS server address into scheme, host and port
+func parseDNSServer(address string) (dnsServer, error) {
+	if address == "" {
+		return dnsServer{}, fmt.Errorf("empty server address")
+	}
+	if !strings.Contains(address, "://") {
+		address = "udp://" + address
+	}
+
+	u, err := url.Parse(address)
+	if err != nil {
+		return dnsServer{}, fmt.Errorf("invalid server address %q: %w", address, err)
+	}
+
+	defaultPorts := map[string]int{"udp": 53, "tcp": 53, "tls": 853, "https": 443}
+	port, ok := defaultPorts[u.Scheme]
+	if !ok {
+		return dnsServer{}, fmt.Errorf("unsupported DNS scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return dnsServer{}, fmt.Errorf("missing host in %q", address)
+	}
+	if p := u.Port(); p != "" {
+		if port, err = strconv.Atoi(p); err != nil || port < 1 || port > 65535 {
+			return dnsServer{}, fmt.Errorf("invalid port in %q", address)
+		}
+	}
+
+	server := dnsServer{Scheme: u.Scheme, Host: u.Hostname(), Port: port, URL: address}
+	if u.Scheme == "udp" {
+		server.URL = u.Host
+	}
+	return server, nil
+}
+
+// isIP reports whether the server is addressed by IP and needs no bootstrap resolver
+func (s dnsServer) isIP() bool {
+	return net.ParseIP(s.Host) != nil
+}
+
+// directDomains returns the domain suffixes and geosite categories routed
+// direct; they are resolved by the direct resolver so they get local answers.
+func (r Routing) directDomains() (suffixes, geosites []string) {
+	for _, rule := range r.rules() {
+		if rule.Outbound != OutboundDirect {
+			continue
+		}
+		suffixes = append(suffixes, rule.DomainSuffix...)
+		geosites = append(geosites, rule.GeoSite...)
+	}
+	return suffixes, geosites
+}
+
+// serverHostnames returns the servers that are host names and must be resolved
+// before the proxies are reachable, skipping IP addresses and duplicates
+func serverHostnames(nodes []*ProxyNode) []string {
+	var hosts []string
+	for _, node := range nodes {
+		if net.ParseIP(node.Server) == nil && !containsString(hosts, node.Server) {
+			hosts = append(hosts, node.Server)
+		}
+	}
+	return hosts
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseDNSServer(t *testing.T) {
+	tests := []struct {
+		address     string
+		want        dnsServer
+		expectError bool
+	}{
+		{address: "1.1.1.1", want: dnsServer{Scheme: "udp", Host: "1.1.1.1", Port: 53, URL: "1.1.1.1"}},
+		{address: "udp://8.8.8.8:5353", want: dnsServer{Scheme: "udp", Host: "8.8.8.8", Port: 5353, URL: "8.8.8.8:5353"}},
+		{address: "tcp://9.9.9.9", want: dnsServer{Scheme: "tcp", Host: "9.9.9.9", Port: 53, URL: "tcp://9.9.9.9"}},
+		{address: "tls://dns.google", want: dnsServer{Scheme: "tls", Host: "dns.google", Port: 853, URL: "tls://dns.google"}},
+		{address: "https://1.1.1.1/dns-query", want: dnsServer{Scheme: "https", Host: "1.1.1.1", Port: 443, URL: "https://1.1.1.1/dns-query"}},
+		{address: "", expectError: true},
+		{address: "quic://dns.adguard.com", expectError: true},
+		{address: "https:///dns-query", expectError: true},
+		{address: "tcp://1.1.1.1:99999", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := parseDNSServer(tt.address)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseDNSServer() error = %v, expectError %v", err, tt.expectError)
+			}
+			if !tt.expectError && got != tt.want {
+				t.Errorf("parseDNSServer() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDNS_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		dns         DNS
+		expectError bool
+	}{
+		{name: "defaults", dns: DNS{}},
+		{name: "DoT and plain", dns: DNS{Remote: "tls://1.1.1.1", Direct: "223.5.5.5", Strategy: "ipv4-only"}},
+		{name: "invalid remote", dns: DNS{Remote: "ftp://1.1.1.1"}, expectError: true},
+		{name: "invalid direct", dns: DNS{Direct: "https://"}, expectError: true},
+		{name: "invalid strategy", dns: DNS{Strategy: "ipv4"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.dns.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRenderWithOptions_DNS(t *testing.T) {
+	node, err := Parse("trojan://secret@example.com:443#Trojan")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.DNS = DNS{Remote: "https://dns.google/dns-query", Direct: "223.5.5.5", FakeIP: true, Strategy: "ipv4-only"}
+	opts.Routing.Rules = []Rule{{Outbound: OutboundDirect, DomainSuffix: []string{"example.cn"}, GeoSite: []string{"cn"}}}
+
+	t.Run("sing-box", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetSingBox, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+
+		dns := cfg["dns"].(map[string]any)
+		if dns["final"] != "remote" || dns["strategy"] != "ipv4-only" {
+			t.Errorf("dns final/strategy = %v/%v, want remote/ipv4-only", dns["final"], dns["strategy"])
+		}
+
+		servers := dns["servers"].([]map[string]any)
+		tags := []string{}
+		for _, server := range servers {
+			tags = append(tags, server["tag"].(string))
+		}
+		if strings.Join(tags, ",") != "remote,direct,bootstrap,fakeip" {
+			t.Errorf("dns server tags = %v, want remote, direct, bootstrap, fakeip", tags)
+		}
+		if servers[0]["detour"] != OutboundProxy || servers[0]["address_resolver"] != "bootstrap" {
+			t.Errorf("remote server = %v, want proxy detour and bootstrap resolver", servers[0])
+		}
+		if servers[1]["address"] != "223.5.5.5" {
+			t.Errorf("direct server address = %v, want 223.5.5.5", servers[1]["address"])
+		}
+
+		rules := dns["rules"].([]map[string]any)
+		if len(rules) != 3 {
+			t.Fatalf("dns rules = %v, want proxy server, direct domains and fake-ip", rules)
+		}
+		if domains := rules[0]["domain"].([]string); domains[0] != "example.com" {
+			t.Errorf("first dns rule = %v, want the proxy server resolved direct", rules[0])
+		}
+		if sets := rules[1]["rule_set"].([]string); sets[0] != "geosite-cn" {
+			t.Errorf("direct domain rule = %v, want geosite-cn", rules[1])
+		}
+		if rules[2]["server"] != "fakeip" {
+			t.Errorf("last dns rule = %v, want fakeip", rules[2])
+		}
+	})
+
+	t.Run("v2ray", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetV2Ray, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+
+		dns := cfg["dns"].(map[string]any)
+		if dns["queryStrategy"] != "UseIPv4" {
+			t.Errorf("queryStrategy = %v, want UseIPv4", dns["queryStrategy"])
+		}
+		servers := dns["servers"].([]any)
+		if len(servers) != 3 || servers[1] != "fakedns" {
+			t.Fatalf("dns servers = %v, want direct, fakedns, remote", servers)
+		}
+		direct := servers[0].(map[string]any)
+		if direct["address"] != "223.5.5.5" || direct["port"] != 53 {
+			t.Errorf("direct server = %v, want 223.5.5.5:53", direct)
+		}
+		if got := strings.Join(direct["domains"].([]string), ","); got != "full:example.com,domain:local,domain:example.cn,geosite:cn" {
+			t.Errorf("direct server domains = %s", got)
+		}
+		if remote := servers[2].(map[string]any); remote["address"] != "https://dns.google/dns-query" {
+			t.Errorf("remote server = %v", remote)
+		}
+		if _, ok := cfg["fakedns"]; !ok {
+			t.Error("fakedns pool missing")
+		}
+
+		rules := cfg["routing"].(map[string]any)["rules"].([]map[string]any)
+		if rules[0]["outboundTag"] != OutboundProxy || rules[0]["domain"].([]string)[0] != "full:dns.google" {
+			t.Errorf("first routing rule = %v, want the remote resolver via proxy", rules[0])
+		}
+		if rules[1]["outboundTag"] != OutboundDirect || rules[1]["ip"].([]string)[0] != "223.5.5.5" {
+			t.Errorf("second routing rule = %v, want the direct resolver direct", rules[1])
+		}
+	})
+
+	t.Run("v2ray rejects DoT", func(t *testing.T) {
+		dot := opts
+		dot.DNS.Remote = "tls://1.1.1.1"
+		if _, err := RenderWithOptions(node, TargetV2Ray, dot); err == nil {
+			t.Error("RenderWithOptions() should reject DNS over TLS for V2Ray")
+		}
+		if _, err := RenderWithOptions(node, TargetSingBox, dot); err != nil {
+			t.Errorf("sing-box should accept DNS over TLS: %v", err)
+		}
+	})
+
+	t.Run("mihomo", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetMihomo, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+
+		dns := cfg["dns"].(map[string]any)
+		if dns["enhanced-mode"] != "fake-ip" || dns["ipv6"] != false {
+			t.Errorf("dns = %v, want fake-ip without ipv6", dns)
+		}
+		if ns := dns["nameserver"].([]string); ns[0] != "https://dns.google/dns-query" {
+			t.Errorf("nameserver = %v", ns)
+		}
+		if ns := dns["proxy-server-nameserver"].([]string); ns[0] != "223.5.5.5" {
+			t.Errorf("proxy-server-nameserver = %v", ns)
+		}
+		policy := dns["nameserver-policy"].(map[string]any)
+		if policy["+.example.cn"] != "223.5.5.5" || policy["geosite:cn"] != "223.5.5.5" {
+			t.Errorf("nameserver-policy = %v", policy)
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// Group types, named after the sing-box outbounds
+const (
+	GroupURLTest  = "urltest"  // use the server with the lowest health check latency
+	GroupFallback = "fallback" // stay on a server until its health check fails
+	GroupSelector = "selector" // switch servers by hand, starting with the first
+)
+
+const (
+	DefaultGroupURL       = "https://www.gstatic.com/generate_204"
+	DefaultGroupInterval  = 180 // seconds
+	DefaultGroupTolerance = 50  // milliseconds
+
+	// singBoxFallbackTolerance makes a sing-box urltest stick to the selected
+	// server until it fails, as sing-box has no fallback outbound
+	singBoxFallbackTolerance = 65535
+)
+
+// GroupTypes are the valid Group.Type values
+var GroupTypes = []string{GroupURLTest, GroupFallback, GroupSelector}
+
+// Group puts several nodes behind one proxy outbound so the core picks
+// between them and fails over on its own
+type Group struct {
+	Type      string `json:"type,omitempty"`      // default urltest
+	URL       string `json:"url,omitempty"`       // health check URL
+	Interval  int    `json:"interval,omitempty"`  // seconds between health checks
+	Tolerance int    `json:"tolerance,omitempty"` // ms a server must be faster by before urltest switches
+}
+
+// WithDefaults fills in unset fields
+func (g Group) WithDefaults() Group {
+	if g.Type == "" {
+		g.Type = GroupURLTest
+	}
+	if g.URL == "" {
+		g.URL = DefaultGroupURL
+	}
+	if g.Interval == 0 {
+		g.Interval = DefaultGroupInterval
+	}
+	if g.Tolerance == 0 {
+		g.Tolerance = DefaultGroupTolerance
+	}
+	return g
+}
+
+// Validate checks the type, health check URL and timings
+func (g Group) Validate() error {
+	if g.Type != "" && !containsString(GroupTypes, g.Type) {
+		return fmt.Errorf("invalid group type: %s", g.Type)
+	}
+	if g.URL != "" {
+		u, err := url.Parse(g.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid health check URL: %s", g.URL)
+		}
+	}
+	if g.Interval < 0 {
+		return fmt.Errorf("health check interval cannot be negative")
+	}
+	if g.Tolerance < 0 || g.Tolerance > singBoxFallbackTolerance {
+		return fmt.Errorf("tolerance must be between 0 and %d ms", singBoxFallbackTolerance)
+	}
+	return nil
+}
+
+// grouped reports whether the nodes render as members of a group outbound
+func (o Options) grouped() bool {
+	return o.Group.Type != ""
+}
+
+// memberTag returns the outbound tag of the i-th group member
+func memberTag(i int) string {
+	return fmt.Sprintf("%s-%d", OutboundProxy, i+1)
+}
+
+// memberTags returns the outbound tags of n group members
+func memberTags(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = memberTag(i)
+	}
+	return tags
+}
+
+// singBoxGroup renders the group outbound over the member outbounds
+func singBoxGroup(g Group, n int) map[string]any {
+	tags := memberTags(n)
+	if g.Type == GroupSelector {
+		return map[string]any{
+			"type":      "selector",
+			"tag":       OutboundProxy,
+			"outbounds": tags,
+			"default":   tags[0],
+		}
+	}
+
+	tolerance := g.Tolerance
+	if g.Type == GroupFallback {
+		tolerance = singBoxFallbackTolerance
+	}
+	return map[string]any{
+		"type":      "urltest",
+		"tag":       OutboundProxy,
+		"outbounds": tags,
+		"url":       g.URL,
+		"interval":  fmt.Sprintf("%ds", g.Interval),
+		"tolerance": tolerance,
+	}
+}
+
+// v2rayBalancer sends proxied traffic to a leastPing balancer over the member
+// outbounds, measured by the observatory. V2Ray and Xray cannot switch servers
+// by hand, and their balancers only use servers that pass the health check,
+// so a fallback group is rendered like urltest.
+func v2rayBalancer(cfg map[string]any, g Group, target Target) error {
+	if g.Type == GroupSelector {
+		return fmt.Errorf("selector groups are not supported by %s, use sing-box or mihomo", v2rayName(target))
+	}
+
+	selector := []string{OutboundProxy + "-"}
+	routing := cfg["routing"].(map[string]any)
+	rules := routing["rules"].([]map[string]any)
+	for _, rule := range rules {
+		if rule["outboundTag"] == OutboundProxy {
+			delete(rule, "outboundTag")
+			rule["balancerTag"] = OutboundProxy
+		}
+	}
+	// Without a catch-all the first outbound, a single member, would get the rest
+	routing["rules"] = append(rules, map[string]any{
+		"type":        "field",
+		"network":     "tcp,udp",
+		"balancerTag": OutboundProxy,
+	})
+	routing["balancers"] = []map[string]any{
+		{
+			"tag":      OutboundProxy,
+			"selector": selector,
+			"strategy": map[string]any{"type": "leastPing"},
+		},
+	}
+
+	cfg["observatory"] = map[string]any{
+		"subjectSelector": selector,
+		"probeURL":        g.URL,
+		"probeInterval":   fmt.Sprintf("%ds", g.Interval),
+	}
+	return nil
+}
+
+// mihomoProxyGroup renders the proxy group over the member proxies
+func mihomoProxyGroup(g Group, n int) map[string]any {
+	group := map[string]any{
+		"name":    OutboundProxy,
+		"proxies": memberTags(n),
+	}
+	switch g.Type {
+	case GroupSelector:
+		group["type"] = "select"
+		return group
+	case GroupFallback:
+		group["type"] = "fallback"
+	default:
+		group["type"] = "url-test"
+		group["tolerance"] = g.Tolerance
+	}
+	group["url"] = g.URL
+	group["interval"] = g.Interval
+	return group
+}
@@ -0,0 +1,174 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+// groupNodes parses the links used as group members
+func groupNodes(t *testing.T, links ...string) []*ProxyNode {
+	t.Helper()
+	var nodes []*ProxyNode
+	for _, link := range links {
+		node, err := Parse(link)
+		if err != nil {
+			t.Fatalf("Parse(%q) unexpected error: %v", link, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func TestGroup_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		group       Group
+		expectError bool
+	}{
+		{name: "defaults", group: Group{}.WithDefaults()},
+		{name: "fallback with URL", group: Group{Type: GroupFallback, URL: "http://cp.cloudflare.com/", Interval: 60}},
+		{name: "unknown type", group: Group{Type: "load-balance"}, expectError: true},
+		{name: "URL without scheme", group: Group{URL: "cp.cloudflare.com"}, expectError: true},
+		{name: "negative interval", group: Group{Interval: -1}, expectError: true},
+		{name: "tolerance too large", group: Group{Tolerance: 70000}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.group.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRenderGroup_SingBox(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@a.example.com:443#A", "trojan://secret@b.example.com:443#B")
+
+	tests := []struct {
+		name      string
+		group     Group
+		wantType  string
+		tolerance any
+	}{
+		{name: "urltest", group: Group{}, wantType: "urltest", tolerance: DefaultGroupTolerance},
+		{name: "fallback", group: Group{Type: GroupFallback}, wantType: "urltest", tolerance: singBoxFallbackTolerance},
+		{name: "selector", group: Group{Type: GroupSelector}, wantType: "selector"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Group = tt.group
+			cfg, err := RenderGroup(nodes, TargetSingBox, opts)
+			if err != nil {
+				t.Fatalf("RenderGroup() unexpected error: %v", err)
+			}
+
+			outbounds := cfg["outbounds"].([]map[string]any)
+			group := outbounds[0]
+			if group["type"] != tt.wantType || group["tag"] != OutboundProxy {
+				t.Fatalf("first outbound = %v, want a %s tagged %s", group, tt.wantType, OutboundProxy)
+			}
+			if !reflect.DeepEqual(group["outbounds"], []string{"proxy-1", "proxy-2"}) {
+				t.Errorf("group members = %v", group["outbounds"])
+			}
+			if tt.tolerance != nil && group["tolerance"] != tt.tolerance {
+				t.Errorf("tolerance = %v, want %v", group["tolerance"], tt.tolerance)
+			}
+			if outbounds[1]["tag"] != "proxy-1" || outbounds[2]["tag"] != "proxy-2" || outbounds[2]["server"] != "b.example.com" {
+				t.Errorf("member outbounds = %v, %v", outbounds[1], outbounds[2])
+			}
+
+			// Both servers are resolved by the direct resolver
+			rule := cfg["dns"].(map[string]any)["rules"].([]map[string]any)[0]
+			if !reflect.DeepEqual(rule["domain"], []string{"a.example.com", "b.example.com"}) {
+				t.Errorf("direct DNS rule = %v, want both servers", rule)
+			}
+		})
+	}
+}
+
+func TestRenderGroup_V2Ray(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@a.example.com:443#A", "trojan://secret@b.example.com:443#B")
+
+	for _, target := range []Target{TargetV2Ray, TargetXray} {
+		t.Run(string(target), func(t *testing.T) {
+			opts := DefaultOptions()
+			opts.Group = Group{Interval: 60}
+			cfg, err := RenderGroup(nodes, target, opts)
+			if err != nil {
+				t.Fatalf("RenderGroup() unexpected error: %v", err)
+			}
+
+			outbounds := cfg["outbounds"].([]map[string]any)
+			if outbounds[0]["tag"] != "proxy-1" || outbounds[1]["tag"] != "proxy-2" {
+				t.Errorf("member outbounds = %v, %v", outbounds[0]["tag"], outbounds[1]["tag"])
+			}
+
+			routing := cfg["routing"].(map[string]any)
+			balancer := routing["balancers"].([]map[string]any)[0]
+			if balancer["tag"] != OutboundProxy || balancer["strategy"].(map[string]any)["type"] != "leastPing" {
+				t.Errorf("balancer = %v", balancer)
+			}
+			rules := routing["rules"].([]map[string]any)
+			for _, rule := range rules {
+				if rule["outboundTag"] == OutboundProxy {
+					t.Errorf("rule %v still targets the proxy outbound instead of the balancer", rule)
+				}
+			}
+			if last := rules[len(rules)-1]; last["balancerTag"] != OutboundProxy || last["network"] != "tcp,udp" {
+				t.Errorf("last rule = %v, want a catch-all to the balancer", last)
+			}
+
+			observatory := cfg["observatory"].(map[string]any)
+			if observatory["probeURL"] != DefaultGroupURL || observatory["probeInterval"] != "60s" {
+				t.Errorf("observatory = %v", observatory)
+			}
+
+			opts.Group = Group{Type: GroupSelector}
+			if _, err := RenderGroup(nodes, target, opts); err == nil {
+				t.Error("RenderGroup() should reject selector groups")
+			}
+		})
+	}
+}
+
+func TestRenderGroup_Mihomo(t *testing.T) {
+	nodes := groupNodes(t, "trojan://secret@a.example.com:443#A", "trojan://secret@b.example.com:443#B")
+
+	opts := DefaultOptions()
+	opts.Group = Group{Type: GroupFallback}
+	cfg, err := RenderGroup(nodes, TargetMihomo, opts)
+	if err != nil {
+		t.Fatalf("RenderGroup() unexpected error: %v", err)
+	}
+
+	proxies := cfg["proxies"].([]map[string]any)
+	if len(proxies) != 2 || proxies[0]["name"] != "proxy-1" || proxies[1]["name"] != "proxy-2" {
+		t.Errorf("proxies = %v", proxies)
+	}
+	groups := cfg["proxy-groups"].([]map[string]any)
+	if len(groups) != 2 {
+		t.Fatalf("proxy-groups = %v, want the PROXY selector and the server group", groups)
+	}
+	group := groups[1]
+	if group["name"] != OutboundProxy || group["type"] != "fallback" || group["url"] != DefaultGroupURL {
+		t.Errorf("server group = %v", group)
+	}
+}
+
+func TestRenderGroup_Errors(t *testing.T) {
+	if _, err := RenderGroup(nil, TargetSingBox, DefaultOptions()); err == nil {
+		t.Error("RenderGroup() should reject an empty group")
+	}
+
+	nodes := groupNodes(t, "trojan://secret@a.example.com:443#A", "hysteria2://secret@b.example.com:443#B")
+	if _, err := RenderGroup(nodes, TargetV2Ray, DefaultOptions()); err == nil {
+		t.Error("RenderGroup() should fail when a member is not supported by the target")
+	}
+	if _, err := RenderGroup(nodes, TargetSingBox, DefaultOptions()); err != nil {
+		t.Errorf("RenderGroup() unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ImportResult is the result of converting the proxies of a client config file
+type ImportResult struct {
+	Nodes   []*ProxyNode
+	Skipped []string // "name: reason" for every entry that could not be mapped
+}
+
+// validateImported checks the credentials a node needs before it can be saved
+func validateImported(node *ProxyNode) error {
+	switch node.Protocol {
+	case "vmess", "tuic":
+		if node.UUID == "" {
+			return fmt.Errorf("missing uuid")
+		}
+	case "vless":
+		if !isUUID(node.UUID) {
+			return fmt.Errorf("invalid uuid")
+		}
+	case "shadowsocks":
+		if node.Method == "" {
+			return fmt.Errorf("missing cipher")
+		}
+	}
+	switch node.Protocol {
+	case "shadowsocks", "trojan", "hysteria2", "tuic":
+		if node.Password == "" {
+			return fmt.Errorf("missing password")
+		}
+	}
+
+	if node.TLS.Reality.Enabled && (node.TLS.Reality.PublicKey == "" || node.TLS.ServerName == "") {
+		return fmt.Errorf("REALITY needs a public key and server name")
+	}
+
+	return node.validate()
+}
+
+// mapString reads a scalar field of a decoded YAML or JSON object as a string
+func mapString(m map[string]any, key string) string {
+	if m == nil {
+		return ""
+	}
+	switch v := m[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// mapInt reads an integer field that may be written as a string
+func mapInt(m map[string]any, key string) int {
+	if v, ok := m[key].(int); ok {
+		return v
+	}
+	return atoiSafe(mapString(m, key))
+}
+
+// mapBool reads a boolean field that may be written as a string
+func mapBool(m map[string]any, key string) bool {
+	if v, ok := m[key].(bool); ok {
+		return v
+	}
+	return mapString(m, key) == "true"
+}
+
+// mapMap reads a nested mapping
+func mapMap(m map[string]any, key string) map[string]any {
+	if m == nil {
+		return nil
+	}
+	v, _ := m[key].(map[string]any)
+	return v
+}
+
+// mapStrings reads a field that may be a single string or a list of strings
+func mapStrings(m map[string]any, key string) []string {
+	if m == nil {
+		return nil
+	}
+	switch v := m[key].(type) {
+	case []any:
+		var out []string
+		for _, item := range v {
+			if s := strings.TrimSpace(fmt.Sprint(item)); s != "" {
+				out = append(out, s)
+			}
+		}
+		return out
+	case string:
+		return splitList(v)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,328 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseJSONConfig converts the proxy outbounds of a sing-box, V2Ray or Xray
+// JSON config into nodes. A bare outbound object or a list of outbounds is
+// accepted as well. Non-proxy outbounds such as direct or block are ignored.
+func ParseJSONConfig(data []byte) (*ImportResult, error) {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid JSON config: %w", err)
+	}
+
+	var outbounds []any
+	switch v := raw.(type) {
+	case map[string]any:
+		if list, ok := v["outbounds"].([]any); ok {
+			outbounds = list
+		} else {
+			outbounds = []any{v}
+		}
+	case []any:
+		outbounds = v
+	}
+
+	result := &ImportResult{}
+	for i, item := range outbounds {
+		outbound, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		name := mapString(outbound, "tag")
+		if name == "" {
+			name = fmt.Sprintf("outbound %d", i+1)
+		}
+
+		var (
+			node *ProxyNode
+			err  error
+		)
+		switch {
+		case mapString(outbound, "protocol") != "":
+			node, err = v2rayOutboundToNode(outbound)
+		case mapString(outbound, "type") != "":
+			node, err = singBoxOutboundToNode(outbound)
+		default:
+			err = fmt.Errorf("neither type nor protocol is set")
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+		if node == nil {
+			continue // not a proxy
+		}
+
+		node.Remark = mapString(outbound, "tag")
+		result.Nodes = append(result.Nodes, node)
+	}
+
+	if len(result.Nodes) == 0 && len(result.Skipped) == 0 {
+		return nil, fmt.Errorf("no proxy outbounds found in JSON config")
+	}
+	return result, nil
+}
+
+// isSupportOutbound reports whether an outbound type only routes locally
+func isSupportOutbound(outboundType string) bool {
+	switch outboundType {
+	case "direct", "block", "dns", "selector", "urltest", "freedom", "blackhole", "loopback":
+		return true
+	}
+	return false
+}
+
+// hasStreamTransport reports whether share links of a protocol carry a transport type
+func hasStreamTransport(protocol string) bool {
+	return protocol == "vmess" || protocol == "vless" || protocol == "trojan"
+}
+
+// singBoxOutboundToNode maps a sing-box outbound onto a ProxyNode, nil for non-proxy outbounds
+func singBoxOutboundToNode(o map[string]any) (*ProxyNode, error) {
+	outboundType := mapString(o, "type")
+	if isSupportOutbound(outboundType) {
+		return nil, nil
+	}
+
+	node := &ProxyNode{
+		Protocol: outboundType,
+		Server:   mapString(o, "server"),
+		Port:     mapInt(o, "server_port"),
+	}
+
+	switch outboundType {
+	case "vmess":
+		node.UUID = mapString(o, "uuid")
+		node.AlterID = mapInt(o, "alter_id")
+		node.Security = mapString(o, "security")
+		if node.Security == "" {
+			node.Security = "auto"
+		}
+	case "vless":
+		node.UUID = mapString(o, "uuid")
+		node.Flow = mapString(o, "flow")
+	case "shadowsocks":
+		node.Method = mapString(o, "method")
+		node.Password = mapString(o, "password")
+		if plugin := mapString(o, "plugin"); plugin != "" {
+			return nil, fmt.Errorf("shadowsocks plugin %q is not supported", plugin)
+		}
+	case "trojan":
+		node.Password = mapString(o, "password")
+	case "hysteria2":
+		node.Password = mapString(o, "password")
+		node.UpMbps = mapInt(o, "up_mbps")
+		node.DownMbps = mapInt(o, "down_mbps")
+		if obfs := mapMap(o, "obfs"); obfs != nil {
+			node.ObfsType = mapString(obfs, "type")
+			node.ObfsPassword = mapString(obfs, "password")
+		}
+	case "tuic":
+		node.UUID = mapString(o, "uuid")
+		node.Password = mapString(o, "password")
+		node.CongestionControl = mapString(o, "congestion_control")
+		node.UDPRelayMode = mapString(o, "udp_relay_mode")
+	default:
+		return nil, fmt.Errorf("unsupported outbound type %q", outboundType)
+	}
+
+	if t := mapMap(o, "transport"); t != nil {
+		node.Transport = Transport{
+			Type:        mapString(t, "type"),
+			Path:        mapString(t, "path"),
+			Host:        mapString(mapMap(t, "headers"), "Host"),
+			ServiceName: mapString(t, "service_name"),
+		}
+		if hosts := mapStrings(t, "host"); len(hosts) > 0 {
+			node.Transport.Host = strings.Join(hosts, ",")
+		}
+	} else if hasStreamTransport(node.Protocol) {
+		node.Transport.Type = "tcp"
+	}
+
+	if tls := mapMap(o, "tls"); tls != nil && mapBool(tls, "enabled") {
+		node.TLS = TLS{
+			Enabled:     true,
+			ServerName:  mapString(tls, "server_name"),
+			ALPN:        mapStrings(tls, "alpn"),
+			Fingerprint: mapString(mapMap(tls, "utls"), "fingerprint"),
+			Insecure:    mapBool(tls, "insecure"),
+		}
+		if reality := mapMap(tls, "reality"); reality != nil && mapBool(reality, "enabled") {
+			node.TLS.Reality = Reality{
+				Enabled:   true,
+				PublicKey: mapString(reality, "public_key"),
+				ShortID:   mapString(reality, "short_id"),
+			}
+		}
+	}
+
+	if mux := mapMap(o, "multiplex"); mux != nil && mapBool(mux, "enabled") {
+		node.Mux = Mux{
+			Enabled:        true,
+			Protocol:       mapString(mux, "protocol"),
+			MaxConnections: mapInt(mux, "max_connections"),
+		}
+	}
+
+	if err := validateImported(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// v2rayOutboundToNode maps a V2Ray or Xray outbound onto a ProxyNode, nil for non-proxy outbounds
+func v2rayOutboundToNode(o map[string]any) (*ProxyNode, error) {
+	protocol := mapString(o, "protocol")
+	if isSupportOutbound(protocol) {
+		return nil, nil
+	}
+
+	settings := mapMap(o, "settings")
+	node := &ProxyNode{Protocol: protocol}
+
+	switch protocol {
+	case "vmess", "vless":
+		server := firstObject(settings, "vnext")
+		user := firstObject(server, "users")
+		node.Server = mapString(server, "address")
+		node.Port = mapInt(server, "port")
+		node.UUID = mapString(user, "id")
+		if protocol == "vmess" {
+			node.AlterID = mapInt(user, "alterId")
+			node.Security = mapString(user, "security")
+			if node.Security == "" {
+				node.Security = "auto"
+			}
+		} else {
+			node.Flow = mapString(user, "flow")
+		}
+	case "shadowsocks", "trojan":
+		server := firstObject(settings, "servers")
+		node.Server = mapString(server, "address")
+		node.Port = mapInt(server, "port")
+		node.Password = mapString(server, "password")
+		if protocol == "shadowsocks" {
+			node.Method = mapString(server, "method")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported outbound protocol %q", protocol)
+	}
+
+	stream := mapMap(o, "streamSettings")
+	if hasStreamTransport(protocol) {
+		node.Transport = v2rayStreamToTransport(stream)
+	}
+
+	switch mapString(stream, "security") {
+	case "tls":
+		tls := mapMap(stream, "tlsSettings")
+		node.TLS = TLS{
+			Enabled:     true,
+			ServerName:  mapString(tls, "serverName"),
+			ALPN:        mapStrings(tls, "alpn"),
+			Fingerprint: mapString(tls, "fingerprint"),
+			Insecure:    mapBool(tls, "allowInsecure"),
+		}
+	case "reality":
+		reality := mapMap(stream, "realitySettings")
+		node.TLS = TLS{
+			Enabled:     true,
+			ServerName:  mapString(reality, "serverName"),
+			Fingerprint: mapString(reality, "fingerprint"),
+			Reality: Reality{
+				Enabled:   true,
+				PublicKey: mapString(reality, "publicKey"),
+				ShortID:   mapString(reality, "shortId"),
+				SpiderX:   mapString(reality, "spiderX"),
+			},
+		}
+	}
+
+	if mux := mapMap(o, "mux"); mux != nil && mapBool(mux, "enabled") {
+		node.Mux = Mux{
+			Enabled:     true,
+			Concurrency: mapInt(mux, "concurrency"),
+		}
+	}
+
+	if err := validateImported(node); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+// v2rayStreamToTransport maps streamSettings network and *Settings onto a Transport
+func v2rayStreamToTransport(stream map[string]any) Transport {
+	t := Transport{Type: mapString(stream, "network")}
+
+	switch t.Type {
+	case "", "tcp", "raw":
+		t.Type = "tcp"
+		header := mapMap(mapMap(stream, "tcpSettings"), "header")
+		if mapString(header, "type") == "http" {
+			request := mapMap(header, "request")
+			t.HeaderType = "http"
+			t.Path = strings.Join(mapStrings(request, "path"), ",")
+			t.Host = strings.Join(mapStrings(mapMap(request, "headers"), "Host"), ",")
+		}
+	case "ws":
+		ws := mapMap(stream, "wsSettings")
+		t.Path = mapString(ws, "path")
+		t.Host = mapString(mapMap(ws, "headers"), "Host")
+		if host := mapString(ws, "host"); host != "" {
+			t.Host = host
+		}
+	case "grpc":
+		t.ServiceName = mapString(mapMap(stream, "grpcSettings"), "serviceName")
+	case "http", "h2":
+		h := mapMap(stream, "httpSettings")
+		t.Type = "http"
+		t.Path = mapString(h, "path")
+		t.Host = strings.Join(mapStrings(h, "host"), ",")
+	case "httpupgrade":
+		h := mapMap(stream, "httpupgradeSettings")
+		t.Path = mapString(h, "path")
+		t.Host = mapString(h, "host")
+	case "quic":
+		quic := mapMap(stream, "quicSettings")
+		t.QUICSecurity = mapString(quic, "security")
+		t.QUICKey = mapString(quic, "key")
+		if header := mapString(mapMap(quic, "header"), "type"); header != "none" {
+			t.HeaderType = header
+		}
+		if t.QUICSecurity == "none" {
+			t.QUICSecurity = ""
+		}
+	case "xhttp", "splithttp":
+		h := mapMap(stream, "xhttpSettings")
+		if h == nil {
+			h = mapMap(stream, "splithttpSettings")
+		}
+		t.Type = "xhttp"
+		t.Path = mapString(h, "path")
+		t.Host = mapString(h, "host")
+		t.Mode = mapString(h, "mode")
+	}
+
+	return t
+}
+
+// firstObject returns the first object of a list field such as vnext or users
+func firstObject(m map[string]any, key string) map[string]any {
+	if m == nil {
+		return nil
+	}
+	list, _ := m[key].([]any)
+	if len(list) == 0 {
+		return nil
+	}
+	first, _ := list[0].(map[string]any)
+	return first
+}
@@ -0,0 +1,38 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SplitLinks extracts share links from a newline separated list or a base64
+// encoded blob, as served by subscription endpoints. Blank lines and
+// comments are skipped; the links themselves are not validated.
+func SplitLinks(blob string) ([]string, error) {
+	blob = strings.TrimSpace(blob)
+	if blob == "" {
+		return nil, fmt.Errorf("no links found")
+	}
+
+	if !strings.Contains(blob, "://") {
+		decoded, err := decodeBase64String(strings.Join(strings.Fields(blob), ""))
+		if err != nil {
+			return nil, fmt.Errorf("content is neither a link list nor base64: %w", err)
+		}
+		blob = string(decoded)
+	}
+
+	var links []string
+	for _, line := range strings.Split(blob, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		links = append(links, line)
+	}
+
+	if len(links) == 0 {
+		return nil, fmt.Errorf("no links found")
+	}
+	return links, nil
+}
@@ -0,0 +1,46 @@
+package parser
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestSplitLinks(t *testing.T) {
+	plain := "vmess://abc\n\n  vless://def  \r\n# comment\nss://ghi\n"
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{"plain list", plain, []string{"vmess://abc", "vless://def", "ss://ghi"}},
+		{"base64 blob", base64.StdEncoding.EncodeToString([]byte(plain)), []string{"vmess://abc", "vless://def", "ss://ghi"}},
+		{"unpadded url-safe blob", base64.RawURLEncoding.EncodeToString([]byte("trojan://p@h:443?x=1#a>b")), []string{"trojan://p@h:443?x=1#a>b"}},
+		{"wrapped base64 blob", "dm1lc3M6Ly9h\nYmMKdmxlc3M6\nLy9kZWY=", []string{"vmess://abc", "vless://def"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			links, err := SplitLinks(tt.input)
+			if err != nil {
+				t.Fatalf("SplitLinks() unexpected error: %v", err)
+			}
+			if len(links) != len(tt.expected) {
+				t.Fatalf("SplitLinks() = %v, want %v", links, tt.expected)
+			}
+			for i := range links {
+				if links[i] != tt.expected[i] {
+					t.Errorf("SplitLinks()[%d] = %q, want %q", i, links[i], tt.expected[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitLinks_InvalidInput(t *testing.T) {
+	for _, input := range []string{"", "   \n ", "not base64 !!!", base64.StdEncoding.EncodeToString([]byte("\n# only a comment\n"))} {
+		if _, err := SplitLinks(input); err == nil {
+			t.Errorf("SplitLinks(%q) expected error but got none", input)
+		}
+	}
+}
@@ -0,0 +1,329 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// mihomoGroup is the selector group that routes non-local traffic to the proxy
+const mihomoGroup = "PROXY"
+
+// LinkToMihomo converts any supported share link into a mihomo (Clash.Meta) config
+func LinkToMihomo(link string) (map[string]any, error) {
+	node, err := Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	return Render(node, TargetMihomo)
+}
+
+// renderMihomo builds a mihomo YAML config around a single proxy, a proxy
+// group named like the single proxy over one proxy per node, or a chain of
+// proxies each dialed through the previous one with dialer-proxy
+func renderMihomo(nodes []*ProxyNode, opts Options) (map[string]any, error) {
+	var proxies []map[string]any
+	for i, node := range nodes {
+		proxy, err := mihomoProxy(node)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case opts.grouped():
+			proxy["name"] = memberTag(i)
+		case opts.chained():
+			proxy["name"] = chainTag(i, len(nodes))
+			if i > 0 {
+				proxy["dialer-proxy"] = chainTag(i-1, len(nodes))
+			}
+		}
+		proxies = append(proxies, proxy)
+	}
+
+	groups := []map[string]any{
+		{
+			"name":    mihomoGroup,
+			"type":    "select",
+			"proxies": []string{OutboundProxy, "DIRECT"},
+		},
+	}
+	if opts.grouped() {
+		groups = append(groups, mihomoProxyGroup(opts.Group, len(nodes)))
+	}
+
+	cfg := map[string]any{
+		"mode":         "rule",
+		"log-level":    "info",
+		"proxies":      proxies,
+		"proxy-groups": groups,
+		"rules":        mihomoRules(opts.Routing),
+	}
+
+	mihomoInbounds(cfg, opts.Inbound)
+	cfg["dns"] = mihomoDNS(opts)
+	cfg["ipv6"] = opts.DNS.Strategy != "ipv4-only"
+
+	// The Clash API lists connections and traffic totals at /connections
+	if opts.API.enabled() {
+		cfg["external-controller"] = opts.API.Listen
+		if opts.API.Secret != "" {
+			cfg["secret"] = opts.API.Secret
+		}
+	}
+
+	return cfg, nil
+}
+
+// mihomoRules renders the rules list, ending with the catch-all proxy group
+func mihomoRules(r Routing) []string {
+	policies := map[string]string{
+		OutboundProxy:  mihomoGroup,
+		OutboundDirect: "DIRECT",
+		OutboundBlock:  "REJECT",
+	}
+
+	var rules []string
+	for _, rule := range r.rules() {
+		policy := policies[rule.Outbound]
+		for _, suffix := range rule.DomainSuffix {
+			rules = append(rules, fmt.Sprintf("DOMAIN-SUFFIX,%s,%s", suffix, policy))
+		}
+		for _, name := range rule.GeoSite {
+			rules = append(rules, fmt.Sprintf("GEOSITE,%s,%s", name, policy))
+		}
+		for _, cidr := range rule.IPCIDR {
+			kind := "IP-CIDR"
+			if strings.Contains(cidr, ":") {
+				kind = "IP-CIDR6"
+			}
+			rules = append(rules, fmt.Sprintf("%s,%s,%s,no-resolve", kind, cidr, policy))
+		}
+		for _, name := range rule.GeoIP {
+			rules = append(rules, fmt.Sprintf("GEOIP,%s,%s", name, policy))
+		}
+	}
+	return append(rules, "MATCH,"+mihomoGroup)
+}
+
+// mihomoDNS renders the dns block. respect-rules sends nameserver queries
+// through the proxy, so the proxy server itself is resolved by the direct resolver.
+func mihomoDNS(opts Options) map[string]any {
+	d := opts.DNS
+	remote, _ := parseDNSServer(d.Remote)
+	direct, _ := parseDNSServer(d.Direct)
+
+	dns := map[string]any{
+		"enable":                  true,
+		"ipv6":                    d.Strategy != "ipv4-only",
+		"respect-rules":           true,
+		"nameserver":              []string{remote.URL},
+		"proxy-server-nameserver": []string{direct.URL},
+	}
+	if !remote.isIP() || !direct.isIP() {
+		dns["default-nameserver"] = []string{bootstrapDNS}
+	}
+
+	policy := map[string]any{}
+	suffixes, geosites := opts.Routing.directDomains()
+	for _, suffix := range suffixes {
+		policy["+."+suffix] = direct.URL
+	}
+	for _, name := range geosites {
+		policy["geosite:"+name] = direct.URL
+	}
+	if len(policy) > 0 {
+		dns["nameserver-policy"] = policy
+	}
+
+	if d.FakeIP {
+		dns["enhanced-mode"] = "fake-ip"
+		dns["fake-ip-range"] = fakeIPRange4
+	}
+	return dns
+}
+
+// mihomoInbounds sets the local listener fields. mihomo always serves SOCKS and
+// HTTP on one mixed port; a separate HTTP port is added when configured.
+func mihomoInbounds(cfg map[string]any, in Inbound) {
+	cfg["mixed-port"] = in.SocksPort
+	if in.HTTPPort > 0 {
+		cfg["port"] = in.HTTPPort
+	}
+
+	ip := net.ParseIP(in.Listen)
+	cfg["allow-lan"] = ip != nil && !ip.IsLoopback()
+	cfg["bind-address"] = in.Listen
+	if ip != nil && ip.IsUnspecified() {
+		cfg["bind-address"] = "*"
+	}
+
+	if in.hasAuth() {
+		cfg["authentication"] = []string{in.Username + ":" + in.Password}
+	}
+}
+
+// mihomoProxy renders the proxies entry of a node
+func mihomoProxy(node *ProxyNode) (map[string]any, error) {
+	p := map[string]any{
+		"name":   "proxy",
+		"server": node.Server,
+		"port":   node.Port,
+		"udp":    true,
+	}
+
+	switch node.Protocol {
+	case "vmess":
+		p["type"] = "vmess"
+		p["uuid"] = node.UUID
+		p["alterId"] = node.AlterID
+		p["cipher"] = node.Security
+	case "vless":
+		p["type"] = "vless"
+		p["uuid"] = node.UUID
+		if node.Flow != "" {
+			p["flow"] = node.Flow
+		}
+	case "shadowsocks":
+		p["type"] = "ss"
+		p["cipher"] = node.Method
+		p["password"] = node.Password
+	case "trojan":
+		p["type"] = "trojan"
+		p["password"] = node.Password
+	case "hysteria2":
+		p["type"] = "hysteria2"
+		p["password"] = node.Password
+		if node.UpMbps > 0 {
+			p["up"] = fmt.Sprintf("%d Mbps", node.UpMbps)
+		}
+		if node.DownMbps > 0 {
+			p["down"] = fmt.Sprintf("%d Mbps", node.DownMbps)
+		}
+		if node.ObfsType != "" {
+			p["obfs"] = node.ObfsType
+			p["obfs-password"] = node.ObfsPassword
+		}
+	case "tuic":
+		p["type"] = "tuic"
+		p["uuid"] = node.UUID
+		p["password"] = node.Password
+		if node.CongestionControl != "" {
+			p["congestion-controller"] = node.CongestionControl
+		}
+		if node.UDPRelayMode != "" {
+			p["udp-relay-mode"] = node.UDPRelayMode
+		}
+	default:
+		return nil, fmt.Errorf("unsupported protocol for mihomo: %s", node.Protocol)
+	}
+
+	if err := mihomoTransport(p, node.Transport); err != nil {
+		return nil, err
+	}
+	mihomoTLS(p, node)
+
+	if node.Mux.Enabled {
+		smux := map[string]any{
+			"enabled":  true,
+			"protocol": node.Mux.Protocol,
+		}
+		if node.Mux.MaxConnections > 0 {
+			smux["max-connections"] = node.Mux.MaxConnections
+		}
+		p["smux"] = smux
+	}
+
+	return p, nil
+}
+
+// mihomoTransport sets network and the matching *-opts block
+func mihomoTransport(p map[string]any, t Transport) error {
+	switch t.Type {
+	case "", "tcp":
+		if t.HeaderType != "http" {
+			return nil
+		}
+		opts := map[string]any{
+			"method": "GET",
+			"path":   []string{"/"},
+		}
+		if paths := splitList(t.Path); len(paths) > 0 {
+			opts["path"] = paths
+		}
+		if hosts := splitList(t.Host); len(hosts) > 0 {
+			opts["headers"] = map[string]any{"Host": hosts}
+		}
+		p["network"] = "http"
+		p["http-opts"] = opts
+	case "ws", "httpupgrade":
+		opts := map[string]any{}
+		if t.Path != "" {
+			opts["path"] = t.Path
+		}
+		if t.Host != "" {
+			opts["headers"] = map[string]any{"Host": t.Host}
+		}
+		if t.Type == "httpupgrade" {
+			opts["v2ray-http-upgrade"] = true
+		}
+		p["network"] = "ws"
+		p["ws-opts"] = opts
+	case "grpc":
+		p["network"] = "grpc"
+		p["grpc-opts"] = map[string]any{"grpc-service-name": t.ServiceName}
+	case "http", "h2":
+		opts := map[string]any{}
+		if t.Path != "" {
+			opts["path"] = t.Path
+		}
+		if hosts := splitList(t.Host); len(hosts) > 0 {
+			opts["host"] = hosts
+		}
+		p["network"] = "h2"
+		p["h2-opts"] = opts
+	default:
+		return fmt.Errorf("transport %s is not supported by mihomo", t.Type)
+	}
+	return nil
+}
+
+// mihomoTLS sets the TLS and REALITY fields of a proxy
+func mihomoTLS(p map[string]any, node *ProxyNode) {
+	tls := node.TLS
+	if !tls.Enabled {
+		return
+	}
+
+	switch node.Protocol {
+	case "vmess", "vless":
+		p["tls"] = true
+		if tls.ServerName != "" {
+			p["servername"] = tls.ServerName
+		}
+	default:
+		if tls.ServerName != "" {
+			p["sni"] = tls.ServerName
+		}
+	}
+
+	if len(tls.ALPN) > 0 {
+		p["alpn"] = tls.ALPN
+	}
+	if tls.Insecure {
+		p["skip-cert-verify"] = true
+	}
+	if tls.Fingerprint != "" {
+		p["client-fingerprint"] = tls.Fingerprint
+	}
+
+	if tls.Reality.Enabled {
+		reality := map[string]any{"public-key": tls.Reality.PublicKey}
+		if tls.Reality.ShortID != "" {
+			reality["short-id"] = tls.Reality.ShortID
+		}
+		p["reality-opts"] = reality
+		if tls.Fingerprint == "" {
+			p["client-fingerprint"] = defaultFingerprint
+		}
+	}
+}
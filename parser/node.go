@@ -0,0 +1,127 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ProxyNode is the protocol-independent representation of a single proxy
+// server. Every share link is parsed into a ProxyNode exactly once and the
+// renderers build client configs from it.
+type ProxyNode struct {
+	Protocol string `json:"protocol"`
+	Remark   string `json:"remark,omitempty"`
+	Server   string `json:"server"`
+	Port     int    `json:"port"`
+
+	// Credentials - which ones are set depends on the protocol
+	UUID     string `json:"uuid,omitempty"`
+	AlterID  int    `json:"alter_id,omitempty"`
+	Security string `json:"security,omitempty"` // vmess cipher
+	Flow     string `json:"flow,omitempty"`     // vless flow control
+	Method   string `json:"method,omitempty"`   // shadowsocks cipher
+	Password string `json:"password,omitempty"` // shadowsocks, trojan, hysteria2, tuic
+
+	// QUIC based protocols
+	ObfsType          string `json:"obfs_type,omitempty"` // hysteria2: salamander
+	ObfsPassword      string `json:"obfs_password,omitempty"`
+	UpMbps            int    `json:"up_mbps,omitempty"`
+	DownMbps          int    `json:"down_mbps,omitempty"`
+	CongestionControl string `json:"congestion_control,omitempty"` // tuic
+	UDPRelayMode      string `json:"udp_relay_mode,omitempty"`     // tuic
+
+	Transport Transport `json:"transport"`
+	TLS       TLS       `json:"tls"`
+	Mux       Mux       `json:"mux"`
+}
+
+// Transport describes the stream transport carrying the proxy protocol
+type Transport struct {
+	Type        string `json:"type"` // tcp, ws, grpc, http, h2, quic, ...
+	Path        string `json:"path,omitempty"`
+	Host        string `json:"host,omitempty"`
+	ServiceName string `json:"service_name,omitempty"`
+	HeaderType  string `json:"header_type,omitempty"` // tcp header obfuscation
+	Mode        string `json:"mode,omitempty"`        // xhttp: auto, packet-up, stream-up, stream-one
+
+	QUICSecurity string `json:"quic_security,omitempty"` // quic packet encryption: none, aes-128-gcm, chacha20-poly1305
+	QUICKey      string `json:"quic_key,omitempty"`      // quic encryption key
+}
+
+// TLS holds the TLS (and REALITY) settings of a node
+type TLS struct {
+	Enabled     bool     `json:"enabled"`
+	ServerName  string   `json:"server_name,omitempty"`
+	ALPN        []string `json:"alpn,omitempty"`
+	Fingerprint string   `json:"fingerprint,omitempty"`
+	Insecure    bool     `json:"insecure,omitempty"`
+	Reality     Reality  `json:"reality"`
+	Fragment    Fragment `json:"fragment"`
+}
+
+// Reality holds the REALITY handshake parameters
+type Reality struct {
+	Enabled   bool   `json:"enabled"`
+	PublicKey string `json:"public_key,omitempty"`
+	ShortID   string `json:"short_id,omitempty"`
+	SpiderX   string `json:"spider_x,omitempty"`
+}
+
+// Fragment holds the Xray TLS ClientHello fragmentation settings, unset when Packets is empty
+type Fragment struct {
+	Packets  string `json:"packets,omitempty"` // tlshello or a packet range such as 1-3
+	Length   string `json:"length,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+// Mux holds connection multiplexing settings
+type Mux struct {
+	Enabled        bool   `json:"enabled"`
+	Protocol       string `json:"protocol,omitempty"` // sing-box: smux, yamux, h2mux
+	MaxConnections int    `json:"max_connections,omitempty"`
+	Concurrency    int    `json:"concurrency,omitempty"` // v2ray
+}
+
+// Parse detects the protocol of a share link and parses it into a ProxyNode
+func Parse(link string) (*ProxyNode, error) {
+	link = strings.TrimSpace(link)
+
+	switch {
+	case strings.HasPrefix(link, "vmess://"):
+		return parseVMess(link)
+	case strings.HasPrefix(link, "vless://"):
+		return parseVLESS(link)
+	case strings.HasPrefix(link, "ss://"):
+		return parseSS(link)
+	case strings.HasPrefix(link, "trojan://"):
+		return parseTrojan(link)
+	case strings.HasPrefix(link, "hysteria2://"), strings.HasPrefix(link, "hy2://"):
+		return parseHysteria2(link)
+	case strings.HasPrefix(link, "tuic://"):
+		return parseTUIC(link)
+	default:
+		return nil, fmt.Errorf("unsupported link: must start with vmess://, vless://, ss://, trojan://, hysteria2://, hy2:// or tuic://")
+	}
+}
+
+// validate checks the fields every node needs regardless of protocol
+func (n *ProxyNode) validate() error {
+	if n.Server == "" {
+		return fmt.Errorf("missing server address in %s link", n.Protocol)
+	}
+	if n.Port <= 0 || n.Port > 65535 {
+		return fmt.Errorf("invalid port %d in %s link", n.Port, n.Protocol)
+	}
+	return nil
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	vmessJSON := `{"v":"2","ps":"VMess Node","add":"vmess.example.com","port":443,"id":"11111111-1111-1111-1111-111111111111","aid":"0","net":"ws","type":"","host":"cdn.example.com","path":"/ws","tls":"tls","sni":"sni.example.com","fp":"chrome","scy":""}`
+	vmessLink := "vmess://" + base64.StdEncoding.EncodeToString([]byte(vmessJSON))
+
+	tests := []struct {
+		name     string
+		link     string
+		expected ProxyNode
+	}{
+		{
+			name: "vmess ws tls",
+			link: vmessLink,
+			expected: ProxyNode{
+				Protocol:  "vmess",
+				Remark:    "VMess Node",
+				Server:    "vmess.example.com",
+				Port:      443,
+				UUID:      "11111111-1111-1111-1111-111111111111",
+				Security:  "auto",
+				Transport: Transport{Type: "ws", Path: "/ws", Host: "cdn.example.com"},
+				TLS:       TLS{Enabled: true, ServerName: "sni.example.com", Fingerprint: "chrome"},
+			},
+		},
+		{
+			name: "vless grpc tls",
+			link: "vless://12345678-1234-1234-1234-123456789012@example.com:8443?security=tls&sni=example.com&type=grpc&serviceName=svc&alpn=h2,http/1.1#My%20Node",
+			expected: ProxyNode{
+				Protocol:  "vless",
+				Remark:    "My Node",
+				Server:    "example.com",
+				Port:      8443,
+				UUID:      "12345678-1234-1234-1234-123456789012",
+				Transport: Transport{Type: "grpc", ServiceName: "svc"},
+				TLS:       TLS{Enabled: true, ServerName: "example.com", ALPN: []string{"h2", "http/1.1"}},
+			},
+		},
+		{
+			name: "vless without type defaults to tcp",
+			link: "vless://12345678-1234-1234-1234-123456789012@example.com:443?encryption=none",
+			expected: ProxyNode{
+				Protocol:  "vless",
+				Server:    "example.com",
+				Port:      443,
+				UUID:      "12345678-1234-1234-1234-123456789012",
+				Transport: Transport{Type: "tcp"},
+			},
+		},
+		{
+			name: "shadowsocks",
+			link: "ss://YWVzLTI1Ni1nY206cGFzc3dvcmQ=@example.com:8388#Test%20Config",
+			expected: ProxyNode{
+				Protocol: "shadowsocks",
+				Remark:   "Test Config",
+				Server:   "example.com",
+				Port:     8388,
+				Method:   "aes-256-gcm",
+				Password: "password",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.link)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			if node.Protocol != tt.expected.Protocol {
+				t.Errorf("Parse() protocol = %v, want %v", node.Protocol, tt.expected.Protocol)
+			}
+			if node.Remark != tt.expected.Remark {
+				t.Errorf("Parse() remark = %v, want %v", node.Remark, tt.expected.Remark)
+			}
+			if node.Server != tt.expected.Server || node.Port != tt.expected.Port {
+				t.Errorf("Parse() server = %s:%d, want %s:%d", node.Server, node.Port, tt.expected.Server, tt.expected.Port)
+			}
+			if node.UUID != tt.expected.UUID {
+				t.Errorf("Parse() uuid = %v, want %v", node.UUID, tt.expected.UUID)
+			}
+			if node.Security != tt.expected.Security {
+				t.Errorf("Parse() security = %v, want %v", node.Security, tt.expected.Security)
+			}
+			if node.Method != tt.expected.Method || node.Password != tt.expected.Password {
+				t.Errorf("Parse() credentials = %s/%s, want %s/%s", node.Method, node.Password, tt.expected.Method, tt.expected.Password)
+			}
+			if node.Transport != tt.expected.Transport {
+				t.Errorf("Parse() transport = %+v, want %+v", node.Transport, tt.expected.Transport)
+			}
+			if node.TLS.Enabled != tt.expected.TLS.Enabled || node.TLS.ServerName != tt.expected.TLS.ServerName ||
+				node.TLS.Fingerprint != tt.expected.TLS.Fingerprint {
+				t.Errorf("Parse() tls = %+v, want %+v", node.TLS, tt.expected.TLS)
+			}
+			if len(node.TLS.ALPN) != len(tt.expected.TLS.ALPN) {
+				t.Errorf("Parse() alpn = %v, want %v", node.TLS.ALPN, tt.expected.TLS.ALPN)
+			}
+		})
+	}
+}
+
+func TestParse_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty string", ""},
+		{"unknown scheme", "http://example.com"},
+		{"vmess missing server", "vmess://" + base64.StdEncoding.EncodeToString([]byte(`{"port":"443","id":"11111111-1111-1111-1111-111111111111"}`))},
+		{"vmess missing id", "vmess://" + base64.StdEncoding.EncodeToString([]byte(`{"add":"example.com","port":"443"}`))},
+		{"vless unknown transport", "vless://12345678-1234-1234-1234-123456789012@example.com:443?type=carrier-pigeon"},
+		{"vless unknown security", "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=ssl"},
+		{"vless port out of range", "vless://12345678-1234-1234-1234-123456789012@example.com:70000"},
+		{"vless non-UUID id", "vless://my-user@example.com:443"},
+		{"vless missing port", "vless://12345678-1234-1234-1234-123456789012@example.com?security=tls"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.input); err == nil {
+				t.Errorf("Parse(%q) expected error but got none", tt.input)
+			}
+		})
+	}
+}
+
+func TestIsUUID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"12345678-1234-1234-1234-123456789012", true},
+		{"ABCDEF01-abcd-4bcd-8bcd-0123456789ab", true},
+		{"invalid-uuid", false},
+		{"12345678-1234-1234-1234-12345678901z", false},
+		{"123456781234-1234-1234-1234567890123", false},
+	}
+
+	for _, tt := range tests {
+		if result := isUUID(tt.input); result != tt.expected {
+			t.Errorf("isUUID(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+}
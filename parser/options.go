@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	DefaultListen    = "127.0.0.1"
+	DefaultSocksPort = 1080
+)
+
+// Options holds the client side settings applied around a node when rendering
+type Options struct {
+	Inbound Inbound `json:"inbound"`
+	Routing Routing `json:"routing"`
+	DNS     DNS     `json:"dns"`
+	TUN     TUN     `json:"tun"`   // sing-box only
+	Group   Group   `json:"group"` // used by RenderGroup
+	API     API     `json:"api"`   // traffic statistics
+
+	chain bool // set by RenderChain
+}
+
+// Inbound describes the local proxy ports the core listens on
+type Inbound struct {
+	Listen    string `json:"listen,omitempty"`     // default 127.0.0.1
+	SocksPort int    `json:"socks_port,omitempty"` // default 1080
+	HTTPPort  int    `json:"http_port,omitempty"`  // 0 disables the separate HTTP inbound
+	Mixed     bool   `json:"mixed,omitempty"`      // serve SOCKS and HTTP on SocksPort where the core can
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
+
+// DefaultOptions returns the settings Render uses: SOCKS on 127.0.0.1:1080
+// without auth, LAN and private addresses bypassing the proxy and DNS over HTTPS
+func DefaultOptions() Options {
+	return Options{
+		Inbound: Inbound{}.WithDefaults(),
+		Routing: DefaultRouting(),
+		DNS:     DNS{}.WithDefaults(),
+		TUN:     DefaultTUN(),
+	}
+}
+
+// WithDefaults fills in the listen address and SOCKS port when they are unset
+func (in Inbound) WithDefaults() Inbound {
+	if in.Listen == "" {
+		in.Listen = DefaultListen
+	}
+	if in.SocksPort == 0 {
+		in.SocksPort = DefaultSocksPort
+	}
+	return in
+}
+
+// Validate checks the inbound settings after defaults are applied
+func (in Inbound) Validate() error {
+	in = in.WithDefaults()
+
+	if net.ParseIP(in.Listen) == nil {
+		return fmt.Errorf("invalid listen address: %s", in.Listen)
+	}
+	if in.SocksPort < 1 || in.SocksPort > 65535 {
+		return fmt.Errorf("invalid SOCKS port: %d", in.SocksPort)
+	}
+	if in.HTTPPort < 0 || in.HTTPPort > 65535 {
+		return fmt.Errorf("invalid HTTP port: %d", in.HTTPPort)
+	}
+	if in.HTTPPort == in.SocksPort {
+		return fmt.Errorf("HTTP port must differ from the SOCKS port")
+	}
+	if (in.Username == "") != (in.Password == "") {
+		return fmt.Errorf("username and password must be set together")
+	}
+	return nil
+}
+
+// hasAuth reports whether the local inbounds require a username and password
+func (in Inbound) hasAuth() bool {
+	return in.Username != "" && in.Password != ""
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestInbound_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		inbound Inbound
+		wantErr bool
+	}{
+		{"defaults", Inbound{}, false},
+		{"all set", Inbound{Listen: "0.0.0.0", SocksPort: 7890, HTTPPort: 7891, Username: "u", Password: "p"}, false},
+		{"ipv6 loopback", Inbound{Listen: "::1"}, false},
+		{"hostname listen", Inbound{Listen: "localhost"}, true},
+		{"socks port too large", Inbound{SocksPort: 70000}, true},
+		{"negative http port", Inbound{HTTPPort: -1}, true},
+		{"same ports", Inbound{SocksPort: 1080, HTTPPort: 1080}, true},
+		{"http port equals default socks port", Inbound{HTTPPort: DefaultSocksPort}, true},
+		{"username without password", Inbound{Username: "u"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.inbound.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderWithOptions_Inbounds(t *testing.T) {
+	node, err := Parse("trojan://secret@example.com:443#Trojan")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	opts := Options{Inbound: Inbound{Listen: "0.0.0.0", SocksPort: 7890, HTTPPort: 7891, Username: "user", Password: "pass"}}
+
+	t.Run("sing-box", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetSingBox, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+		inbounds := cfg["inbounds"].([]map[string]any)
+		if len(inbounds) != 2 {
+			t.Fatalf("RenderWithOptions() returned %d inbounds, want socks and http", len(inbounds))
+		}
+		if inbounds[0]["type"] != "socks" || inbounds[0]["listen"] != "0.0.0.0" || inbounds[0]["listen_port"] != 7890 {
+			t.Errorf("RenderWithOptions() socks inbound = %v", inbounds[0])
+		}
+		if inbounds[1]["type"] != "http" || inbounds[1]["listen_port"] != 7891 {
+			t.Errorf("RenderWithOptions() http inbound = %v", inbounds[1])
+		}
+		users := inbounds[1]["users"].([]map[string]any)
+		if users[0]["username"] != "user" || users[0]["password"] != "pass" {
+			t.Errorf("RenderWithOptions() http users = %v", users)
+		}
+
+		mixedOpts := Options{Inbound: Inbound{Mixed: true}}
+		cfg, err = RenderWithOptions(node, TargetSingBox, mixedOpts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions(mixed) unexpected error: %v", err)
+		}
+		inbounds = cfg["inbounds"].([]map[string]any)
+		if len(inbounds) != 1 || inbounds[0]["type"] != "mixed" || inbounds[0]["listen_port"] != DefaultSocksPort {
+			t.Errorf("RenderWithOptions(mixed) inbounds = %v", inbounds)
+		}
+		if _, exists := inbounds[0]["users"]; exists {
+			t.Error("RenderWithOptions(mixed) should not add users without auth")
+		}
+	})
+
+	for _, target := range []Target{TargetV2Ray, TargetXray} {
+		t.Run(string(target), func(t *testing.T) {
+			cfg, err := RenderWithOptions(node, target, opts)
+			if err != nil {
+				t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+			}
+			inbounds := cfg["inbounds"].([]map[string]any)
+			if len(inbounds) != 2 {
+				t.Fatalf("RenderWithOptions() returned %d inbounds, want socks and http", len(inbounds))
+			}
+			socks := inbounds[0]
+			settings := socks["settings"].(map[string]any)
+			if socks["port"] != 7890 || socks["listen"] != "0.0.0.0" || settings["auth"] != "password" {
+				t.Errorf("RenderWithOptions() socks inbound = %v", socks)
+			}
+			accounts := settings["accounts"].([]map[string]any)
+			if accounts[0]["user"] != "user" || accounts[0]["pass"] != "pass" {
+				t.Errorf("RenderWithOptions() socks accounts = %v", accounts)
+			}
+			if inbounds[1]["protocol"] != "http" || inbounds[1]["port"] != 7891 {
+				t.Errorf("RenderWithOptions() http inbound = %v", inbounds[1])
+			}
+		})
+	}
+
+	t.Run("mihomo", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetMihomo, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+		if cfg["mixed-port"] != 7890 || cfg["port"] != 7891 || cfg["allow-lan"] != true || cfg["bind-address"] != "*" {
+			t.Errorf("RenderWithOptions() mihomo listeners = mixed-port %v, port %v, allow-lan %v, bind-address %v",
+				cfg["mixed-port"], cfg["port"], cfg["allow-lan"], cfg["bind-address"])
+		}
+		auth := cfg["authentication"].([]string)
+		if len(auth) != 1 || auth[0] != "user:pass" {
+			t.Errorf("RenderWithOptions() mihomo authentication = %v", auth)
+		}
+	})
+
+	t.Run("invalid options", func(t *testing.T) {
+		if _, err := RenderWithOptions(node, TargetSingBox, Options{Inbound: Inbound{SocksPort: 99999}}); err == nil {
+			t.Error("RenderWithOptions() expected error for invalid port")
+		}
+	})
+}
@@ -0,0 +1,172 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultFingerprint is the uTLS fingerprint used when REALITY needs one and the link has none
+const defaultFingerprint = "chrome"
+
+// Target identifies the proxy core a config is rendered for
+type Target string
+
+const (
+	TargetSingBox Target = "sing-box"
+	TargetV2Ray   Target = "v2ray"
+	TargetMihomo  Target = "mihomo"
+	TargetXray    Target = "xray"
+)
+
+// Render builds a complete client config for the given target from a parsed node
+func Render(node *ProxyNode, target Target) (map[string]any, error) {
+	return RenderWithOptions(node, target, DefaultOptions())
+}
+
+// RenderWithOptions is Render with explicit client side settings
+func RenderWithOptions(node *ProxyNode, target Target, opts Options) (map[string]any, error) {
+	if node == nil {
+		return nil, fmt.Errorf("nil proxy node")
+	}
+	return render([]*ProxyNode{node}, target, opts)
+}
+
+// RenderGroup builds a config whose proxy outbound is a group over all nodes
+// as described by opts.Group, so the core itself picks a server and fails over
+func RenderGroup(nodes []*ProxyNode, target Target, opts Options) (map[string]any, error) {
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("group has no servers")
+	}
+	for _, node := range nodes {
+		if node == nil {
+			return nil, fmt.Errorf("nil proxy node")
+		}
+	}
+	if err := opts.Group.Validate(); err != nil {
+		return nil, err
+	}
+	opts.Group = opts.Group.WithDefaults()
+	return render(nodes, target, opts)
+}
+
+// RenderChain builds a config whose proxy outbound reaches the destination
+// through every node in turn: the first node is dialed directly and each
+// following one through the node before it
+func RenderChain(nodes []*ProxyNode, target Target, opts Options) (map[string]any, error) {
+	if len(nodes) < 2 {
+		return nil, fmt.Errorf("a chain needs at least two servers")
+	}
+	for _, node := range nodes {
+		if node == nil {
+			return nil, fmt.Errorf("nil proxy node")
+		}
+	}
+	if opts.grouped() {
+		return nil, fmt.Errorf("a chain cannot also be a group")
+	}
+	opts.chain = true
+	return render(nodes, target, opts)
+}
+
+// render validates opts, fills in defaults and renders the nodes for target
+func render(nodes []*ProxyNode, target Target, opts Options) (map[string]any, error) {
+	if err := opts.Inbound.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.Routing.Validate(); err != nil {
+		return nil, err
+	}
+	if err := opts.API.Validate(opts.Inbound); err != nil {
+		return nil, err
+	}
+	if err := opts.DNS.Validate(); err != nil {
+		return nil, err
+	}
+	if opts.TUN.Enabled {
+		if target != TargetSingBox {
+			return nil, fmt.Errorf("TUN mode is only supported by sing-box")
+		}
+		if err := opts.TUN.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, node := range nodes {
+		if err := validateNode(node, target); err != nil {
+			return nil, err
+		}
+	}
+	opts.Inbound = opts.Inbound.WithDefaults()
+	opts.DNS = opts.DNS.WithDefaults()
+	opts.TUN = opts.TUN.WithDefaults()
+
+	switch target {
+	case TargetSingBox:
+		return renderSingBox(nodes, opts)
+	case TargetV2Ray:
+		return renderV2Ray(nodes, opts)
+	case TargetMihomo:
+		return renderMihomo(nodes, opts)
+	case TargetXray:
+		return renderXray(nodes, opts)
+	default:
+		return nil, fmt.Errorf("unsupported render target: %s", target)
+	}
+}
+
+// SupportsNode reports whether node can be rendered for the given target,
+// which also depends on settings such as REALITY, the VLESS flow and the transport
+func SupportsNode(node *ProxyNode, target Target) bool {
+	if !Supports(node.Protocol, target) || validateNode(node, target) != nil {
+		return false
+	}
+	_, err := renderOutbound(node, target)
+	return err == nil
+}
+
+// renderOutbound renders the outbound of a single node for target
+func renderOutbound(node *ProxyNode, target Target) (map[string]any, error) {
+	switch target {
+	case TargetSingBox:
+		return singBoxOutbound(node)
+	case TargetV2Ray, TargetXray:
+		return v2rayOutbound(node, target)
+	case TargetMihomo:
+		return mihomoProxy(node)
+	default:
+		return nil, fmt.Errorf("unsupported render target: %s", target)
+	}
+}
+
+// validateNode checks the settings of node that only some targets can render
+func validateNode(node *ProxyNode, target Target) error {
+	if node.TLS.Reality.Enabled && target == TargetV2Ray {
+		return fmt.Errorf("V2Ray does not support REALITY, connect with Xray")
+	}
+	return validateFlow(node, target)
+}
+
+// Supports reports whether a protocol can be rendered for the given target
+func Supports(protocol string, target Target) bool {
+	switch protocol {
+	case "vmess", "vless", "shadowsocks", "trojan":
+		return target == TargetSingBox || target == TargetV2Ray || target == TargetXray || target == TargetMihomo
+	case "hysteria2", "tuic":
+		return target == TargetSingBox || target == TargetMihomo
+	default:
+		return false
+	}
+}
+
+// parseAndRender parses a link of the expected protocol and renders it for target
+func parseAndRender(link, scheme string, target Target) (map[string]any, error) {
+	if !strings.HasPrefix(link, scheme) {
+		return nil, fmt.Errorf("invalid link: must start with %s", scheme)
+	}
+
+	node, err := Parse(link)
+	if err != nil {
+		return nil, err
+	}
+
+	return Render(node, target)
+}
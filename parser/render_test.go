@@ -0,0 +1,265 @@
+package parser
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestRender_SameNodeBothTargets(t *testing.T) {
+	node, err := Parse("vless://12345678-1234-1234-1234-123456789012@example.com:2083?security=tls&sni=example.com&type=ws&path=/ws&host=cdn.example.com")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	singBox, err := Render(node, TargetSingBox)
+	if err != nil {
+		t.Fatalf("Render(sing-box) unexpected error: %v", err)
+	}
+	sbProxy := singBox["outbounds"].([]map[string]any)[0]
+	if sbProxy["server_port"] != 2083 {
+		t.Errorf("Render(sing-box) server_port = %v, want 2083", sbProxy["server_port"])
+	}
+	tls, ok := sbProxy["tls"].(map[string]any)
+	if !ok || tls["server_name"] != "example.com" {
+		t.Errorf("Render(sing-box) tls = %v, want server_name example.com", sbProxy["tls"])
+	}
+
+	v2ray, err := Render(node, TargetV2Ray)
+	if err != nil {
+		t.Fatalf("Render(v2ray) unexpected error: %v", err)
+	}
+	v2Proxy := v2ray["outbounds"].([]map[string]any)[0]
+	vnext := v2Proxy["settings"].(map[string]any)["vnext"].([]map[string]any)
+	if vnext[0]["port"] != 2083 {
+		t.Errorf("Render(v2ray) port = %v, want 2083", vnext[0]["port"])
+	}
+	stream := v2Proxy["streamSettings"].(map[string]any)
+	if stream["security"] != "tls" {
+		t.Errorf("Render(v2ray) security = %v, want tls", stream["security"])
+	}
+}
+
+func TestRender_PlainTCPHasNoTransportOrTLS(t *testing.T) {
+	node, err := Parse("vless://12345678-1234-1234-1234-123456789012@example.com:443?type=tcp")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	cfg, err := Render(node, TargetSingBox)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	proxy := cfg["outbounds"].([]map[string]any)[0]
+	if _, exists := proxy["transport"]; exists {
+		t.Error("Render() should omit transport for plain tcp")
+	}
+	if _, exists := proxy["tls"]; exists {
+		t.Error("Render() should omit tls when security is not set")
+	}
+}
+
+func TestRender_InvalidInput(t *testing.T) {
+	if _, err := Render(nil, TargetSingBox); err == nil {
+		t.Error("Render(nil) expected error but got none")
+	}
+
+	node := &ProxyNode{Protocol: "vmess", Server: "example.com", Port: 443}
+	if _, err := Render(node, Target("clash")); err == nil {
+		t.Error("Render() expected error for unknown target")
+	}
+
+	node.Protocol = "carrier-pigeon"
+	if _, err := Render(node, TargetSingBox); err == nil {
+		t.Error("Render() expected error for unknown protocol")
+	}
+}
+
+func TestRender_Transports(t *testing.T) {
+	const base = "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=tls&sni=example.com&"
+
+	tests := []struct {
+		name            string
+		query           string
+		singBox         map[string]any
+		v2rayNetwork    string
+		v2raySettingKey string
+		v2raySettings   map[string]any
+	}{
+		{
+			name:            "grpc",
+			query:           "type=grpc&serviceName=tunnel",
+			singBox:         map[string]any{"type": "grpc", "service_name": "tunnel"},
+			v2rayNetwork:    "grpc",
+			v2raySettingKey: "grpcSettings",
+			v2raySettings:   map[string]any{"serviceName": "tunnel"},
+		},
+		{
+			name:            "h2",
+			query:           "type=h2&path=/h2&host=a.example.com",
+			singBox:         map[string]any{"type": "http", "path": "/h2"},
+			v2rayNetwork:    "http",
+			v2raySettingKey: "httpSettings",
+			v2raySettings:   map[string]any{"path": "/h2"},
+		},
+		{
+			name:            "httpupgrade",
+			query:           "type=httpupgrade&path=/up&host=cdn.example.com",
+			singBox:         map[string]any{"type": "httpupgrade", "path": "/up", "host": "cdn.example.com"},
+			v2rayNetwork:    "httpupgrade",
+			v2raySettingKey: "httpupgradeSettings",
+			v2raySettings:   map[string]any{"path": "/up", "host": "cdn.example.com"},
+		},
+		{
+			name:            "quic",
+			query:           "type=quic",
+			singBox:         map[string]any{"type": "quic"},
+			v2rayNetwork:    "quic",
+			v2raySettingKey: "quicSettings",
+			v2raySettings:   map[string]any{"security": "none"},
+		},
+		{
+			name:            "tcp http obfuscation",
+			query:           "type=tcp&headerType=http&host=obfs.example.com&path=/index",
+			singBox:         map[string]any{"type": "http", "method": "GET", "path": "/index"},
+			v2rayNetwork:    "tcp",
+			v2raySettingKey: "tcpSettings",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(base + tt.query)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			singBox, err := Render(node, TargetSingBox)
+			if err != nil {
+				t.Fatalf("Render(sing-box) unexpected error: %v", err)
+			}
+			transport, ok := singBox["outbounds"].([]map[string]any)[0]["transport"].(map[string]any)
+			if !ok {
+				t.Fatal("Render(sing-box) transport is not a map")
+			}
+			for key, want := range tt.singBox {
+				if transport[key] != want {
+					t.Errorf("Render(sing-box) transport.%s = %v, want %v", key, transport[key], want)
+				}
+			}
+			if _, exists := transport["headers"]; exists && tt.singBox["type"] != "ws" {
+				t.Errorf("Render(sing-box) %s transport should not carry ws headers", tt.name)
+			}
+
+			v2ray, err := Render(node, TargetV2Ray)
+			if err != nil {
+				t.Fatalf("Render(v2ray) unexpected error: %v", err)
+			}
+			stream := v2ray["outbounds"].([]map[string]any)[0]["streamSettings"].(map[string]any)
+			if stream["network"] != tt.v2rayNetwork {
+				t.Errorf("Render(v2ray) network = %v, want %v", stream["network"], tt.v2rayNetwork)
+			}
+			if _, exists := stream["wsSettings"]; exists {
+				t.Errorf("Render(v2ray) %s should not emit wsSettings", tt.name)
+			}
+			settings, ok := stream[tt.v2raySettingKey].(map[string]any)
+			if !ok {
+				t.Fatalf("Render(v2ray) %s is not a map", tt.v2raySettingKey)
+			}
+			for key, want := range tt.v2raySettings {
+				if settings[key] != want {
+					t.Errorf("Render(v2ray) %s.%s = %v, want %v", tt.v2raySettingKey, key, settings[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_TCPHTTPObfuscationHeaders(t *testing.T) {
+	node, err := Parse("vless://12345678-1234-1234-1234-123456789012@example.com:80?type=tcp&headerType=http&host=a.example.com,b.example.com")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	cfg, err := Render(node, TargetV2Ray)
+	if err != nil {
+		t.Fatalf("Render() unexpected error: %v", err)
+	}
+
+	stream := cfg["outbounds"].([]map[string]any)[0]["streamSettings"].(map[string]any)
+	header := stream["tcpSettings"].(map[string]any)["header"].(map[string]any)
+	if header["type"] != "http" {
+		t.Errorf("Render() header.type = %v, want http", header["type"])
+	}
+
+	request := header["request"].(map[string]any)
+	if path := request["path"].([]string); len(path) != 1 || path[0] != "/" {
+		t.Errorf("Render() request.path = %v, want [/]", path)
+	}
+	hosts := request["headers"].(map[string]any)["Host"].([]string)
+	if len(hosts) != 2 {
+		t.Errorf("Render() request Host = %v, want 2 hosts", hosts)
+	}
+}
+
+func TestRender_QUICSettings(t *testing.T) {
+	vmessQUIC := `{"v":"2","add":"quic.example.com","port":"443","id":"11111111-1111-1111-1111-111111111111","net":"quic","type":"srtp","host":"chacha20-poly1305","path":"k3y","tls":"tls"}`
+
+	tests := []struct {
+		name         string
+		link         string
+		wantSecurity string
+		wantKey      string
+		wantHeader   string
+		singBoxError bool
+	}{
+		{
+			name:         "vless encrypted with header",
+			link:         "vless://12345678-1234-1234-1234-123456789012@example.com:443?type=quic&quicSecurity=aes-128-gcm&key=k3y&headerType=wechat-video&security=tls&sni=example.com",
+			wantSecurity: "aes-128-gcm",
+			wantKey:      "k3y",
+			wantHeader:   "wechat-video",
+			singBoxError: true,
+		},
+		{
+			name:         "vmess security in host and key in path",
+			link:         "vmess://" + base64.StdEncoding.EncodeToString([]byte(vmessQUIC)),
+			wantSecurity: "chacha20-poly1305",
+			wantKey:      "k3y",
+			wantHeader:   "srtp",
+			singBoxError: true,
+		},
+		{
+			name:         "plain",
+			link:         "vless://12345678-1234-1234-1234-123456789012@example.com:443?type=quic&security=tls&sni=example.com",
+			wantSecurity: "none",
+			wantHeader:   "none",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.link)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			cfg, err := Render(node, TargetV2Ray)
+			if err != nil {
+				t.Fatalf("Render(v2ray) unexpected error: %v", err)
+			}
+			stream := cfg["outbounds"].([]map[string]any)[0]["streamSettings"].(map[string]any)
+			quic := stream["quicSettings"].(map[string]any)
+			if quic["security"] != tt.wantSecurity || quic["key"] != tt.wantKey {
+				t.Errorf("quicSettings = %v, want security %s and key %q", quic, tt.wantSecurity, tt.wantKey)
+			}
+			if header := quic["header"].(map[string]any)["type"]; header != tt.wantHeader {
+				t.Errorf("quicSettings.header.type = %v, want %s", header, tt.wantHeader)
+			}
+
+			if _, err := Render(node, TargetSingBox); (err != nil) != tt.singBoxError {
+				t.Errorf("Render(sing-box) error = %v, want error %v", err, tt.singBoxError)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Outbound tags a routing rule can send traffic to
+const (
+	OutboundProxy  = "proxy"
+	OutboundDirect = "direct"
+	OutboundBlock  = "block"
+)
+
+// adsGeoSite is the geosite category the block ads preset matches
+const adsGeoSite = "category-ads-all"
+
+// privateCIDRs are the loopback, LAN and link-local ranges bypassed by the private preset
+var privateCIDRs = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"169.254.0.0/16",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+}
+
+// Routing decides which traffic goes through the proxy, directly or nowhere
+type Routing struct {
+	BypassPrivate bool   `json:"bypass_private"` // LAN, loopback and link-local go direct
+	BlockAds      bool   `json:"block_ads"`      // geosite:category-ads-all is blocked
+	Rules         []Rule `json:"rules,omitempty"`
+}
+
+// Rule sends traffic matching any of its conditions to Outbound
+type Rule struct {
+	Outbound     string   `json:"outbound"` // proxy, direct or block
+	DomainSuffix []string `json:"domain_suffix,omitempty"`
+	IPCIDR       []string `json:"ip_cidr,omitempty"`
+	GeoIP        []string `json:"geoip,omitempty"`   // e.g. cn, private
+	GeoSite      []string `json:"geosite,omitempty"` // e.g. cn, google
+}
+
+// DefaultRouting keeps LAN and private addresses off the proxy
+func DefaultRouting() Routing {
+	return Routing{BypassPrivate: true}
+}
+
+// Validate checks rule outbounds and conditions
+func (r Routing) Validate() error {
+	for i, rule := range r.Rules {
+		switch rule.Outbound {
+		case OutboundProxy, OutboundDirect, OutboundBlock:
+		default:
+			return fmt.Errorf("rule %d: invalid outbound %q", i+1, rule.Outbound)
+		}
+		if rule.isEmpty() {
+			return fmt.Errorf("rule %d: no domain, IP or geo condition", i+1)
+		}
+		for _, cidr := range rule.IPCIDR {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("rule %d: invalid CIDR %q", i+1, cidr)
+			}
+		}
+		for _, name := range append(append([]string{}, rule.GeoIP...), rule.GeoSite...) {
+			if name == "" || strings.ContainsAny(name, " :,/") {
+				return fmt.Errorf("rule %d: invalid geo category %q", i+1, name)
+			}
+		}
+	}
+	return nil
+}
+
+// rules expands the presets and returns every rule in match order: ads are
+// blocked first, private ranges bypassed next, then the user rules.
+func (r Routing) rules() []Rule {
+	var rules []Rule
+	if r.BlockAds {
+		rules = append(rules, Rule{Outbound: OutboundBlock, GeoSite: []string{adsGeoSite}})
+	}
+	if r.BypassPrivate {
+		rules = append(rules, Rule{Outbound: OutboundDirect, DomainSuffix: []string{"local"}, IPCIDR: privateCIDRs})
+	}
+	for _, rule := range r.Rules {
+		if !rule.isEmpty() {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// usesBlock reports whether any rule needs a block outbound
+func (r Routing) usesBlock() bool {
+	for _, rule := range r.rules() {
+		if rule.Outbound == OutboundBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// usesGeoIP reports whether any rule matches on resolved country
+func (r Routing) usesGeoIP() bool {
+	for _, rule := range r.rules() {
+		if len(rule.GeoIP) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isEmpty reports whether a rule has no conditions
+func (rule Rule) isEmpty() bool {
+	return len(rule.DomainSuffix) == 0 && len(rule.IPCIDR) == 0 && len(rule.GeoIP) == 0 && len(rule.GeoSite) == 0
+}
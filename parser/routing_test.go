@@ -0,0 +1,179 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRouting_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		routing     Routing
+		expectError bool
+	}{
+		{name: "empty", routing: Routing{}},
+		{name: "default", routing: DefaultRouting()},
+		{
+			name: "country lists",
+			routing: Routing{Rules: []Rule{
+				{Outbound: OutboundDirect, GeoIP: []string{"cn"}, GeoSite: []string{"cn"}},
+				{Outbound: OutboundProxy, DomainSuffix: []string{"google.com"}},
+			}},
+		},
+		{
+			name:        "unknown outbound",
+			routing:     Routing{Rules: []Rule{{Outbound: "reject", DomainSuffix: []string{"ads.com"}}}},
+			expectError: true,
+		},
+		{
+			name:        "rule without conditions",
+			routing:     Routing{Rules: []Rule{{Outbound: OutboundDirect}}},
+			expectError: true,
+		},
+		{
+			name:        "invalid CIDR",
+			routing:     Routing{Rules: []Rule{{Outbound: OutboundDirect, IPCIDR: []string{"10.0.0.0"}}}},
+			expectError: true,
+		},
+		{
+			name:        "invalid geo category",
+			routing:     Routing{Rules: []Rule{{Outbound: OutboundDirect, GeoSite: []string{"geosite:cn"}}}},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.routing.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRenderWithOptions_Routing(t *testing.T) {
+	node, err := Parse("trojan://secret@example.com:443#Trojan")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Routing = Routing{
+		BypassPrivate: true,
+		BlockAds:      true,
+		Rules: []Rule{
+			{Outbound: OutboundDirect, DomainSuffix: []string{"example.cn"}, GeoIP: []string{"cn"}, GeoSite: []string{"cn"}},
+		},
+	}
+
+	t.Run("sing-box", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetSingBox, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+
+		route := cfg["route"].(map[string]any)
+		rules := route["rules"].([]map[string]any)
+		if len(rules) != 3 {
+			t.Fatalf("route.rules has %d rules, want 3", len(rules))
+		}
+		if rules[0]["outbound"] != OutboundBlock || rules[1]["outbound"] != OutboundDirect {
+			t.Errorf("route.rules order = %v, %v; want block then direct", rules[0]["outbound"], rules[1]["outbound"])
+		}
+		if got := rules[2]["rule_set"].([]string); strings.Join(got, ",") != "geosite-cn,geoip-cn" {
+			t.Errorf("rule_set tags = %v, want [geosite-cn geoip-cn]", got)
+		}
+		if len(route["rule_set"].([]map[string]any)) != 3 {
+			t.Errorf("route.rule_set = %v, want 3 remote sets", route["rule_set"])
+		}
+		if route["final"] != OutboundProxy {
+			t.Errorf("route.final = %v, want proxy", route["final"])
+		}
+		if !hasOutbound(cfg, "tag", OutboundBlock) {
+			t.Error("sing-box config is missing the block outbound")
+		}
+	})
+
+	for _, target := range []Target{TargetV2Ray, TargetXray} {
+		t.Run(string(target), func(t *testing.T) {
+			cfg, err := RenderWithOptions(node, target, opts)
+			if err != nil {
+				t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+			}
+
+			routing := cfg["routing"].(map[string]any)
+			if routing["domainStrategy"] != "IPIfNonMatch" {
+				t.Errorf("domainStrategy = %v, want IPIfNonMatch with geoip rules", routing["domainStrategy"])
+			}
+
+			// two resolver rules, ads domain, private domain + private IPs, user domains + user IPs
+			rules := routing["rules"].([]map[string]any)
+			if len(rules) != 7 {
+				t.Fatalf("routing.rules has %d rules, want 7", len(rules))
+			}
+			if got := rules[5]["domain"].([]string); strings.Join(got, ",") != "domain:example.cn,geosite:cn" {
+				t.Errorf("user domain rule = %v", got)
+			}
+			if got := rules[6]["ip"].([]string); strings.Join(got, ",") != "geoip:cn" {
+				t.Errorf("user IP rule = %v", got)
+			}
+			if !hasOutbound(cfg, "tag", OutboundBlock) {
+				t.Error("config is missing the blackhole outbound")
+			}
+		})
+	}
+
+	t.Run("mihomo", func(t *testing.T) {
+		cfg, err := RenderWithOptions(node, TargetMihomo, opts)
+		if err != nil {
+			t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+		}
+
+		rules := strings.Join(cfg["rules"].([]string), "\n")
+		for _, want := range []string{
+			"GEOSITE,category-ads-all,REJECT",
+			"IP-CIDR,192.168.0.0/16,DIRECT,no-resolve",
+			"IP-CIDR6,fc00::/7,DIRECT,no-resolve",
+			"DOMAIN-SUFFIX,example.cn,DIRECT",
+			"GEOIP,cn,DIRECT",
+		} {
+			if !strings.Contains(rules, want) {
+				t.Errorf("mihomo rules missing %q:\n%s", want, rules)
+			}
+		}
+	})
+}
+
+func TestRenderWithOptions_NoRouting(t *testing.T) {
+	node, err := Parse("trojan://secret@example.com:443#Trojan")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.Routing = Routing{}
+
+	cfg, err := RenderWithOptions(node, TargetV2Ray, opts)
+	if err != nil {
+		t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+	}
+	// Only the DNS resolver rules remain
+	routing := cfg["routing"].(map[string]any)
+	if len(routing["rules"].([]map[string]any)) != 2 || routing["domainStrategy"] != "AsIs" {
+		t.Errorf("routing = %v, want resolver rules only and AsIs", routing)
+	}
+	if hasOutbound(cfg, "tag", OutboundBlock) {
+		t.Error("block outbound rendered without block rules")
+	}
+}
+
+// hasOutbound reports whether a rendered config has an outbound with key set to value
+func hasOutbound(cfg map[string]any, key, value string) bool {
+	for _, outbound := range cfg["outbounds"].([]map[string]any) {
+		if outbound[key] == value {
+			return true
+		}
+	}
+	return false
+}
@@ -8,9 +8,12 @@ import (
 	"strings"
 )
 
-// decodeBase64String safely decodes base64 with or without padding
+// decodeBase64String safely decodes standard or URL-safe base64 with or without padding
 func decodeBase64String(s string) ([]byte, error) {
 	s = strings.TrimSpace(s)
+	if strings.ContainsAny(s, "-_") {
+		s = strings.NewReplacer("-", "+", "_", "/").Replace(s)
+	}
 	if m := len(s) % 4; m != 0 {
 		s += strings.Repeat("=", 4-m)
 	}
@@ -70,7 +73,9 @@ func parseSSCredentials(ssLink string) (method, password, host string, port int,
 		return
 	}
 
-	// Case 2: fully base64(method:password@host:port)
+	// Case 2: fully base64(method:password@host:port), the remark stays outside the base64
+	raw = strings.SplitN(raw, "#", 2)[0]
+
 	// URL-decode before base64 decode - handle multiple layers
 	for {
 		if decodedURL, errURL := url.QueryUnescape(raw); errURL == nil && decodedURL != raw {
@@ -95,108 +100,52 @@ func parseSSCredentials(ssLink string) (method, password, host string, port int,
 		return
 	}
 
-	userParts := strings.SplitN(u.User.String(), ":", 2)
-	if len(userParts) != 2 {
+	pass, hasPassword := u.User.Password()
+	if !hasPassword {
 		err = fmt.Errorf("invalid user info in ss:// link")
 		return
 	}
-	method, password = userParts[0], userParts[1]
+	method, password = u.User.Username(), pass
 	host = u.Hostname()
 	port, err = strconv.Atoi(u.Port())
 	return
 }
 
-// buildSSV2rayConfig builds the final V2Ray JSON config
-func buildSSV2rayConfig(method, password, host string, port int) map[string]any {
-	return map[string]any{
-		"log": map[string]any{"loglevel": "none"},
-		"inbounds": []map[string]any{
-			{
-				"tag":      "socks",
-				"port":     1080,
-				"listen":   "127.0.0.1",
-				"protocol": "socks",
-				"sniffing": map[string]any{
-					"enabled":      true,
-					"destOverride": []string{"http", "tls"},
-				},
-				"settings": map[string]any{
-					"auth": "noauth",
-					"udp":  true,
-				},
-			},
-		},
-		"outbounds": []map[string]any{
-			{
-				"tag":      "proxy",
-				"protocol": "shadowsocks",
-				"settings": map[string]any{
-					"servers": []map[string]any{
-						{
-							"address":  host,
-							"port":     port,
-							"method":   method,
-							"password": password,
-							"level":    0,
-							"ota":      false,
-						},
-					},
-				},
-			},
-		},
-	}
-}
-
 // SSToV2ray converts an ss:// link to a V2Ray Shadowsocks config
 func SSToV2ray(ssLink string) (map[string]any, error) {
-	if !strings.HasPrefix(ssLink, "ss://") {
-		return nil, fmt.Errorf("invalid link: must start with ss://")
-	}
-	method, password, host, port, err := parseSSCredentials(ssLink)
-	if err != nil {
-		return nil, err
-	}
-	return buildSSV2rayConfig(method, password, host, port), nil
+	return parseAndRender(ssLink, "ss://", TargetV2Ray)
 }
 
 // SSToSingBox converts an ss:// link to a sing-box Shadowsocks config
 func SSToSingBox(ssLink string) (map[string]any, error) {
-	if !strings.HasPrefix(ssLink, "ss://") {
-		return nil, fmt.Errorf("invalid link: must start with ss://")
-	}
+	return parseAndRender(ssLink, "ss://", TargetSingBox)
+}
+
+// parseSS parses an ss:// link in either SIP002 or legacy base64 form
+func parseSS(ssLink string) (*ProxyNode, error) {
 	method, password, host, port, err := parseSSCredentials(ssLink)
 	if err != nil {
 		return nil, err
 	}
 
-	cfg := map[string]any{
-		"log": map[string]any{
-			"level": "info",
-		},
-		"inbounds": []map[string]any{
-			{
-				"type":        "socks",
-				"tag":         "socks-in",
-				"listen":      "127.0.0.1",
-				"listen_port": 1080,
-				"sniff":       true,
-			},
-		},
-		"outbounds": []map[string]any{
-			{
-				"type":        "shadowsocks",
-				"tag":         "proxy",
-				"server":      host,
-				"server_port": port,
-				"method":      method,
-				"password":    password,
-			},
-			{
-				"type": "direct",
-				"tag":  "direct",
-			},
-		},
+	node := &ProxyNode{
+		Protocol: "shadowsocks",
+		Server:   host,
+		Port:     port,
+		Method:   method,
+		Password: password,
+	}
+	if _, fragment, ok := strings.Cut(ssLink, "#"); ok {
+		if remark, err := url.PathUnescape(fragment); err == nil {
+			node.Remark = remark
+		} else {
+			node.Remark = fragment
+		}
+	}
+
+	if err := node.validate(); err != nil {
+		return nil, err
 	}
 
-	return cfg, nil
+	return node, nil
 }
@@ -0,0 +1,436 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// renderSingBox builds a sing-box JSON config around a single proxy outbound,
+// a group outbound over one outbound per node, or a chain of outbounds each
+// dialed through the previous one with detour
+func renderSingBox(nodes []*ProxyNode, opts Options) (map[string]any, error) {
+	var outbounds []map[string]any
+	if opts.grouped() {
+		outbounds = append(outbounds, singBoxGroup(opts.Group, len(nodes)))
+	}
+	for i, node := range nodes {
+		outbound, err := singBoxOutbound(node)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case opts.grouped():
+			outbound["tag"] = memberTag(i)
+		case opts.chained():
+			outbound["tag"] = chainTag(i, len(nodes))
+			if i > 0 {
+				outbound["detour"] = chainTag(i-1, len(nodes))
+			}
+		}
+		outbounds = append(outbounds, outbound)
+	}
+
+	cfg := map[string]any{
+		"log": map[string]any{
+			"level": "info",
+		},
+		"inbounds": singBoxInbounds(opts.Inbound),
+		"outbounds": append(outbounds, map[string]any{
+			"type": "direct",
+			"tag":  OutboundDirect,
+		}),
+		"dns":   singBoxDNS(nodes, opts),
+		"route": singBoxRoute(opts.Routing),
+	}
+
+	if opts.Routing.usesBlock() {
+		cfg["outbounds"] = append(cfg["outbounds"].([]map[string]any), map[string]any{
+			"type": "block",
+			"tag":  OutboundBlock,
+		})
+	}
+
+	if opts.TUN.Enabled {
+		singBoxTUN(cfg, opts.TUN)
+	}
+
+	if opts.API.enabled() {
+		singBoxAPI(cfg, opts.API)
+	}
+
+	return cfg, nil
+}
+
+// singBoxTUN adds the tun inbound next to the local proxy inbounds. DNS
+// queries captured by the TUN are answered by the dns block through a dns
+// outbound, and outbound sockets bind to the default interface to avoid loops.
+func singBoxTUN(cfg map[string]any, t TUN) {
+	tun := map[string]any{
+		"type":         "tun",
+		"tag":          "tun-in",
+		"address":      []string{tunAddress4, tunAddress6},
+		"mtu":          tunMTU,
+		"auto_route":   t.AutoRoute,
+		"strict_route": t.StrictRoute,
+		"stack":        t.Stack,
+		"sniff":        true,
+	}
+	if len(t.ExcludeRoutes) > 0 {
+		tun["route_exclude_address"] = t.ExcludeRoutes
+	}
+	cfg["inbounds"] = append([]map[string]any{tun}, cfg["inbounds"].([]map[string]any)...)
+
+	cfg["outbounds"] = append(cfg["outbounds"].([]map[string]any), map[string]any{
+		"type": "dns",
+		"tag":  "dns-out",
+	})
+
+	route := cfg["route"].(map[string]any)
+	route["auto_detect_interface"] = true
+	route["rules"] = append([]map[string]any{
+		{"protocol": "dns", "outbound": "dns-out"},
+	}, route["rules"].([]map[string]any)...)
+}
+
+// singBoxDNS renders the dns block: the remote resolver is dialed through the
+// proxy, the direct one answers for the proxy servers and direct domains.
+func singBoxDNS(nodes []*ProxyNode, opts Options) map[string]any {
+	d := opts.DNS
+	remote, _ := parseDNSServer(d.Remote)
+	direct, _ := parseDNSServer(d.Direct)
+
+	remoteServer := map[string]any{"tag": "remote", "address": remote.URL, "detour": OutboundProxy}
+	directServer := map[string]any{"tag": "direct", "address": direct.URL}
+	servers := []map[string]any{remoteServer, directServer}
+
+	if !remote.isIP() || !direct.isIP() {
+		servers = append(servers, map[string]any{"tag": "bootstrap", "address": bootstrapDNS})
+		if !remote.isIP() {
+			remoteServer["address_resolver"] = "bootstrap"
+		}
+		if !direct.isIP() {
+			directServer["address_resolver"] = "bootstrap"
+		}
+	}
+
+	rules := []map[string]any{}
+	if hosts := serverHostnames(nodes); len(hosts) > 0 {
+		rules = append(rules, map[string]any{"domain": hosts, "server": "direct"})
+	}
+
+	suffixes, geosites := opts.Routing.directDomains()
+	if len(suffixes) > 0 || len(geosites) > 0 {
+		rule := map[string]any{"server": "direct"}
+		if len(suffixes) > 0 {
+			rule["domain_suffix"] = suffixes
+		}
+		if len(geosites) > 0 {
+			var tags []string
+			for _, name := range geosites {
+				tags = append(tags, "geosite-"+name)
+			}
+			rule["rule_set"] = tags
+		}
+		rules = append(rules, rule)
+	}
+
+	cfg := map[string]any{
+		"servers": servers,
+		"rules":   rules,
+		"final":   "remote",
+	}
+	if d.Strategy != "" {
+		cfg["strategy"] = d.Strategy
+	}
+
+	if d.FakeIP {
+		cfg["servers"] = append(servers, map[string]any{"tag": "fakeip", "address": "fakeip"})
+		cfg["rules"] = append(rules, map[string]any{"query_type": []string{"A", "AAAA"}, "server": "fakeip"})
+		cfg["fakeip"] = map[string]any{
+			"enabled":     true,
+			"inet4_range": fakeIPRange4,
+			"inet6_range": fakeIPRange6,
+		}
+	}
+
+	return cfg
+}
+
+// singBoxRoute renders route.rules. geoip and geosite categories are loaded as
+// remote rule sets from the SagerNet rule-set branches.
+func singBoxRoute(r Routing) map[string]any {
+	rules := []map[string]any{}
+	ruleSets := []map[string]any{}
+	seen := map[string]bool{}
+
+	addRuleSet := func(kind, name string) string {
+		tag := kind + "-" + name
+		if !seen[tag] {
+			seen[tag] = true
+			ruleSets = append(ruleSets, map[string]any{
+				"type":   "remote",
+				"tag":    tag,
+				"format": "binary",
+				"url":    fmt.Sprintf("https://raw.githubusercontent.com/SagerNet/sing-%s/rule-set/%s.srs", kind, tag),
+			})
+		}
+		return tag
+	}
+
+	for _, rule := range r.rules() {
+		sbRule := map[string]any{"outbound": rule.Outbound}
+		if len(rule.DomainSuffix) > 0 {
+			sbRule["domain_suffix"] = rule.DomainSuffix
+		}
+		if len(rule.IPCIDR) > 0 {
+			sbRule["ip_cidr"] = rule.IPCIDR
+		}
+
+		var tags []string
+		for _, name := range rule.GeoSite {
+			tags = append(tags, addRuleSet("geosite", name))
+		}
+		for _, name := range rule.GeoIP {
+			tags = append(tags, addRuleSet("geoip", name))
+		}
+		if len(tags) > 0 {
+			sbRule["rule_set"] = tags
+		}
+
+		rules = append(rules, sbRule)
+	}
+
+	route := map[string]any{
+		"rules": rules,
+		"final": OutboundProxy,
+	}
+	if len(ruleSets) > 0 {
+		route["rule_set"] = ruleSets
+	}
+	return route
+}
+
+// singBoxInbounds renders the local SOCKS, HTTP or mixed inbounds
+func singBoxInbounds(in Inbound) []map[string]any {
+	inbound := func(inboundType, tag string, port int) map[string]any {
+		ib := map[string]any{
+			"type":        inboundType,
+			"tag":         tag,
+			"listen":      in.Listen,
+			"listen_port": port,
+			"sniff":       true,
+		}
+		if in.hasAuth() {
+			ib["users"] = []map[string]any{
+				{"username": in.Username, "password": in.Password},
+			}
+		}
+		return ib
+	}
+
+	var inbounds []map[string]any
+	if in.Mixed {
+		inbounds = append(inbounds, inbound("mixed", "mixed-in", in.SocksPort))
+	} else {
+		inbounds = append(inbounds, inbound("socks", "socks-in", in.SocksPort))
+	}
+	if in.HTTPPort > 0 {
+		inbounds = append(inbounds, inbound("http", "http-in", in.HTTPPort))
+	}
+	return inbounds
+}
+
+// singBoxOutbound renders the proxy outbound of a node
+func singBoxOutbound(node *ProxyNode) (map[string]any, error) {
+	out := map[string]any{
+		"tag":         "proxy",
+		"server":      node.Server,
+		"server_port": node.Port,
+	}
+
+	switch node.Protocol {
+	case "vmess":
+		out["type"] = "vmess"
+		out["uuid"] = node.UUID
+		out["security"] = node.Security
+		out["alter_id"] = node.AlterID
+	case "vless":
+		out["type"] = "vless"
+		out["uuid"] = node.UUID
+		if node.Flow != "" {
+			out["flow"] = node.Flow
+		}
+	case "shadowsocks":
+		out["type"] = "shadowsocks"
+		out["method"] = node.Method
+		out["password"] = node.Password
+	case "trojan":
+		out["type"] = "trojan"
+		out["password"] = node.Password
+	case "hysteria2":
+		out["type"] = "hysteria2"
+		out["password"] = node.Password
+		if node.UpMbps > 0 {
+			out["up_mbps"] = node.UpMbps
+		}
+		if node.DownMbps > 0 {
+			out["down_mbps"] = node.DownMbps
+		}
+		if node.ObfsType != "" {
+			out["obfs"] = map[string]any{
+				"type":     node.ObfsType,
+				"password": node.ObfsPassword,
+			}
+		}
+	case "tuic":
+		out["type"] = "tuic"
+		out["uuid"] = node.UUID
+		out["password"] = node.Password
+		if node.CongestionControl != "" {
+			out["congestion_control"] = node.CongestionControl
+		}
+		if node.UDPRelayMode != "" {
+			out["udp_relay_mode"] = node.UDPRelayMode
+		}
+	default:
+		return nil, fmt.Errorf("unsupported protocol for sing-box: %s", node.Protocol)
+	}
+
+	transport, err := singBoxTransport(node.Transport)
+	if err != nil {
+		return nil, err
+	}
+	if transport != nil {
+		out["transport"] = transport
+	}
+	if node.TLS.Enabled {
+		out["tls"] = singBoxTLS(node)
+	}
+	if node.Mux.Enabled {
+		out["multiplex"] = singBoxMultiplex(node.Mux)
+	}
+
+	return out, nil
+}
+
+// singBoxTransport renders the transport block, nil for plain tcp
+func singBoxTransport(t Transport) (map[string]any, error) {
+	switch t.Type {
+	case "", "tcp":
+		if t.HeaderType != "http" {
+			return nil, nil
+		}
+		// V2Ray tcp http obfuscation is plain HTTP/1.1 without TLS
+		transport := map[string]any{
+			"type":   "http",
+			"method": "GET",
+		}
+		if hosts := splitList(t.Host); len(hosts) > 0 {
+			transport["host"] = hosts
+		}
+		if t.Path != "" {
+			transport["path"] = t.Path
+		}
+		return transport, nil
+
+	case "ws":
+		transport := map[string]any{
+			"type": "ws",
+			"path": t.Path,
+		}
+		if t.Host != "" {
+			transport["headers"] = map[string]any{
+				"Host": t.Host,
+			}
+		}
+		return transport, nil
+
+	case "grpc":
+		return map[string]any{
+			"type":         "grpc",
+			"service_name": t.ServiceName,
+		}, nil
+
+	case "http", "h2":
+		transport := map[string]any{
+			"type": "http",
+		}
+		if hosts := splitList(t.Host); len(hosts) > 0 {
+			transport["host"] = hosts
+		}
+		if t.Path != "" {
+			transport["path"] = t.Path
+		}
+		return transport, nil
+
+	case "httpupgrade":
+		transport := map[string]any{
+			"type": "httpupgrade",
+			"path": t.Path,
+		}
+		if t.Host != "" {
+			transport["host"] = t.Host
+		}
+		return transport, nil
+
+	case "xhttp", "splithttp":
+		return nil, fmt.Errorf("%s transport is not supported by sing-box, use Xray instead", t.Type)
+
+	case "quic":
+		if (t.QUICSecurity != "" && t.QUICSecurity != "none") || t.HeaderType != "" {
+			return nil, fmt.Errorf("quic encryption and header obfuscation are not supported by sing-box, use V2Ray or Xray instead")
+		}
+		return map[string]any{
+			"type": "quic",
+		}, nil
+
+	default:
+		return map[string]any{
+			"type": t.Type,
+		}, nil
+	}
+}
+
+// singBoxTLS renders the outbound tls block
+func singBoxTLS(node *ProxyNode) map[string]any {
+	tls := map[string]any{
+		"enabled":     true,
+		"server_name": node.TLS.ServerName,
+		"insecure":    node.TLS.Insecure,
+	}
+	if len(node.TLS.ALPN) > 0 {
+		tls["alpn"] = node.TLS.ALPN
+	}
+	fingerprint := node.TLS.Fingerprint
+	if node.TLS.Reality.Enabled {
+		tls["reality"] = map[string]any{
+			"enabled":    true,
+			"public_key": node.TLS.Reality.PublicKey,
+			"short_id":   node.TLS.Reality.ShortID,
+		}
+		// sing-box only performs the REALITY handshake through uTLS
+		if fingerprint == "" {
+			fingerprint = defaultFingerprint
+		}
+	}
+	if fingerprint != "" {
+		tls["utls"] = map[string]any{
+			"enabled":     true,
+			"fingerprint": fingerprint,
+		}
+	}
+	return tls
+}
+
+// singBoxMultiplex renders the outbound multiplex block
+func singBoxMultiplex(mux Mux) map[string]any {
+	multiplex := map[string]any{
+		"enabled": true,
+	}
+	if mux.Protocol != "" {
+		multiplex["protocol"] = mux.Protocol
+	}
+	if mux.MaxConnections > 0 {
+		multiplex["max_connections"] = mux.MaxConnections
+	}
+	return multiplex
+}
@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// TrojanToSingBox converts a trojan:// link into sing-box JSON config
+func TrojanToSingBox(trojanLink string) (map[string]any, error) {
+	return parseAndRender(trojanLink, "trojan://", TargetSingBox)
+}
+
+// TrojanToV2Ray converts a trojan:// link into V2Ray JSON config
+func TrojanToV2Ray(trojanLink string) (map[string]any, error) {
+	return parseAndRender(trojanLink, "trojan://", TargetV2Ray)
+}
+
+// parseTrojan parses a trojan://password@host:port?query#remark link
+func parseTrojan(trojanLink string) (*ProxyNode, error) {
+	u, err := url.Parse(trojanLink)
+	if err != nil {
+		return nil, fmt.Errorf("invalid trojan link: %w", err)
+	}
+
+	password := u.User.Username()
+	if password == "" {
+		return nil, fmt.Errorf("missing password in Trojan link")
+	}
+
+	portStr := u.Port()
+	if portStr == "" {
+		portStr = "443"
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port: %w", err)
+	}
+
+	q := u.Query()
+	network := strings.ToLower(q.Get("type"))
+	switch network {
+	case "":
+		network = "tcp"
+	case "tcp", "ws", "grpc":
+		// valid
+	default:
+		return nil, fmt.Errorf("unsupported transport type for Trojan: %s", network)
+	}
+
+	node := &ProxyNode{
+		Protocol:  "trojan",
+		Remark:    u.Fragment,
+		Server:    u.Hostname(),
+		Port:      port,
+		Password:  password,
+		Transport: transportFromQuery(network, q),
+	}
+
+	// Trojan always runs over TLS unless the link explicitly turns it off
+	switch security := strings.ToLower(q.Get("security")); security {
+	case "none":
+	case "", "tls":
+		node.TLS = tlsFromQuery(q)
+		if node.TLS.ServerName == "" {
+			node.TLS.ServerName = q.Get("peer")
+		}
+		if node.TLS.ServerName == "" {
+			node.TLS.ServerName = node.Server
+		}
+	default:
+		return nil, fmt.Errorf("unsupported security type for Trojan: %s", security)
+	}
+
+	if err := node.validate(); err != nil {
+		return nil, err
+	}
+
+	return node, nil
+}
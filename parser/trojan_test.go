@@ -0,0 +1,113 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestTrojanToSingBox(t *testing.T) {
+	trojanLink := "trojan://secret%40pass@example.com:443?sni=sni.example.com&type=ws&path=/ws&host=cdn.example.com&alpn=h2,http/1.1&allowInsecure=1#Trojan%20Node"
+
+	cfg, err := TrojanToSingBox(trojanLink)
+	if err != nil {
+		t.Fatalf("TrojanToSingBox failed: %v", err)
+	}
+
+	outbounds, ok := cfg["outbounds"].([]map[string]any)
+	if !ok || len(outbounds) < 2 {
+		t.Fatal("TrojanToSingBox expected at least 2 outbounds (proxy + direct)")
+	}
+
+	proxy := outbounds[0]
+	if proxy["type"] != "trojan" {
+		t.Errorf("TrojanToSingBox proxy type = %v, want trojan", proxy["type"])
+	}
+	if proxy["password"] != "secret@pass" {
+		t.Errorf("TrojanToSingBox password = %v, want secret@pass", proxy["password"])
+	}
+	if proxy["server_port"] != 443 {
+		t.Errorf("TrojanToSingBox server_port = %v, want 443", proxy["server_port"])
+	}
+
+	tls, ok := proxy["tls"].(map[string]any)
+	if !ok {
+		t.Fatal("TrojanToSingBox tls is not a map")
+	}
+	if tls["server_name"] != "sni.example.com" {
+		t.Errorf("TrojanToSingBox server_name = %v, want sni.example.com", tls["server_name"])
+	}
+	if tls["insecure"] != true {
+		t.Errorf("TrojanToSingBox insecure = %v, want true", tls["insecure"])
+	}
+	if alpn, ok := tls["alpn"].([]string); !ok || len(alpn) != 2 {
+		t.Errorf("TrojanToSingBox alpn = %v, want [h2 http/1.1]", tls["alpn"])
+	}
+
+	transport, ok := proxy["transport"].(map[string]any)
+	if !ok || transport["type"] != "ws" {
+		t.Errorf("TrojanToSingBox transport = %v, want ws", proxy["transport"])
+	}
+}
+
+func TestTrojanToV2Ray(t *testing.T) {
+	trojanLink := "trojan://password@example.com:8443?security=tls&type=grpc&serviceName=svc#Trojan"
+
+	cfg, err := TrojanToV2Ray(trojanLink)
+	if err != nil {
+		t.Fatalf("TrojanToV2Ray failed: %v", err)
+	}
+
+	outbounds, ok := cfg["outbounds"].([]map[string]any)
+	if !ok || len(outbounds) == 0 {
+		t.Fatal("TrojanToV2Ray outbounds is not valid")
+	}
+
+	proxy := outbounds[0]
+	if proxy["protocol"] != "trojan" {
+		t.Errorf("TrojanToV2Ray protocol = %v, want trojan", proxy["protocol"])
+	}
+
+	servers := proxy["settings"].(map[string]any)["servers"].([]map[string]any)
+	if servers[0]["address"] != "example.com" || servers[0]["port"] != 8443 || servers[0]["password"] != "password" {
+		t.Errorf("TrojanToV2Ray server = %v, want example.com:8443 with password", servers[0])
+	}
+
+	stream, ok := proxy["streamSettings"].(map[string]any)
+	if !ok {
+		t.Fatal("TrojanToV2Ray streamSettings is not a map")
+	}
+	if stream["security"] != "tls" {
+		t.Errorf("TrojanToV2Ray security = %v, want tls", stream["security"])
+	}
+
+	// SNI falls back to the server address when not given
+	tlsSettings := stream["tlsSettings"].(map[string]any)
+	if tlsSettings["serverName"] != "example.com" {
+		t.Errorf("TrojanToV2Ray serverName = %v, want example.com", tlsSettings["serverName"])
+	}
+}
+
+func TestTrojan_InvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty string", ""},
+		{"invalid prefix", "vless://password@example.com:443"},
+		{"missing password", "trojan://@example.com:443"},
+		{"missing server", "trojan://password@:443"},
+		{"invalid port", "trojan://password@example.com:invalid"},
+		{"unsupported transport", "trojan://password@example.com:443?type=kcp"},
+		{"unsupported security", "trojan://password@example.com:443?security=reality"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := TrojanToSingBox(tt.input); err == nil {
+				t.Errorf("TrojanToSingBox(%q) expected error but got none", tt.input)
+			}
+			if _, err := TrojanToV2Ray(tt.input); err == nil {
+				t.Errorf("TrojanToV2Ray(%q) expected error but got none", tt.input)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package parser
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	DefaultTUNStack = "mixed"
+
+	tunAddress4 = "172.19.0.1/30"
+	tunAddress6 = "fdfe:dcba:9876::1/126"
+	tunMTU      = 9000
+)
+
+// TUNStacks are the network stacks sing-box can run the TUN inbound on
+var TUNStacks = []string{"system", "gvisor", "mixed"}
+
+// TUN describes the sing-box TUN inbound that captures system-wide traffic
+type TUN struct {
+	Enabled       bool     `json:"-"`               // chosen per connection, never stored
+	Stack         string   `json:"stack,omitempty"` // one of TUNStacks, default mixed
+	AutoRoute     bool     `json:"auto_route"`
+	StrictRoute   bool     `json:"strict_route,omitempty"`
+	ExcludeRoutes []string `json:"exclude_routes,omitempty"` // CIDRs kept off the TUN
+}
+
+// DefaultTUN returns the TUN settings used until the user changes them
+func DefaultTUN() TUN {
+	return TUN{Stack: DefaultTUNStack, AutoRoute: true}
+}
+
+// WithDefaults fills in the stack when it is unset
+func (t TUN) WithDefaults() TUN {
+	if t.Stack == "" {
+		t.Stack = DefaultTUNStack
+	}
+	return t
+}
+
+// Validate checks the stack and excluded routes
+func (t TUN) Validate() error {
+	t = t.WithDefaults()
+
+	if !containsString(TUNStacks, t.Stack) {
+		return fmt.Errorf("invalid TUN stack: %s", t.Stack)
+	}
+	for _, cidr := range t.ExcludeRoutes {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid excluded route %q", cidr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"testing"
+)
+
+func TestTUN_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		tun         TUN
+		expectError bool
+	}{
+		{name: "defaults", tun: DefaultTUN()},
+		{name: "empty stack", tun: TUN{}},
+		{name: "gvisor with excludes", tun: TUN{Stack: "gvisor", ExcludeRoutes: []string{"10.0.0.0/8", "fd00::/8"}}},
+		{name: "unknown stack", tun: TUN{Stack: "lwip"}, expectError: true},
+		{name: "invalid excluded route", tun: TUN{ExcludeRoutes: []string{"10.0.0.1"}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.tun.Validate()
+			if (err != nil) != tt.expectError {
+				t.Errorf("Validate() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestRenderWithOptions_TUN(t *testing.T) {
+	node, err := Parse("trojan://secret@example.com:443#Trojan")
+	if err != nil {
+		t.Fatalf("Parse() unexpected error: %v", err)
+	}
+
+	opts := DefaultOptions()
+	opts.TUN = TUN{Enabled: true, Stack: "system", AutoRoute: true, StrictRoute: true, ExcludeRoutes: []string{"192.168.0.0/16"}}
+
+	cfg, err := RenderWithOptions(node, TargetSingBox, opts)
+	if err != nil {
+		t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+	}
+
+	inbounds := cfg["inbounds"].([]map[string]any)
+	if len(inbounds) != 2 || inbounds[1]["type"] != "socks" {
+		t.Errorf("inbounds = %v, want tun followed by the SOCKS inbound", inbounds)
+	}
+	tun := inbounds[0]
+	if tun["type"] != "tun" || tun["stack"] != "system" || tun["auto_route"] != true || tun["strict_route"] != true {
+		t.Errorf("tun inbound = %v", tun)
+	}
+	if excludes := tun["route_exclude_address"].([]string); len(excludes) != 1 || excludes[0] != "192.168.0.0/16" {
+		t.Errorf("route_exclude_address = %v", excludes)
+	}
+
+	route := cfg["route"].(map[string]any)
+	if route["auto_detect_interface"] != true {
+		t.Error("route.auto_detect_interface should be set in TUN mode")
+	}
+	if first := route["rules"].([]map[string]any)[0]; first["protocol"] != "dns" || first["outbound"] != "dns-out" {
+		t.Errorf("first route rule = %v, want DNS hijack", first)
+	}
+	if !hasOutbound(cfg, "type", "dns") {
+		t.Error("dns outbound missing in TUN mode")
+	}
+
+	// TUN stays off unless requested
+	plain, err := RenderWithOptions(node, TargetSingBox, DefaultOptions())
+	if err != nil {
+		t.Fatalf("RenderWithOptions() unexpected error: %v", err)
+	}
+	if inbounds := plain["inbounds"].([]map[string]any); inbounds[0]["type"] == "tun" {
+		t.Error("tun inbound rendered without TUN enabled")
+	}
+
+	// Other cores are rejected
+	for _, target := range []Target{TargetV2Ray, TargetXray, TargetMihomo} {
+		if _, err := RenderWithOptions(node, target, opts); err == nil {
+			t.Errorf("RenderWithOptions(%s) with TUN should fail", target)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// renderV2Ray builds a V2Ray JSON config around a single proxy outbound, a balancer or a chain
+func renderV2Ray(nodes []*ProxyNode, opts Options) (map[string]any, error) {
+	return renderV2RayConfig(nodes, TargetV2Ray, opts)
+}
+
+// renderV2RayConfig builds the config layout shared by V2Ray and Xray. The
+// proxy outbounds come first, in node order.
+func renderV2RayConfig(nodes []*ProxyNode, target Target, opts Options) (map[string]any, error) {
+	var outbounds []map[string]any
+	for i, node := range nodes {
+		outbound, err := v2rayOutbound(node, target)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case opts.grouped():
+			outbound["tag"] = memberTag(i)
+		case opts.chained():
+			outbound["tag"] = chainTag(i, len(nodes))
+			if i > 0 {
+				v2rayDetour(outbound, chainTag(i-1, len(nodes)), target)
+			}
+		}
+		outbounds = append(outbounds, outbound)
+	}
+
+	dns, err := v2rayDNS(nodes, target, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := map[string]any{
+		"log": map[string]any{
+			"loglevel": "info",
+		},
+		"dns":      dns,
+		"inbounds": v2rayInbounds(opts.Inbound, opts.DNS.FakeIP),
+		"outbounds": append(outbounds, map[string]any{
+			"tag":      OutboundDirect,
+			"protocol": "freedom",
+			"settings": map[string]any{
+				"domainStrategy": v2rayDomainStrategy(opts.DNS.Strategy),
+			},
+		}),
+		"routing": v2rayRouting(opts.Routing, opts.DNS),
+	}
+
+	if opts.grouped() {
+		if err := v2rayBalancer(cfg, opts.Group, target); err != nil {
+			return nil, err
+		}
+	}
+	if opts.chained() {
+		// Without a catch-all the first outbound, the entry hop, would carry the
+		// rest past the chain
+		routing := cfg["routing"].(map[string]any)
+		routing["rules"] = append(routing["rules"].([]map[string]any), map[string]any{
+			"type":        "field",
+			"network":     "tcp,udp",
+			"outboundTag": OutboundProxy,
+		})
+	}
+
+	if opts.DNS.FakeIP {
+		cfg["fakedns"] = []map[string]any{
+			{"ipPool": fakeIPRange4, "poolSize": 65535},
+		}
+	}
+
+	if opts.Routing.usesBlock() {
+		cfg["outbounds"] = append(cfg["outbounds"].([]map[string]any), map[string]any{
+			"tag":      OutboundBlock,
+			"protocol": "blackhole",
+		})
+	}
+
+	if opts.API.enabled() {
+		v2rayAPI(cfg, opts.API)
+	}
+
+	return cfg, nil
+}
+
+// v2rayRouting renders routing.rules. A V2Ray rule matches only when all of
+// its fields match, so domain and IP conditions become separate rules. The
+// resolvers are pinned first so DNS queries leave through the intended outbound.
+func v2rayRouting(r Routing, d DNS) map[string]any {
+	remote, _ := parseDNSServer(d.Remote)
+	direct, _ := parseDNSServer(d.Direct)
+	rules := []map[string]any{
+		v2rayDNSServerRule(remote, OutboundProxy),
+		v2rayDNSServerRule(direct, OutboundDirect),
+	}
+
+	for _, rule := range r.rules() {
+		var domains, ips []string
+		for _, suffix := range rule.DomainSuffix {
+			domains = append(domains, "domain:"+suffix)
+		}
+		for _, name := range rule.GeoSite {
+			domains = append(domains, "geosite:"+name)
+		}
+		ips = append(ips, rule.IPCIDR...)
+		for _, name := range rule.GeoIP {
+			ips = append(ips, "geoip:"+name)
+		}
+
+		if len(domains) > 0 {
+			rules = append(rules, map[string]any{
+				"type":        "field",
+				"domain":      domains,
+				"outboundTag": rule.Outbound,
+			})
+		}
+		if len(ips) > 0 {
+			rules = append(rules, map[string]any{
+				"type":        "field",
+				"ip":          ips,
+				"outboundTag": rule.Outbound,
+			})
+		}
+	}
+
+	// Country rules only see domains once they are resolved
+	strategy := "AsIs"
+	if r.usesGeoIP() {
+		strategy = "IPIfNonMatch"
+	}
+
+	return map[string]any{
+		"domainStrategy": strategy,
+		"rules":          rules,
+	}
+}
+
+// v2rayDNS renders the dns block. Direct domains and the proxy servers are
+// resolved by the direct server; everything else by the remote one.
+func v2rayDNS(nodes []*ProxyNode, target Target, opts Options) (map[string]any, error) {
+	d := opts.DNS
+
+	var servers []any
+	var domains []string
+	for _, host := range serverHostnames(nodes) {
+		domains = append(domains, "full:"+host)
+	}
+	suffixes, geosites := opts.Routing.directDomains()
+	for _, suffix := range suffixes {
+		domains = append(domains, "domain:"+suffix)
+	}
+	for _, name := range geosites {
+		domains = append(domains, "geosite:"+name)
+	}
+
+	if len(domains) > 0 {
+		direct, err := v2rayDNSServer(d.Direct, target)
+		if err != nil {
+			return nil, err
+		}
+		direct["domains"] = domains
+		direct["skipFallback"] = true
+		servers = append(servers, direct)
+	}
+	if d.FakeIP {
+		servers = append(servers, "fakedns")
+	}
+
+	remote, err := v2rayDNSServer(d.Remote, target)
+	if err != nil {
+		return nil, err
+	}
+	servers = append(servers, remote)
+
+	dns := map[string]any{"servers": servers}
+	switch d.Strategy {
+	case "ipv4-only":
+		dns["queryStrategy"] = "UseIPv4"
+	case "ipv6-only":
+		dns["queryStrategy"] = "UseIPv6"
+	case "prefer-ipv4", "prefer-ipv6":
+		dns["queryStrategy"] = "UseIP"
+	}
+	return dns, nil
+}
+
+// v2rayDNSServer renders a DNS server object. V2Ray and Xray have no DNS over TLS client.
+func v2rayDNSServer(address string, target Target) (map[string]any, error) {
+	server, err := parseDNSServer(address)
+	if err != nil {
+		return nil, err
+	}
+
+	switch server.Scheme {
+	case "udp":
+		return map[string]any{"address": server.Host, "port": server.Port}, nil
+	case "tls":
+		return nil, fmt.Errorf("DNS over TLS is not supported by %s, use an https:// server", v2rayName(target))
+	default:
+		return map[string]any{"address": server.URL}, nil
+	}
+}
+
+// v2rayDNSServerRule routes the traffic to a DNS server through outbound
+func v2rayDNSServerRule(server dnsServer, outbound string) map[string]any {
+	rule := map[string]any{
+		"type":        "field",
+		"port":        strconv.Itoa(server.Port),
+		"outboundTag": outbound,
+	}
+	if server.isIP() {
+		rule["ip"] = []string{server.Host}
+	} else {
+		rule["domain"] = []string{"full:" + server.Host}
+	}
+	return rule
+}
+
+// v2rayDomainStrategy maps the DNS strategy onto the freedom outbound's domainStrategy
+func v2rayDomainStrategy(strategy string) string {
+	switch strategy {
+	case "ipv4-only":
+		return "UseIPv4"
+	case "ipv6-only":
+		return "UseIPv6"
+	default:
+		return "UseIP"
+	}
+}
+
+// v2rayInbounds renders the local SOCKS and HTTP inbounds. V2Ray has no mixed
+// inbound, so HTTP is only served when a separate HTTP port is configured.
+func v2rayInbounds(in Inbound, fakeDNS bool) []map[string]any {
+	destOverride := []string{"http", "tls"}
+	if fakeDNS {
+		destOverride = append(destOverride, "fakedns")
+	}
+
+	socks := map[string]any{
+		"auth": "noauth",
+		"udp":  true,
+	}
+	if in.hasAuth() {
+		socks["auth"] = "password"
+		socks["accounts"] = []map[string]any{
+			{"user": in.Username, "pass": in.Password},
+		}
+	}
+
+	inbounds := []map[string]any{
+		{
+			"tag":      "socks-in",
+			"port":     in.SocksPort,
+			"listen":   in.Listen,
+			"protocol": "socks",
+			"sniffing": map[string]any{
+				"enabled":      true,
+				"destOverride": destOverride,
+			},
+			"settings": socks,
+		},
+	}
+
+	if in.HTTPPort > 0 {
+		http := map[string]any{}
+		if in.hasAuth() {
+			http["accounts"] = []map[string]any{
+				{"user": in.Username, "pass": in.Password},
+			}
+		}
+		inbounds = append(inbounds, map[string]any{
+			"tag":      "http-in",
+			"port":     in.HTTPPort,
+			"listen":   in.Listen,
+			"protocol": "http",
+			"settings": http,
+		})
+	}
+	return inbounds
+}
+
+// v2rayOutbound renders the proxy outbound of a node
+func v2rayOutbound(node *ProxyNode, target Target) (map[string]any, error) {
+	out := map[string]any{
+		"tag": "proxy",
+	}
+
+	switch node.Protocol {
+	case "vmess":
+		out["protocol"] = "vmess"
+		out["settings"] = map[string]any{
+			"vnext": []map[string]any{
+				{
+					"address": node.Server,
+					"port":    node.Port,
+					"users": []map[string]any{
+						{
+							"id":       node.UUID,
+							"alterId":  node.AlterID,
+							"security": node.Security,
+						},
+					},
+				},
+			},
+		}
+	case "vless":
+		user := map[string]any{
+			"id":         node.UUID,
+			"encryption": "none",
+		}
+		if node.Flow != "" {
+			user["flow"] = node.Flow
+		}
+		out["protocol"] = "vless"
+		out["settings"] = map[string]any{
+			"vnext": []map[string]any{
+				{
+					"address": node.Server,
+					"port":    node.Port,
+					"users":   []map[string]any{user},
+				},
+			},
+		}
+	case "shadowsocks":
+		out["protocol"] = "shadowsocks"
+		out["settings"] = map[string]any{
+			"servers": []map[string]any{
+				{
+					"address":  node.Server,
+					"port":     node.Port,
+					"method":   node.Method,
+					"password": node.Password,
+				},
+			},
+		}
+	case "trojan":
+		out["protocol"] = "trojan"
+		out["settings"] = map[string]any{
+			"servers": []map[string]any{
+				{
+					"address":  node.Server,
+					"port":     node.Port,
+					"password": node.Password,
+				},
+			},
+		}
+	case "hysteria2", "tuic":
+		return nil, fmt.Errorf("%s is not supported by %s, use sing-box instead", node.Protocol, v2rayName(target))
+	default:
+		return nil, fmt.Errorf("unsupported protocol for %s: %s", v2rayName(target), node.Protocol)
+	}
+
+	stream, err := v2rayStreamSettings(node, target)
+	if err != nil {
+		return nil, err
+	}
+	if stream != nil {
+		out["streamSettings"] = stream
+	}
+	if node.Mux.Enabled {
+		out["mux"] = v2rayMux(node.Mux)
+	}
+
+	return out, nil
+}
+
+// v2rayStreamSettings renders transport and TLS settings, nil when the node has neither
+func v2rayStreamSettings(node *ProxyNode, target Target) (map[string]any, error) {
+	t := node.Transport
+	network := t.Type
+	switch network {
+	case "":
+		network = "tcp"
+	case "h2":
+		network = "http"
+	case "xhttp", "splithttp":
+		if target != TargetXray {
+			return nil, fmt.Errorf("%s transport is not supported by V2Ray, use Xray instead", network)
+		}
+		network = "xhttp"
+	}
+	if network == "tcp" && t.HeaderType == "" && !node.TLS.Enabled {
+		return nil, nil
+	}
+
+	stream := map[string]any{
+		"network":  network,
+		"security": "none",
+	}
+
+	switch network {
+	case "tcp":
+		if t.HeaderType == "http" {
+			stream["tcpSettings"] = v2rayHTTPObfs(t)
+		}
+
+	case "ws":
+		ws := map[string]any{
+			"path": t.Path,
+		}
+		if t.Host != "" {
+			ws["headers"] = map[string]any{
+				"Host": t.Host,
+			}
+		}
+		stream["wsSettings"] = ws
+
+	case "grpc":
+		stream["grpcSettings"] = map[string]any{
+			"serviceName": t.ServiceName,
+		}
+
+	case "http":
+		httpSettings := map[string]any{
+			"path": t.Path,
+		}
+		if hosts := splitList(t.Host); len(hosts) > 0 {
+			httpSettings["host"] = hosts
+		}
+		stream["httpSettings"] = httpSettings
+
+	case "httpupgrade":
+		stream["httpupgradeSettings"] = map[string]any{
+			"path": t.Path,
+			"host": t.Host,
+		}
+
+	case "xhttp":
+		stream["xhttpSettings"] = xrayXHTTPSettings(t)
+
+	case "quic":
+		security, header := t.QUICSecurity, t.HeaderType
+		if security == "" {
+			security = "none"
+		}
+		if header == "" {
+			header = "none"
+		}
+		stream["quicSettings"] = map[string]any{
+			"security": security,
+			"key":      t.QUICKey,
+			"header": map[string]any{
+				"type": header,
+			},
+		}
+	}
+
+	switch {
+	case node.TLS.Reality.Enabled:
+		stream["security"] = "reality"
+		stream["realitySettings"] = v2rayRealitySettings(node.TLS)
+	case node.TLS.Enabled:
+		stream["security"] = "tls"
+		stream["tlsSettings"] = v2rayTLSSettings(node.TLS)
+	}
+
+	return stream, nil
+}
+
+// v2rayName returns the display name of a V2Ray-family target for error messages
+func v2rayName(target Target) string {
+	if target == TargetXray {
+		return "Xray"
+	}
+	return "V2Ray"
+}
+
+// v2rayHTTPObfs renders tcpSettings for tcp with http header obfuscation
+func v2rayHTTPObfs(t Transport) map[string]any {
+	path := t.Path
+	if path == "" {
+		path = "/"
+	}
+
+	request := map[string]any{
+		"path": splitList(path),
+	}
+	if hosts := splitList(t.Host); len(hosts) > 0 {
+		request["headers"] = map[string]any{
+			"Host": hosts,
+		}
+	}
+
+	return map[string]any{
+		"header": map[string]any{
+			"type":    "http",
+			"request": request,
+		},
+	}
+}
+
+// v2rayTLSSettings renders tlsSettings for a TLS enabled node
+func v2rayTLSSettings(tls TLS) map[string]any {
+	settings := map[string]any{
+		"serverName":    tls.ServerName,
+		"allowInsecure": tls.Insecure,
+	}
+	if len(tls.ALPN) > 0 {
+		settings["alpn"] = tls.ALPN
+	}
+	if tls.Fingerprint != "" {
+		settings["fingerprint"] = tls.Fingerprint
+	}
+	return settings
+}
+
+// v2rayRealitySettings renders realitySettings for a REALITY node, Xray only
+func v2rayRealitySettings(tls TLS) map[string]any {
+	fingerprint := tls.Fingerprint
+	if fingerprint == "" {
+		fingerprint = defaultFingerprint
+	}
+
+	settings := map[string]any{
+		"serverName":  tls.ServerName,
+		"fingerprint": fingerprint,
+		"publicKey":   tls.Reality.PublicKey,
+		"shortId":     tls.Reality.ShortID,
+	}
+	if tls.Reality.SpiderX != "" {
+		settings["spiderX"] = tls.Reality.SpiderX
+	}
+	return settings
+}
+
+// v2rayMux renders the outbound mux block
+func v2rayMux(mux Mux) map[string]any {
+	concurrency := mux.Concurrency
+	if concurrency == 0 {
+		concurrency = 8
+	}
+	return map[string]any{
+		"enabled":     true,
+		"concurrency": concurrency,
+	}
+}
@@ -7,11 +7,47 @@ import (
 	"strings"
 )
 
+// VLESSToSingBox converts a vless:// link into sing-box JSON config
 func VLESSToSingBox(vlessLink string) (map[string]any, error) {
-	if !strings.HasPrefix(vlessLink, "vless://") {
-		return nil, fmt.Errorf("invalid link: must start with vless://")
+	return parseAndRender(vlessLink, "vless://", TargetSingBox)
+}
+
+// VLESSToV2Ray converts a vless:// link into V2Ray JSON config
+func VLESSToV2Ray(vlessLink string) (map[string]any, error) {
+	return parseAndRender(vlessLink, "vless://", TargetV2Ray)
+}
+
+// visionFlow prefixes the XTLS Vision flows xtls-rprx-vision and xtls-rprx-vision-udp443
+const visionFlow = "xtls-rprx-vision"
+
+// validateFlow checks that target can carry the VLESS flow of node. V2Ray has
+// no XTLS Vision; Xray only runs it on raw TCP under TLS or REALITY without mux.
+func validateFlow(node *ProxyNode, target Target) error {
+	if !strings.HasPrefix(node.Flow, visionFlow) {
+		return nil
 	}
+	switch target {
+	case TargetV2Ray:
+		return fmt.Errorf("V2Ray does not support the XTLS Vision flow, connect with Xray")
+	case TargetXray:
+		if network := node.Transport.Type; network != "" && network != "tcp" {
+			return fmt.Errorf("XTLS Vision needs the tcp transport, not %s", network)
+		}
+		if header := node.Transport.HeaderType; header != "" && header != "none" {
+			return fmt.Errorf("XTLS Vision cannot be used with the %s header", header)
+		}
+		if !node.TLS.Enabled {
+			return fmt.Errorf("XTLS Vision needs TLS or REALITY")
+		}
+		if node.Mux.Enabled {
+			return fmt.Errorf("XTLS Vision cannot be used with mux")
+		}
+	}
+	return nil
+}
 
+// parseVLESS parses a vless://uuid@host:port?query#remark link; the id must be a UUID and the port is required
+func parseVLESS(vlessLink string) (*ProxyNode, error) {
 	u, err := url.Parse(vlessLink)
 	if err != nil {
 		return nil, fmt.Errorf("invalid vless link: %w", err)
@@ -21,171 +57,132 @@ func VLESSToSingBox(vlessLink string) (map[string]any, error) {
 	if uuid == "" {
 		return nil, fmt.Errorf("missing UUID in VLESS link")
 	}
+	if !isUUID(uuid) {
+		return nil, fmt.Errorf("invalid UUID in VLESS link: %s (expected the 8-4-4-4-12 hex form)", uuid)
+	}
 
-	host := u.Hostname()
-	portStr := u.Port()
-
-	q := u.Query()
-	security := strings.ToLower(q.Get("security"))
-	if portStr == "" {
-		if security == "tls" {
-			portStr = "443"
-		} else {
-			portStr = "80"
-		}
+	if u.Port() == "" {
+		return nil, fmt.Errorf("missing port in VLESS link")
 	}
-	port, err := strconv.Atoi(portStr)
+	port, err := strconv.Atoi(u.Port())
 	if err != nil {
 		return nil, fmt.Errorf("invalid port: %w", err)
 	}
 
-	sni := q.Get("sni")
-	fp := q.Get("fp")
+	q := u.Query()
 	network := strings.ToLower(q.Get("type"))
-	path := q.Get("path")
-	hostHeader := q.Get("host")
-
-	// Map or validate transport type
 	switch network {
-	case "xhttp":
-		network = "http" // map unsupported to supported
-	case "tcp", "ws", "grpc", "http", "quic", "h2", "splithttp":
+	case "":
+		network = "tcp"
+	case "tcp", "ws", "grpc", "http", "quic", "h2", "httpupgrade", "splithttp", "xhttp":
 		// valid
 	default:
 		return nil, fmt.Errorf("unsupported transport type: %s", network)
 	}
 
-	cfg := map[string]any{
-		"log": map[string]any{
-			"level": "info",
-		},
-		"inbounds": []map[string]any{
-			{
-				"type":                       "socks",
-				"tag":                        "socks-in",
-				"listen":                     "127.0.0.1",
-				"listen_port":                1080,
-				"sniff":                      true,
-				"sniff_override_destination": true,
-			},
-		},
-		"outbounds": []map[string]any{
-			{
-				"type":        "vless",
-				"tag":         "proxy-out",
-				"server":      host,
-				"server_port": port,
-				"uuid":        uuid,
-				"flow":        q.Get("flow"),
-				"tls": map[string]any{
-					"enabled":     security == "tls",
-					"server_name": sni,
-					"utls": map[string]any{
-						"enabled":     fp != "",
-						"fingerprint": fp,
-					},
-				},
-				"transport": map[string]any{
-					"type": network,
-					"path": path,
-					"headers": map[string]any{
-						"Host": hostHeader,
-					},
-				},
-			},
-		},
-	}
-
-	return cfg, nil
-}
+	node := &ProxyNode{
+		Protocol:  "vless",
+		Remark:    u.Fragment,
+		Server:    u.Hostname(),
+		Port:      port,
+		UUID:      uuid,
+		Flow:      q.Get("flow"),
+		Transport: transportFromQuery(network, q),
+	}
 
-func VLESSToV2Ray(vlessLink string) (map[string]any, error) {
-	if !strings.HasPrefix(vlessLink, "vless://") {
-		return nil, fmt.Errorf("invalid link: must start with vless://")
+	switch security := strings.ToLower(q.Get("security")); security {
+	case "", "none":
+	case "tls", "reality":
+		node.TLS = tlsFromQuery(q)
+	default:
+		return nil, fmt.Errorf("unsupported security type: %s", security)
 	}
 
-	u, err := url.Parse(vlessLink)
-	if err != nil {
-		return nil, fmt.Errorf("invalid VLESS link: %w", err)
+	if node.TLS.Reality.Enabled {
+		if node.TLS.Reality.PublicKey == "" {
+			return nil, fmt.Errorf("missing public key (pbk) for REALITY")
+		}
+		if node.TLS.ServerName == "" {
+			return nil, fmt.Errorf("missing server name (sni) for REALITY")
+		}
 	}
 
-	uuid := u.User.Username()
-	if uuid == "" {
-		return nil, fmt.Errorf("missing UUID in VLESS link")
+	if err := node.validate(); err != nil {
+		return nil, err
 	}
 
-	host := u.Hostname()
-	portStr := u.Port()
-	if portStr == "" {
-		portStr = "443"
+	return node, nil
+}
+
+// transportFromQuery reads the transport fields shared by URL-style links
+func transportFromQuery(network string, q url.Values) Transport {
+	t := Transport{
+		Type:        network,
+		Path:        q.Get("path"),
+		Host:        q.Get("host"),
+		ServiceName: q.Get("serviceName"),
+		Mode:        q.Get("mode"),
 	}
-	port, err := strconv.Atoi(portStr)
-	if err != nil {
-		return nil, fmt.Errorf("invalid port: %w", err)
+	if headerType := q.Get("headerType"); headerType != "" && headerType != "none" {
+		t.HeaderType = headerType
 	}
+	if network == "quic" {
+		t.QUICSecurity = q.Get("quicSecurity")
+		t.QUICKey = q.Get("key")
+	}
+	return t
+}
 
-	q := u.Query()
-	security := q.Get("security")
-	network := q.Get("type")
-	path := q.Get("path")
-	hostHeader := q.Get("host")
-	sni := q.Get("sni")
-
-	cfg := map[string]any{
-		"log": map[string]any{
-			"loglevel": "info",
-		},
-		"inbounds": []map[string]any{
-			{
-				"tag":      "socks-in",
-				"port":     1080,
-				"listen":   "127.0.0.1",
-				"protocol": "socks",
-				"sniffing": map[string]any{
-					"enabled":      true,
-					"destOverride": []string{"http", "tls"},
-				},
-				"settings": map[string]any{
-					"auth": "noauth",
-					"udp":  true,
-				},
-			},
-		},
-		"outbounds": []map[string]any{
-			{
-				"tag":      "proxy-out",
-				"protocol": "vless",
-				"settings": map[string]any{
-					"vnext": []map[string]any{
-						{
-							"address": host,
-							"port":    port,
-							"users": []map[string]any{
-								{
-									"id":         uuid,
-									"encryption": "none",
-									"flow":       q.Get("flow"),
-								},
-							},
-						},
-					},
-				},
-				"streamSettings": map[string]any{
-					"network":  network,
-					"security": security,
-					"tlsSettings": map[string]any{
-						"serverName": sni,
-					},
-					"wsSettings": map[string]any{
-						"path": path,
-						"headers": map[string]any{
-							"Host": hostHeader,
-						},
-					},
-				},
-			},
-		},
-	}
-
-	return cfg, nil
+// tlsFromQuery reads the TLS fields shared by URL-style links
+func tlsFromQuery(q url.Values) TLS {
+	tls := TLS{
+		Enabled:     true,
+		ServerName:  q.Get("sni"),
+		ALPN:        splitList(q.Get("alpn")),
+		Fingerprint: q.Get("fp"),
+		Insecure:    q.Get("allowInsecure") == "1" || q.Get("allowInsecure") == "true",
+		Fragment:    parseFragment(q.Get("fragment")),
+	}
+	if strings.ToLower(q.Get("security")) == "reality" {
+		tls.Reality = Reality{
+			Enabled:   true,
+			PublicKey: q.Get("pbk"),
+			ShortID:   q.Get("sid"),
+			SpiderX:   q.Get("spx"),
+		}
+	}
+	return tls
+}
+
+// parseFragment reads a "packets,length,interval" fragment parameter such as tlshello,100-200,10-20
+func parseFragment(s string) Fragment {
+	parts := strings.Split(s, ",")
+	if len(parts) != 3 || strings.TrimSpace(parts[0]) == "" {
+		return Fragment{}
+	}
+	return Fragment{
+		Packets:  strings.TrimSpace(parts[0]),
+		Length:   strings.TrimSpace(parts[1]),
+		Interval: strings.TrimSpace(parts[2]),
+	}
+}
+
+// isUUID reports whether s has the canonical 8-4-4-4-12 hex UUID form
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+				return false
+			}
+		}
+	}
+	return true
 }
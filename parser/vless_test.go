@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -257,3 +258,112 @@ func TestVLESSToV2Ray_InvalidInput(t *testing.T) {
 		})
 	}
 }
+
+func TestVLESS_Reality(t *testing.T) {
+	vlessLink := "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&sni=www.microsoft.com&fp=firefox&pbk=PUBLICKEY&sid=6ba85179&spx=%2Fpath&type=tcp&flow=xtls-rprx-vision#Reality"
+
+	t.Run("sing-box", func(t *testing.T) {
+		cfg, err := VLESSToSingBox(vlessLink)
+		if err != nil {
+			t.Fatalf("VLESSToSingBox() unexpected error: %v", err)
+		}
+
+		proxy := cfg["outbounds"].([]map[string]any)[0]
+		tls, ok := proxy["tls"].(map[string]any)
+		if !ok {
+			t.Fatal("VLESSToSingBox() tls is not a map")
+		}
+		if tls["enabled"] != true || tls["server_name"] != "www.microsoft.com" {
+			t.Errorf("VLESSToSingBox() tls = %v, want enabled with server_name www.microsoft.com", tls)
+		}
+
+		reality, ok := tls["reality"].(map[string]any)
+		if !ok {
+			t.Fatal("VLESSToSingBox() reality is not a map")
+		}
+		if reality["enabled"] != true || reality["public_key"] != "PUBLICKEY" || reality["short_id"] != "6ba85179" {
+			t.Errorf("VLESSToSingBox() reality = %v, want PUBLICKEY/6ba85179", reality)
+		}
+
+		utls, ok := tls["utls"].(map[string]any)
+		if !ok || utls["fingerprint"] != "firefox" {
+			t.Errorf("VLESSToSingBox() utls = %v, want fingerprint firefox", tls["utls"])
+		}
+	})
+
+	t.Run("v2ray", func(t *testing.T) {
+		if _, err := VLESSToV2Ray(vlessLink); err == nil || !strings.Contains(err.Error(), "REALITY") {
+			t.Errorf("VLESSToV2Ray() error = %v, want REALITY rejected", err)
+		}
+		if node, _ := Parse(vlessLink); SupportsNode(node, TargetV2Ray) {
+			t.Error("SupportsNode(v2ray) = true for a REALITY node")
+		}
+	})
+
+	t.Run("xray", func(t *testing.T) {
+		cfg, err := LinkToXray(vlessLink)
+		if err != nil {
+			t.Fatalf("LinkToXray() unexpected error: %v", err)
+		}
+
+		proxy := cfg["outbounds"].([]map[string]any)[0]
+		stream := proxy["streamSettings"].(map[string]any)
+		if stream["security"] != "reality" {
+			t.Errorf("LinkToXray() security = %v, want reality", stream["security"])
+		}
+		if _, exists := stream["tlsSettings"]; exists {
+			t.Error("LinkToXray() should not emit tlsSettings for REALITY")
+		}
+
+		reality, ok := stream["realitySettings"].(map[string]any)
+		if !ok {
+			t.Fatal("LinkToXray() realitySettings is not a map")
+		}
+		expected := map[string]any{
+			"serverName":  "www.microsoft.com",
+			"fingerprint": "firefox",
+			"publicKey":   "PUBLICKEY",
+			"shortId":     "6ba85179",
+			"spiderX":     "/path",
+		}
+		for key, want := range expected {
+			if reality[key] != want {
+				t.Errorf("LinkToXray() realitySettings.%s = %v, want %v", key, reality[key], want)
+			}
+		}
+	})
+}
+
+func TestVLESS_RealityDefaultFingerprint(t *testing.T) {
+	cfg, err := VLESSToSingBox("vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&sni=www.microsoft.com&pbk=PUBLICKEY")
+	if err != nil {
+		t.Fatalf("VLESSToSingBox() unexpected error: %v", err)
+	}
+
+	tls := cfg["outbounds"].([]map[string]any)[0]["tls"].(map[string]any)
+	utls, ok := tls["utls"].(map[string]any)
+	if !ok || utls["fingerprint"] != "chrome" {
+		t.Errorf("VLESSToSingBox() utls = %v, want default fingerprint chrome", tls["utls"])
+	}
+}
+
+func TestVLESS_RealityInvalidInput(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"missing pbk", "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&sni=www.microsoft.com&sid=6ba85179"},
+		{"missing sni", "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&pbk=PUBLICKEY"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := VLESSToSingBox(tt.input); err == nil {
+				t.Error("VLESSToSingBox() expected error but got none")
+			}
+			if _, err := VLESSToV2Ray(tt.input); err == nil {
+				t.Error("VLESSToV2Ray() expected error but got none")
+			}
+		})
+	}
+}
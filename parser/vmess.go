@@ -1,7 +1,7 @@
 package parser
 
 import (
-	"encoding/base64"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -10,161 +10,96 @@ import (
 
 // VMessToSingBox converts a vmess:// link into sing-box JSON config
 func VMessToSingBox(vmessLink string) (map[string]any, error) {
-	if !strings.HasPrefix(vmessLink, "vmess://") {
-		return nil, fmt.Errorf("invalid link: must start with vmess://")
-	}
+	return parseAndRender(vmessLink, "vmess://", TargetSingBox)
+}
 
+// VMessToV2ray converts a vmess:// link into V2Ray JSON config
+func VMessToV2ray(vmessLink string) (map[string]any, error) {
+	return parseAndRender(vmessLink, "vmess://", TargetV2Ray)
+}
+
+// parseVMess decodes the base64 JSON payload of a vmess:// link
+func parseVMess(vmessLink string) (*ProxyNode, error) {
 	raw := strings.TrimPrefix(vmessLink, "vmess://")
-	decoded, err := base64.StdEncoding.DecodeString(raw)
+	decoded, err := decodeBase64String(raw)
 	if err != nil {
 		return nil, fmt.Errorf("base64 decode error: %w", err)
 	}
 
-	var v map[string]string
-	if err := json.Unmarshal(decoded, &v); err != nil {
+	v, err := decodeVMessJSON(decoded)
+	if err != nil {
 		return nil, fmt.Errorf("vmess JSON parse error: %w", err)
 	}
 
-	cfg := map[string]any{
-		"log": map[string]any{
-			"level": "info",
-		},
-		"inbounds": []map[string]any{
-			{
-				"type":        "socks",
-				"tag":         "socks-in",
-				"listen":      "127.0.0.1",
-				"listen_port": 1080,
-				"sniff":       true,
-			},
-		},
-		"outbounds": []map[string]any{
-			{
-				"type":        "vmess",
-				"tag":         "proxy",
-				"server":      v["add"],
-				"server_port": atoiSafe(v["port"]),
-				"uuid":        v["id"],
-				"security":    v["scy"],
-				"transport": map[string]any{
-					"type": v["net"],
-					"path": v["path"],
-					"headers": map[string]any{
-						"Host": v["host"],
-					},
-				},
-				"tls": map[string]any{
-					"enabled":     v["tls"] == "tls",
-					"server_name": v["host"],
-					"utls": map[string]any{
-						"enabled":     true,
-						"fingerprint": v["fp"],
-					},
-				},
-			},
-			{
-				"type": "direct",
-				"tag":  "direct",
-			},
+	node := &ProxyNode{
+		Protocol: "vmess",
+		Remark:   v["ps"],
+		Server:   v["add"],
+		Port:     atoiSafe(v["port"]),
+		UUID:     v["id"],
+		AlterID:  atoiSafe(v["aid"]),
+		Security: v["scy"],
+		Transport: Transport{
+			Type: strings.ToLower(v["net"]),
+			Path: v["path"],
+			Host: v["host"],
 		},
 	}
-
-	return cfg, nil
-}
-
-// VMessToV2ray converts a vmess:// link into V2Ray JSON config
-func VMessToV2ray(vmessLink string) (map[string]any, error) {
-	if !strings.HasPrefix(vmessLink, "vmess://") {
-		return nil, fmt.Errorf("invalid link: must start with vmess://")
+	if node.Security == "" {
+		node.Security = "auto"
+	}
+	if node.Transport.Type == "" {
+		node.Transport.Type = "tcp"
+	}
+	if node.Transport.Type == "quic" {
+		// vmess links carry the quic encryption in host and its key in path
+		node.Transport.QUICSecurity, node.Transport.QUICKey = v["host"], v["path"]
+		node.Transport.Host, node.Transport.Path = "", ""
+	}
+	if node.Transport.Type == "grpc" {
+		node.Transport.ServiceName = v["path"]
+	} else if headerType := v["type"]; headerType != "" && headerType != "none" {
+		node.Transport.HeaderType = headerType
 	}
 
-	raw := strings.TrimPrefix(vmessLink, "vmess://")
-	decoded, err := base64.StdEncoding.DecodeString(raw)
-	if err != nil {
-		return nil, fmt.Errorf("base64 decode error: %w", err)
+	if v["tls"] == "tls" {
+		node.TLS = TLS{
+			Enabled:     true,
+			ServerName:  v["sni"],
+			ALPN:        splitList(v["alpn"]),
+			Fingerprint: v["fp"],
+		}
+		if node.TLS.ServerName == "" {
+			node.TLS.ServerName = v["host"]
+		}
 	}
 
-	var v map[string]string
-	if err := json.Unmarshal(decoded, &v); err != nil {
-		return nil, fmt.Errorf("vmess JSON parse error: %w", err)
+	if node.UUID == "" {
+		return nil, fmt.Errorf("missing id in vmess link")
+	}
+	if err := node.validate(); err != nil {
+		return nil, err
 	}
 
-	cfg := map[string]any{
-		"log": map[string]any{"loglevel": "none"},
-		"dns": map[string]any{
-			"servers": []string{"1.1.1.1", "9.9.9.10", "8.8.8.8"},
-		},
-		"inbounds": []map[string]any{
-			{
-				"tag":      "socks",
-				"port":     1080,
-				"listen":   "127.0.0.1",
-				"protocol": "socks",
-				"sniffing": map[string]any{
-					"enabled":      true,
-					"destOverride": []string{"http", "tls"},
-					"routeOnly":    false,
-				},
-				"settings": map[string]any{
-					"auth":             "noauth",
-					"udp":              true,
-					"allowTransparent": false,
-				},
-			},
-		},
-		"outbounds": []map[string]any{
-			{
-				"tag":      "proxy",
-				"protocol": "vmess",
-				"settings": map[string]any{
-					"vnext": []map[string]any{
-						{
-							"address": v["add"],
-							"port":    atoiSafe(v["port"]),
-							"users": []map[string]any{
-								{
-									"id":       v["id"],
-									"alterId":  atoiSafe(v["aid"]),
-									"email":    "t@t.tt",
-									"security": v["scy"],
-								},
-							},
-						},
-					},
-				},
-				"streamSettings": map[string]any{
-					"network":  v["net"],
-					"security": v["tls"],
-					"tlsSettings": map[string]any{
-						"allowInsecure": false,
-						"serverName":    v["host"],
-						"fingerprint":   v["fp"],
-					},
-					"wsSettings": map[string]any{
-						"path": v["path"],
-						"host": v["host"],
-						"headers": map[string]any{
-							"Host": v["host"],
-						},
-					},
-				},
-				"mux": map[string]any{
-					"enabled":     false,
-					"concurrency": -1,
-				},
-			},
-			{
-				"tag":      "direct",
-				"protocol": "freedom",
-				"settings": map[string]any{
-					"domainStrategy": "UseIP",
-					"userLevel":      0,
-				},
-			},
-		},
+	return node, nil
+}
+
+// decodeVMessJSON unmarshals the vmess payload, tolerating numeric fields
+func decodeVMessJSON(data []byte) (map[string]string, error) {
+	var raw map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return nil, err
 	}
 
-	return cfg, nil
+	v := make(map[string]string, len(raw))
+	for key, value := range raw {
+		if value != nil {
+			v[key] = fmt.Sprint(value)
+		}
+	}
+	return v, nil
 }
 
 // atoiSafe safely converts string to int
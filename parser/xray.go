@@ -0,0 +1,89 @@
+package parser
+
+import "fmt"
+
+// xrayFragmentTag is the freedom outbound that fragments the TLS ClientHello
+const xrayFragmentTag = "fragment"
+
+// LinkToXray converts any supported share link into an Xray-core JSON config
+func LinkToXray(link string) (map[string]any, error) {
+	node, err := Parse(link)
+	if err != nil {
+		return nil, err
+	}
+	return Render(node, TargetXray)
+}
+
+// renderXray builds an Xray-core JSON config. It shares the V2Ray layout, where
+// the XTLS Vision flow checked by validateFlow is set on the VLESS user, and
+// adds what only Xray understands: xhttp and TLS fragmentation.
+func renderXray(nodes []*ProxyNode, opts Options) (map[string]any, error) {
+	cfg, err := renderV2RayConfig(nodes, TargetXray, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	outbounds := cfg["outbounds"].([]map[string]any)
+	for i, node := range nodes {
+		if !node.TLS.Enabled || node.TLS.Fragment.Packets == "" {
+			continue
+		}
+		// Later hops already dial through the hop before them, inside its tunnel
+		if opts.chained() && i > 0 {
+			continue
+		}
+		// Group members may fragment differently, each gets its own freedom outbound
+		tag := xrayFragmentTag
+		if opts.grouped() {
+			tag = fmt.Sprintf("%s-%d", xrayFragmentTag, i+1)
+		}
+		stream := outbounds[i]["streamSettings"].(map[string]any)
+		stream["sockopt"] = map[string]any{
+			"dialerProxy": tag,
+		}
+		fragment := xrayFragmentOutbound(node.TLS.Fragment)
+		fragment["tag"] = tag
+		outbounds = append(outbounds, fragment)
+	}
+	cfg["outbounds"] = outbounds
+
+	return cfg, nil
+}
+
+// xrayXHTTPSettings renders xhttpSettings, the successor of splithttp
+func xrayXHTTPSettings(t Transport) map[string]any {
+	mode := t.Mode
+	if mode == "" {
+		mode = "auto"
+	}
+
+	settings := map[string]any{
+		"path": t.Path,
+		"mode": mode,
+	}
+	if t.Host != "" {
+		settings["host"] = t.Host
+	}
+	return settings
+}
+
+// xrayFragmentOutbound renders the freedom outbound the proxy dials through to split the ClientHello
+func xrayFragmentOutbound(f Fragment) map[string]any {
+	fragment := map[string]any{
+		"packets": f.Packets,
+	}
+	if f.Length != "" {
+		fragment["length"] = f.Length
+	}
+	if f.Interval != "" {
+		fragment["interval"] = f.Interval
+	}
+
+	return map[string]any{
+		"tag":      xrayFragmentTag,
+		"protocol": "freedom",
+		"settings": map[string]any{
+			"fragment": fragment,
+		},
+	}
+}
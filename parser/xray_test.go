@@ -0,0 +1,164 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRender_XrayVisionReality(t *testing.T) {
+	link := "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&sni=www.microsoft.com&pbk=PUBLICKEY&sid=6ba85179&type=tcp&flow=xtls-rprx-vision#Vision"
+
+	cfg, err := LinkToXray(link)
+	if err != nil {
+		t.Fatalf("LinkToXray() unexpected error: %v", err)
+	}
+
+	proxy := cfg["outbounds"].([]map[string]any)[0]
+	user := proxy["settings"].(map[string]any)["vnext"].([]map[string]any)[0]["users"].([]map[string]any)[0]
+	if user["flow"] != "xtls-rprx-vision" {
+		t.Errorf("LinkToXray() flow = %v, want xtls-rprx-vision", user["flow"])
+	}
+
+	stream := proxy["streamSettings"].(map[string]any)
+	reality, ok := stream["realitySettings"].(map[string]any)
+	if stream["security"] != "reality" || !ok {
+		t.Fatalf("LinkToXray() streamSettings = %v, want reality", stream)
+	}
+	if reality["publicKey"] != "PUBLICKEY" || reality["fingerprint"] != defaultFingerprint {
+		t.Errorf("LinkToXray() realitySettings = %v", reality)
+	}
+}
+
+func TestRender_VisionFlow(t *testing.T) {
+	const base = "vless://12345678-1234-1234-1234-123456789012@example.com:443?flow=xtls-rprx-vision&"
+	const reality = "security=reality&sni=www.microsoft.com&pbk=PUBLICKEY"
+
+	tests := []struct {
+		name    string
+		query   string
+		mux     bool
+		target  Target
+		wantErr string
+	}{
+		{name: "xray reality", query: reality, target: TargetXray},
+		{name: "xray tls tcp", query: "security=tls&sni=example.com&type=tcp", target: TargetXray},
+		{name: "v2ray", query: reality, target: TargetV2Ray, wantErr: "V2Ray does not support"},
+		{name: "xray websocket", query: "security=tls&sni=example.com&type=ws&path=%2Fws", target: TargetXray, wantErr: "tcp transport"},
+		{name: "xray http header", query: reality + "&headerType=http", target: TargetXray, wantErr: "http header"},
+		{name: "xray without tls", query: "security=none", target: TargetXray, wantErr: "TLS or REALITY"},
+		{name: "xray mux", query: reality, mux: true, target: TargetXray, wantErr: "mux"},
+		{name: "sing-box", query: reality, target: TargetSingBox},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(base + tt.query)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+			node.Mux.Enabled = tt.mux
+
+			_, err = Render(node, tt.target)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Render() unexpected error: %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Render() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+			if got := SupportsNode(node, tt.target); got != (tt.wantErr == "") {
+				t.Errorf("SupportsNode() = %v, want %v", got, tt.wantErr == "")
+			}
+		})
+	}
+}
+
+func TestRender_XHTTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		wantMode string
+	}{
+		{"xhttp", "type=xhttp&path=%2Fx&host=cdn.example.com&mode=packet-up", "packet-up"},
+		{"splithttp alias", "type=splithttp&path=%2Fx&host=cdn.example.com", "auto"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse("vless://12345678-1234-1234-1234-123456789012@example.com:443?security=tls&sni=example.com&" + tt.query)
+			if err != nil {
+				t.Fatalf("Parse() unexpected error: %v", err)
+			}
+
+			cfg, err := Render(node, TargetXray)
+			if err != nil {
+				t.Fatalf("Render(xray) unexpected error: %v", err)
+			}
+			stream := cfg["outbounds"].([]map[string]any)[0]["streamSettings"].(map[string]any)
+			if stream["network"] != "xhttp" {
+				t.Errorf("Render(xray) network = %v, want xhttp", stream["network"])
+			}
+			settings, ok := stream["xhttpSettings"].(map[string]any)
+			if !ok {
+				t.Fatal("Render(xray) xhttpSettings is not a map")
+			}
+			if settings["path"] != "/x" || settings["host"] != "cdn.example.com" || settings["mode"] != tt.wantMode {
+				t.Errorf("Render(xray) xhttpSettings = %v", settings)
+			}
+
+			if !SupportsNode(node, TargetXray) {
+				t.Errorf("SupportsNode(xray) = false for %s transport", node.Transport.Type)
+			}
+			for _, target := range []Target{TargetSingBox, TargetV2Ray, TargetMihomo} {
+				if _, err := Render(node, target); err == nil {
+					t.Errorf("Render(%s) expected error for %s transport", target, node.Transport.Type)
+				}
+				if SupportsNode(node, target) {
+					t.Errorf("SupportsNode(%s) = true for %s transport", target, node.Transport.Type)
+				}
+			}
+		})
+	}
+}
+
+func TestRender_XrayFragment(t *testing.T) {
+	link := "trojan://secret@example.com:443?sni=example.com&fragment=tlshello%2C100-200%2C10-20#Fragment"
+
+	cfg, err := LinkToXray(link)
+	if err != nil {
+		t.Fatalf("LinkToXray() unexpected error: %v", err)
+	}
+
+	outbounds := cfg["outbounds"].([]map[string]any)
+	if len(outbounds) != 3 {
+		t.Fatalf("LinkToXray() returned %d outbounds, want proxy, direct and fragment", len(outbounds))
+	}
+
+	stream := outbounds[0]["streamSettings"].(map[string]any)
+	sockopt, ok := stream["sockopt"].(map[string]any)
+	if !ok || sockopt["dialerProxy"] != xrayFragmentTag {
+		t.Errorf("LinkToXray() sockopt = %v, want dialerProxy %s", stream["sockopt"], xrayFragmentTag)
+	}
+
+	fragment := outbounds[2]
+	settings := fragment["settings"].(map[string]any)["fragment"].(map[string]any)
+	if fragment["tag"] != xrayFragmentTag || settings["packets"] != "tlshello" || settings["length"] != "100-200" || settings["interval"] != "10-20" {
+		t.Errorf("LinkToXray() fragment outbound = %v", fragment)
+	}
+
+	// V2Ray has no fragment support, so the setting is left out
+	v2ray, err := TrojanToV2Ray(link)
+	if err != nil {
+		t.Fatalf("TrojanToV2Ray() unexpected error: %v", err)
+	}
+	if n := len(v2ray["outbounds"].([]map[string]any)); n != 2 {
+		t.Errorf("TrojanToV2Ray() returned %d outbounds, want 2", n)
+	}
+}
+
+func TestRender_XrayUnsupportedProtocol(t *testing.T) {
+	_, err := LinkToXray("hysteria2://auth@example.com:443#HY2")
+	if err == nil || !strings.Contains(err.Error(), "Xray") {
+		t.Errorf("LinkToXray(hysteria2) error = %v, want Xray not supported", err)
+	}
+}
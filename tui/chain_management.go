@@ -0,0 +1,238 @@
+package tui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// protocolChain is the Protocol of a saved configuration that chains other configurations
+const protocolChain = "chain"
+
+const chainName = "Name"
+
+// addChain saves a configuration that reaches the destination through the hops
+// in order, the first hop being dialed directly
+func (tui *TUI) addChain(name string, hops []string) (Config, error) {
+	if len(hops) < 2 {
+		return Config{}, fmt.Errorf("select at least two configurations")
+	}
+
+	var protocols []string
+	for i, id := range hops {
+		index := tui.findConfig(id)
+		if index < 0 {
+			return Config{}, fmt.Errorf("configuration %s not found", id)
+		}
+		hop := tui.configs.Configurations[index]
+		if hop.Protocol == protocolChain {
+			return Config{}, fmt.Errorf("chain %s cannot be a hop", hop.Name)
+		}
+		if slices.Contains(hops[:i], id) {
+			return Config{}, fmt.Errorf("%s is used twice", hop.Name)
+		}
+		protocols = append(protocols, hop.Protocol)
+	}
+	if len(clientButtons(protocols...)) == 1 {
+		return Config{}, fmt.Errorf("no client supports all of %s", strings.Join(protocols, ", "))
+	}
+
+	chain := Config{
+		ID:        tui.nextConfigID(),
+		Name:      strings.TrimSpace(name),
+		Protocol:  protocolChain,
+		Chain:     slices.Clone(hops),
+		CreatedAt: time.Now().Format(time.RFC3339),
+		LastUsed:  time.Now().Format(time.RFC3339),
+	}
+	if chain.Name == "" {
+		chain.Name = fmt.Sprintf("Chain %d", len(tui.configs.Configurations)+1)
+	}
+	tui.configs.Configurations = append(tui.configs.Configurations, chain)
+
+	return chain, tui.saveConfigsToFile()
+}
+
+// chainHops returns the configurations a chain goes through, in order. Unlike
+// a group a chain cannot skip a deleted hop, as that would change the route.
+func (tui *TUI) chainHops(chain Config) ([]Config, error) {
+	var hops []Config
+	for i, id := range chain.Chain {
+		index := tui.findConfig(id)
+		if index < 0 {
+			return nil, fmt.Errorf("hop %d of chain %s was deleted", i+1, chain.Name)
+		}
+		hops = append(hops, tui.configs.Configurations[index])
+	}
+	return hops, nil
+}
+
+// configProtocols returns the protocols a client must support to run the
+// configuration: its own, or those of every hop of a chain
+func (tui *TUI) configProtocols(config Config) []string {
+	if config.Protocol != protocolChain {
+		return []string{config.Protocol}
+	}
+
+	hops, err := tui.chainHops(config)
+	if err != nil {
+		return nil
+	}
+	var protocols []string
+	for _, hop := range hops {
+		protocols = append(protocols, hop.Protocol)
+	}
+	return protocols
+}
+
+// configNodes parses the servers of a configuration, the hops for a chain.
+// Links that do not parse are left out.
+func (tui *TUI) configNodes(config Config) []*parser.ProxyNode {
+	configs := []Config{config}
+	if config.Protocol == protocolChain {
+		configs, _ = tui.chainHops(config)
+	}
+	var nodes []*parser.ProxyNode
+	for _, c := range configs {
+		if node, err := parser.Parse(c.Link); err == nil {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// renderChain renders the hops of a chain for target, each dialed through the one before it
+func (tui *TUI) renderChain(chain Config, target parser.Target, opts parser.Options) (map[string]any, error) {
+	hops, err := tui.chainHops(chain)
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*parser.ProxyNode
+	for _, hop := range hops {
+		node, err := parser.Parse(hop.Link)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", hop.Name, err)
+		}
+		nodes = append(nodes, node)
+	}
+	return parser.RenderChain(nodes, target, opts)
+}
+
+// chainRoute describes the path of a chain's traffic by hop name
+func (tui *TUI) chainRoute(chain Config) string {
+	names := []string{"You"}
+	for _, id := range chain.Chain {
+		if index := tui.findConfig(id); index >= 0 {
+			names = append(names, tui.configs.Configurations[index].Name)
+		} else {
+			names = append(names, "(deleted)")
+		}
+	}
+	return strings.Join(append(names, "Internet"), " -> ")
+}
+
+// showChains switches to the chain creation screen
+func (tui *TUI) showChains() {
+	tui.chainPicks = nil
+	tui.refreshChainHopList()
+	tui.app.SetRoot(tui.chainView, true)
+	tui.app.SetFocus(tui.chainHopList)
+}
+
+// createChainView builds the chain creation screen
+func (tui *TUI) createChainView() *tview.Flex {
+	tui.chainHopList = tview.NewList()
+	tui.chainHopList.ShowSecondaryText(false)
+	tui.chainHopList.SetBorder(true)
+	tui.chainHopList.SetTitle(" Hops (Enter adds or removes, in order) ")
+
+	tui.chainStatus = tview.NewTextView()
+	tui.chainStatus.SetText("Pick the relay first and the server reaching the destination last")
+	tui.chainStatus.SetTextAlign(tview.AlignCenter)
+	tui.chainStatus.SetBorder(true)
+	tui.chainStatus.SetTitle(" Status ")
+
+	tui.chainForm = tview.NewForm().
+		AddInputField(chainName, "", 30, nil, nil)
+
+	tui.chainForm.
+		AddButton("Save Chain", func() {
+			tui.addChainFromForm()
+		}).
+		AddButton("Clear", func() {
+			tui.chainPicks = nil
+			tui.refreshChainHopList()
+		}).
+		AddButton("Back", func() {
+			tui.app.SetRoot(tui.mainFlex, true)
+		})
+	tui.chainForm.SetBorder(true)
+	tui.chainForm.SetTitle(" New Chain ")
+
+	content := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(tui.chainHopList, 0, 1, true).
+		AddItem(tui.chainForm, 0, 1, false)
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(content, 0, 1, true).
+		AddItem(tui.chainStatus, 3, 0, false)
+}
+
+// toggleChainHop appends the configuration at index to the hops, or removes it
+func (tui *TUI) toggleChainHop(index int) {
+	if index < 0 || index >= len(tui.configs.Configurations) {
+		return
+	}
+	id := tui.configs.Configurations[index].ID
+	if i := slices.Index(tui.chainPicks, id); i >= 0 {
+		tui.chainPicks = slices.Delete(tui.chainPicks, i, i+1)
+	} else {
+		tui.chainPicks = append(tui.chainPicks, id)
+	}
+	tui.refreshChainHopList()
+	tui.chainHopList.SetCurrentItem(index)
+}
+
+// addChainFromForm saves a chain over the picked hops
+func (tui *TUI) addChainFromForm() {
+	nameInput := tui.chainForm.GetFormItemByLabel(chainName).(*tview.InputField)
+
+	chain, err := tui.addChain(nameInput.GetText(), tui.chainPicks)
+	if err != nil {
+		tui.setChainStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.chainPicks = nil
+	nameInput.SetText("")
+	tui.refreshConfigList()
+	tui.refreshChainHopList()
+	tui.setChainStatus(fmt.Sprintf("Chain '%s' saved: %s", chain.Name, tui.chainRoute(chain)), tcell.ColorGreen)
+}
+
+// refreshChainHopList redraws the configurations with their hop positions
+func (tui *TUI) refreshChainHopList() {
+	tui.chainHopList.Clear()
+	for i, config := range tui.configs.Configurations {
+		index := i
+		mark := "[ ]"
+		if position := slices.Index(tui.chainPicks, config.ID); position >= 0 {
+			mark = fmt.Sprintf("[%d]", position+1)
+		}
+		tui.chainHopList.AddItem(tview.Escape(fmt.Sprintf("%s %s (%s)", mark, config.Name, config.Protocol)), "", 0, func() {
+			tui.toggleChainHop(index)
+		})
+	}
+}
+
+// setChainStatus shows a message on the chain screen
+func (tui *TUI) setChainStatus(message string, color tcell.Color) {
+	tui.chainStatus.SetText(message).SetTextColor(color)
+}
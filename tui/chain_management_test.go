@@ -0,0 +1,142 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"tui_proxy_client/parser"
+)
+
+func TestAddChain(t *testing.T) {
+	tests := []struct {
+		name        string
+		hops        []string
+		expectError bool
+	}{
+		{name: "two hops", hops: []string{"1", "2"}},
+		{name: "hysteria2 exit", hops: []string{"2", "3"}},
+		{name: "single hop", hops: []string{"1"}, expectError: true},
+		{name: "missing hop", hops: []string{"1", "9"}, expectError: true},
+		{name: "repeated hop", hops: []string{"1", "2", "1"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := groupTestTUI(t)
+			chain, err := tui.addChain("", tt.hops)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("addChain() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				if len(tui.configs.Configurations) != 3 {
+					t.Errorf("failed addChain() stored %v", tui.configs.Configurations[3:])
+				}
+				return
+			}
+			if chain.ID != "4" || chain.Name != "Chain 4" || chain.Protocol != protocolChain || !reflect.DeepEqual(chain.Chain, tt.hops) {
+				t.Errorf("addChain() = %+v", chain)
+			}
+		})
+	}
+
+	t.Run("chain as hop", func(t *testing.T) {
+		tui := groupTestTUI(t)
+		if _, err := tui.addChain("Inner", []string{"1", "2"}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tui.addChain("Outer", []string{"4", "3"}); err == nil {
+			t.Error("addChain() should reject a chain as a hop")
+		}
+	})
+}
+
+func TestRenderChainConfig(t *testing.T) {
+	tui := groupTestTUI(t)
+	chain, err := tui.addChain("Relay", []string{"2", "1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := tui.renderConfig(chain, parser.TargetSingBox)
+	if err != nil {
+		t.Fatalf("renderConfig() unexpected error: %v", err)
+	}
+	outbounds := rendered["outbounds"].([]map[string]any)
+	if outbounds[0]["type"] != "shadowsocks" || outbounds[0]["tag"] != "proxy-1" {
+		t.Errorf("entry hop = %v, want the shadowsocks server", outbounds[0])
+	}
+	if outbounds[1]["type"] != "vmess" || outbounds[1]["tag"] != parser.OutboundProxy || outbounds[1]["detour"] != "proxy-1" {
+		t.Errorf("exit hop = %v, want vmess dialed through proxy-1", outbounds[1])
+	}
+
+	if got := tui.chainRoute(chain); got != "You -> SS -> VMess -> Internet" {
+		t.Errorf("chainRoute() = %q", got)
+	}
+	if _, err := configShareLink(chain); err == nil {
+		t.Error("configShareLink() should fail for a chain")
+	}
+
+	tui.configs.Configurations = tui.configs.Configurations[1:]
+	if _, err := tui.renderConfig(chain, parser.TargetSingBox); err == nil || !strings.Contains(err.Error(), "deleted") {
+		t.Errorf("renderConfig() error = %v, want a deleted hop error", err)
+	}
+}
+
+func TestChainClientButtons(t *testing.T) {
+	tui := groupTestTUI(t)
+	plain, err := tui.addChain("Plain", []string{"1", "2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	udp, err := tui.addChain("UDP exit", []string{"1", "3"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{name: "plain hops", config: plain, want: []string{"V2Ray", "Xray", "SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{name: "hysteria2 hop", config: udp, want: []string{"SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{name: "single config", config: tui.configs.Configurations[2], want: []string{"SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientButtons(tui.configProtocols(tt.config)...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("clientButtons() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddChainFromForm(t *testing.T) {
+	tui := groupTestTUI(t)
+	tui.showChains()
+
+	// Picked order is the hop order, toggling again removes a hop
+	tui.toggleChainHop(1)
+	tui.toggleChainHop(2)
+	tui.toggleChainHop(0)
+	tui.toggleChainHop(2)
+	if !reflect.DeepEqual(tui.chainPicks, []string{"2", "1"}) {
+		t.Fatalf("picked hops = %v, want [2 1]", tui.chainPicks)
+	}
+	if main, _ := tui.chainHopList.GetItemText(0); !strings.HasPrefix(main, "[2[] VMess") {
+		t.Errorf("hop list item = %q, want the hop position", main)
+	}
+
+	tui.addChainFromForm()
+	if len(tui.configs.Configurations) != 4 {
+		t.Fatalf("got %d configurations, want the chain added", len(tui.configs.Configurations))
+	}
+	if chain := tui.configs.Configurations[3]; !reflect.DeepEqual(chain.Chain, []string{"2", "1"}) {
+		t.Errorf("chain hops = %v", chain.Chain)
+	}
+	if tui.configList.GetItemCount() != 4 || tui.chainPicks != nil {
+		t.Error("the config list should show the chain and the picks should be cleared")
+	}
+}
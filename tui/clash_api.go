@@ -0,0 +1,152 @@
+package tui
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// clashAPI talks to the Clash-compatible API of sing-box and mihomo
+type clashAPI struct {
+	baseURL string
+	secret  string
+	client  *http.Client
+}
+
+// clashConnection is an entry of the /connections list
+type clashConnection struct {
+	ID       string `json:"id"`
+	Metadata struct {
+		Network         string `json:"network"`
+		Host            string `json:"host"`
+		DestinationIP   string `json:"destinationIP"`
+		DestinationPort string `json:"destinationPort"`
+	} `json:"metadata"`
+	Upload      int64     `json:"upload"`
+	Download    int64     `json:"download"`
+	Start       time.Time `json:"start"`
+	Chains      []string  `json:"chains"` // outbounds from the one carrying the connection outwards
+	Rule        string    `json:"rule"`
+	RulePayload string    `json:"rulePayload"`
+}
+
+// clashConnections is the /connections response
+type clashConnections struct {
+	UploadTotal   int64             `json:"uploadTotal"`
+	DownloadTotal int64             `json:"downloadTotal"`
+	Connections   []clashConnection `json:"connections"`
+}
+
+// newClashAPI returns a client for the API listening on listen; an unspecified
+// listen address is reached through the loopback address
+func newClashAPI(listen, secret string) (clashAPI, error) {
+	host, port, err := net.SplitHostPort(listen)
+	if err != nil {
+		return clashAPI{}, err
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return clashAPI{
+		baseURL: "http://" + net.JoinHostPort(host, port),
+		secret:  secret,
+		client:  &http.Client{},
+	}, nil
+}
+
+// newAPISecret returns a random bearer token for the API of this session's clients
+func newAPISecret() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+// do sends an authenticated request and fails on anything but a 2xx status
+func (c clashAPI) do(ctx context.Context, method, path string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.secret != "" {
+		req.Header.Set("Authorization", "Bearer "+c.secret)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("clash API: HTTP %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// Connections lists the live connections and the traffic totals
+func (c clashAPI) Connections(ctx context.Context) (clashConnections, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/connections")
+	if err != nil {
+		return clashConnections{}, err
+	}
+	defer resp.Body.Close()
+
+	var conns clashConnections
+	if err := json.NewDecoder(resp.Body).Decode(&conns); err != nil {
+		return clashConnections{}, fmt.Errorf("clash API: %w", err)
+	}
+	return conns, nil
+}
+
+// CloseConnection closes a live connection by ID
+func (c clashAPI) CloseConnection(ctx context.Context, id string) error {
+	resp, err := c.do(ctx, http.MethodDelete, "/connections/"+url.PathEscape(id))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// Query fetches the upload and download totals
+func (c clashAPI) Query(ctx context.Context) (trafficCounters, error) {
+	conns, err := c.Connections(ctx)
+	if err != nil {
+		return trafficCounters{}, err
+	}
+	return trafficCounters{Up: conns.UploadTotal, Down: conns.DownloadTotal}, nil
+}
+
+// destination returns the host, or the IP when the host is unknown, with the port
+func (c clashConnection) destination() string {
+	host := c.Metadata.Host
+	if host == "" {
+		host = c.Metadata.DestinationIP
+	}
+	if c.Metadata.DestinationPort == "" {
+		return host
+	}
+	return net.JoinHostPort(host, c.Metadata.DestinationPort)
+}
+
+// rule returns the matched rule with its payload
+func (c clashConnection) rule() string {
+	if c.RulePayload == "" {
+		return c.Rule
+	}
+	return fmt.Sprintf("%s(%s)", c.Rule, c.RulePayload)
+}
+
+// outbound returns the outbounds the connection passes, outermost group first
+func (c clashConnection) outbound() string {
+	chains := make([]string, len(c.Chains))
+	for i, name := range c.Chains {
+		chains[len(c.Chains)-1-i] = name
+	}
+	return strings.Join(chains, " > ")
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -20,26 +21,16 @@ func (tui *TUI) addConfig() {
 		return
 	}
 
-	// Detect protocol and parse accordingly
-	var config interface{}
-	var protocol string
-	var err error
-
-	switch {
-	case strings.HasPrefix(proxyLink, "vmess://"):
-		protocol = "vmess"
-		config, err = parser.VMessToSingBox(proxyLink)
-	case strings.HasPrefix(proxyLink, "ss://"):
-		protocol = "shadowsocks"
-		config, err = parser.SSToSingBox(proxyLink)
-	case strings.HasPrefix(proxyLink, "vless://"):
-		protocol = "vless"
-		config, err = parser.VLESSToSingBox(proxyLink)
-	default:
-		tui.updateStatus("Error: Invalid proxy link format. Must start with 'vmess://', 'ss://', or 'vless://'", tcell.ColorRed)
+	if isMultiLinkInput(proxyLink) {
+		tui.runImport(proxyLink)
 		return
 	}
 
+	protocol, config, err := parseLinkForSingBox(proxyLink)
+	if errors.Is(err, errUnsupportedLink) {
+		tui.updateStatus("Error: Invalid proxy link format. Must start with 'vmess://', 'ss://', 'vless://', 'trojan://', 'hysteria2://', or 'tuic://'", tcell.ColorRed)
+		return
+	}
 	if err != nil {
 		tui.updateStatus(fmt.Sprintf("Error parsing %s: %v", protocol, err), tcell.ColorRed)
 		return
@@ -55,7 +46,7 @@ func (tui *TUI) addConfig() {
 	configName := fmt.Sprintf("Config %d", len(tui.configs.Configurations)+1)
 
 	newConfig := Config{
-		ID:        fmt.Sprintf("%d", len(tui.configs.Configurations)+1),
+		ID:        tui.nextConfigID(),
 		Name:      configName,
 		Protocol:  protocol,
 		Link:      proxyLink,
@@ -74,13 +65,43 @@ func (tui *TUI) addConfig() {
 	tui.updateStatus(fmt.Sprintf("Configuration '%s' (%s) added and saved successfully", configName, protocol), tcell.ColorGreen)
 }
 
+// errUnsupportedLink is returned for links whose scheme matches no known protocol
+var errUnsupportedLink = errors.New("unsupported proxy link")
+
+// parseLinkForSingBox detects the protocol of a proxy link and parses it into a sing-box config
+func parseLinkForSingBox(proxyLink string) (protocol string, config interface{}, err error) {
+	switch {
+	case strings.HasPrefix(proxyLink, "vmess://"):
+		protocol = "vmess"
+		config, err = parser.VMessToSingBox(proxyLink)
+	case strings.HasPrefix(proxyLink, "ss://"):
+		protocol = "shadowsocks"
+		config, err = parser.SSToSingBox(proxyLink)
+	case strings.HasPrefix(proxyLink, "vless://"):
+		protocol = "vless"
+		config, err = parser.VLESSToSingBox(proxyLink)
+	case strings.HasPrefix(proxyLink, "trojan://"):
+		protocol = "trojan"
+		config, err = parser.TrojanToSingBox(proxyLink)
+	case strings.HasPrefix(proxyLink, "hysteria2://"), strings.HasPrefix(proxyLink, "hy2://"):
+		protocol = "hysteria2"
+		config, err = parser.Hysteria2ToSingBox(proxyLink)
+	case strings.HasPrefix(proxyLink, "tuic://"):
+		protocol = "tuic"
+		config, err = parser.TUICToSingBox(proxyLink)
+	default:
+		err = errUnsupportedLink
+	}
+	return protocol, config, err
+}
+
 // loadConfigList loads existing configurations from storage
 func (tui *TUI) loadConfigList() {
 	tui.loadConfigsFromFile()
 	tui.refreshConfigList()
 
 	if len(tui.configs.Configurations) == 0 {
-		tui.updateStatus("Ready to add configurations. Use Ctrl+A to add a new proxy configuration (VMess/SS/VLESS).", tcell.ColorBlue)
+		tui.updateStatus("Ready to add configurations. Use Ctrl+A to add a new proxy configuration (VMess/SS/VLESS/Trojan/Hysteria2/TUIC).", tcell.ColorBlue)
 	} else {
 		tui.updateStatus(fmt.Sprintf("Ready! %d configuration(s) loaded from configs.json", len(tui.configs.Configurations)), tcell.ColorGreen)
 	}
@@ -95,10 +116,10 @@ func (tui *TUI) refreshConfigList() {
 		for i, config := range tui.configs.Configurations {
 			localIndex := i
 			displayName := fmt.Sprintf("%s (%s)", config.Name, config.Protocol)
-			secondaryText := fmt.Sprintf("Created: %s | Last Used: %s",
-				config.CreatedAt[:10], config.LastUsed[:10])
-
-			tui.configList.AddItem(displayName, secondaryText, 0, func() {
+			if subName := tui.subscriptionName(config.SubscriptionID); subName != "" {
+				displayName = tview.Escape(fmt.Sprintf("[%s] ", subName)) + displayName
+			}
+			tui.configList.AddItem(displayName, tui.configSecondaryText(config), 0, func() {
 				tui.viewConfig(localIndex)
 			})
 		}
@@ -107,6 +128,15 @@ func (tui *TUI) refreshConfigList() {
 	tui.updateConfigCount()
 }
 
+// configSecondaryText returns the list details of a configuration, including its last latency result
+func (tui *TUI) configSecondaryText(config Config) string {
+	text := fmt.Sprintf("Created: %s | Last Used: %s", config.CreatedAt[:10], config.LastUsed[:10])
+	if result, ok := tui.latency[config.Link]; ok {
+		text += " | Latency: " + tview.Escape(result.String())
+	}
+	return text
+}
+
 // updateConfigCount updates the status to show the current number of configurations
 func (tui *TUI) updateConfigCount() {
 	if count := len(tui.configs.Configurations); count == 0 {
@@ -147,6 +177,14 @@ func (tui *TUI) viewConfig(configIndex int) {
 		parsedConfig, err = parser.SSToSingBox(config.Link)
 	case "vless":
 		parsedConfig, err = parser.VLESSToSingBox(config.Link)
+	case "trojan":
+		parsedConfig, err = parser.TrojanToSingBox(config.Link)
+	case "hysteria2":
+		parsedConfig, err = parser.Hysteria2ToSingBox(config.Link)
+	case "tuic":
+		parsedConfig, err = parser.TUICToSingBox(config.Link)
+	case protocolChain:
+		parsedConfig, err = tui.renderConfig(config, parser.TargetSingBox)
 	default:
 		tui.updateStatus(fmt.Sprintf("Unsupported protocol: %s", config.Protocol), tcell.ColorRed)
 		return
@@ -162,9 +200,14 @@ func (tui *TUI) viewConfig(configIndex int) {
 		return
 	}
 
+	link := config.Link
+	if config.Protocol == protocolChain {
+		link = tui.chainRoute(config)
+	}
+
 	tui.configText.SetText(fmt.Sprintf(
 		"Configuration: %s\nProtocol: %s\nLink: %s\nCreated: %s\nLast Used: %s\n\nParsed Configuration:\n%s\n\nReady to connect - click Connect button to start",
-		config.Name, config.Protocol, config.Link, config.CreatedAt[:10], config.LastUsed[:10], string(configJSON)))
+		config.Name, config.Protocol, link, config.CreatedAt[:10], config.LastUsed[:10], string(configJSON)))
 
 	tui.vmessInput.SetText(config.Link)
 	tui.updateStatus(fmt.Sprintf("Selected configuration: %s (Ready to connect)", config.Name), tcell.ColorBlue)
@@ -173,6 +216,39 @@ func (tui *TUI) viewConfig(configIndex int) {
 	tui.saveConfigsToFile()
 }
 
+// configShareLink regenerates the share link of a saved config under its current name
+func configShareLink(config Config) (string, error) {
+	if config.Protocol == protocolChain {
+		return "", fmt.Errorf("chains have no share link, share their hops instead")
+	}
+	if config.Link == "" {
+		return "", fmt.Errorf("%s has no link to share", config.Name)
+	}
+	node, err := parser.Parse(config.Link)
+	if err != nil {
+		return "", err
+	}
+	node.Remark = config.Name
+	return parser.Encode(node)
+}
+
+// shareSelectedConfig regenerates the share link of the selected configuration and copies it
+func (tui *TUI) shareSelectedConfig() {
+	config, ok := tui.getSelectedConfig()
+	if !ok {
+		return
+	}
+
+	link, err := configShareLink(config)
+	if err != nil {
+		tui.updateStatus(fmt.Sprintf("Error generating share link: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.configText.SetText(fmt.Sprintf("Share link for %s:\n\n%s", config.Name, link))
+	tui.copyToClipboard(link)
+}
+
 // deleteSelectedConfig deletes the currently selected configuration
 func (tui *TUI) deleteSelectedConfig() {
 	currentIndex := tui.configList.GetCurrentItem()
@@ -1,8 +1,13 @@
 package tui
 
 import (
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/gdamore/tcell/v2"
+
+	"tui_proxy_client/parser"
 )
 
 func TestTUI_AddConfig(t *testing.T) {
@@ -27,6 +32,11 @@ func TestTUI_AddConfig(t *testing.T) {
 			proxyLink:   "vless://12345678-1234-1234-1234-123456789012@example.com:443?encryption=none&type=tcp#Test%20Config",
 			expectError: false,
 		},
+		{
+			name:        "valid trojan link",
+			proxyLink:   "trojan://password@example.com:443?sni=example.com&type=ws&path=/ws#Test%20Config",
+			expectError: false,
+		},
 		{
 			name:        "empty link",
 			proxyLink:   "",
@@ -326,3 +336,131 @@ func TestTUI_GetSelectedConfig(t *testing.T) {
 	// The function should return false when selection is out of bounds
 	// We can't easily test this without a full UI environment
 }
+
+func TestClientButtons(t *testing.T) {
+	tests := []struct {
+		protocol string
+		expected []string
+	}{
+		{"vmess", []string{"V2Ray", "Xray", "SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"trojan", []string{"V2Ray", "Xray", "SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"hysteria2", []string{"SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"tuic", []string{"SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"unknown", []string{"Cancel"}},
+	}
+
+	for _, tt := range tests {
+		buttons := clientButtons(tt.protocol)
+		if strings.Join(buttons, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("clientButtons(%s) = %v, want %v", tt.protocol, buttons, tt.expected)
+		}
+	}
+}
+
+func TestTUI_ConfigClientButtons_NodeSettings(t *testing.T) {
+	tui := newTestTUI(t)
+	vision := Config{ID: "vision", Protocol: "vless", Link: "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&sni=www.microsoft.com&pbk=PUBLICKEY&flow=xtls-rprx-vision"}
+	plain := Config{ID: "plain", Protocol: "vless", Link: testVLESSLink}
+	xhttp := Config{ID: "xhttp", Protocol: "vless", Link: "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=tls&sni=example.com&type=xhttp&path=%2Fx"}
+	tui.configs.Configurations = []Config{vision, plain, xhttp}
+	chain := Config{Protocol: protocolChain, Chain: []string{"plain", "vision"}}
+
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"plain", plain, []string{"V2Ray", "Xray", "SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"vision", vision, []string{"Xray", "SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"chain with a vision hop", chain, []string{"Xray", "SingBox", "SingBox TUN", "Mihomo", "Cancel"}},
+		{"xhttp", xhttp, []string{"Xray", "Cancel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := supportedButtons(clientButtons(tui.configProtocols(tt.config)...), tui.configNodes(tt.config))
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("buttons = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	group := ConfigGroup{Members: []string{"plain", "vision"}, Policy: parser.Group{Type: parser.GroupURLTest}}
+	if got := tui.groupClientButtons(group); strings.Contains(strings.Join(got, ","), "V2Ray") {
+		t.Errorf("groupClientButtons() = %v, want no V2Ray for a vision member", got)
+	}
+}
+
+func TestMarshalClientConfig(t *testing.T) {
+	tui := newTestTUI(t)
+
+	config, err := tui.parseForMihomo(Config{Protocol: "vless", Link: testVLESSLink})
+	if err != nil {
+		t.Fatalf("parseForMihomo() unexpected error: %v", err)
+	}
+
+	data, err := marshalClientConfig("config.yaml", config)
+	if err != nil {
+		t.Fatalf("marshalClientConfig() unexpected error: %v", err)
+	}
+	if !strings.Contains(string(data), "mixed-port: 1080") || strings.HasPrefix(string(data), "{") {
+		t.Errorf("marshalClientConfig(config.yaml) should produce YAML, got:\n%s", data)
+	}
+
+	data, err = marshalClientConfig("config.json", config)
+	if err != nil {
+		t.Fatalf("marshalClientConfig() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(string(data), "{") {
+		t.Errorf("marshalClientConfig(config.json) should produce JSON, got:\n%s", data)
+	}
+}
+
+func TestConfigShareLink(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.configs.Configurations = []Config{
+		{ID: "1", Name: "Renamed VLESS", Protocol: "vless", Link: testVLESSLink, CreatedAt: "2024-01-01T00:00:00Z", LastUsed: "2024-01-01T00:00:00Z"},
+		{ID: "2", Name: "Broken", Protocol: "vless", Link: "vless://broken", CreatedAt: "2024-01-01T00:00:00Z", LastUsed: "2024-01-01T00:00:00Z"},
+	}
+
+	link, err := configShareLink(tui.configs.Configurations[0])
+	if err != nil {
+		t.Fatalf("configShareLink() unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(link, "vless://") || !strings.HasSuffix(link, "#Renamed%20VLESS") {
+		t.Errorf("configShareLink() = %s, want vless link named after the config", link)
+	}
+
+	original, _ := parser.Parse(testVLESSLink)
+	shared, err := parser.Parse(link)
+	if err != nil {
+		t.Fatalf("configShareLink() produced unparsable link: %v", err)
+	}
+	if shared.Server != original.Server || shared.UUID != original.UUID || shared.Transport != original.Transport {
+		t.Errorf("configShareLink() changed the node: %+v, want %+v", shared, original)
+	}
+
+	tui.refreshConfigList()
+	tui.configList.SetCurrentItem(0)
+	tui.shareSelectedConfig()
+	if text := tui.configText.GetText(true); !strings.Contains(text, link) {
+		t.Errorf("shareSelectedConfig() log = %q, want share link", text)
+	}
+
+	if _, err := configShareLink(tui.configs.Configurations[1]); err == nil {
+		t.Error("configShareLink() expected error for invalid link")
+	}
+	if _, err := configShareLink(Config{Name: "Empty", Protocol: "vless"}); err == nil || !strings.Contains(err.Error(), "no link to share") {
+		t.Errorf("configShareLink() error = %v, want no link to share", err)
+	}
+
+	tui.showGroups()
+	tui.configText.SetText("")
+	ctrlK := tcell.NewEventKey(tcell.KeyCtrlK, 0, tcell.ModCtrl)
+	if tui.app.GetInputCapture()(ctrlK) != ctrlK {
+		t.Error("Ctrl+K should not share from the groups screen")
+	}
+	if text := tui.configText.GetText(true); text != "" {
+		t.Errorf("Ctrl+K on the groups screen shared %q", text)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,12 +15,14 @@ import (
 
 	"github.com/gdamore/tcell/v2"
 	"github.com/rivo/tview"
+	"gopkg.in/yaml.v3"
 )
 
 // connectClient is now a high-level wrapper that delegates to helper methods
 func (tui *TUI) connectClient(
 	clientType string,
-	parser func(string) (interface{}, error),
+	parser func(Config) (interface{}, error),
+	configFile string,
 	command []string,
 ) {
 	config, ok := tui.getSelectedConfig()
@@ -27,12 +30,12 @@ func (tui *TUI) connectClient(
 		return
 	}
 
-	if !tui.prepareConfigFile(config, parser) {
+	if !tui.prepareConfigFile(config, parser, configFile) {
 		return
 	}
 
 	tui.updateStatus(fmt.Sprintf("Starting %s with configuration: %s...", clientType, config.Name), tcell.ColorBlue)
-	tui.configText.SetText(fmt.Sprintf("Starting %s...\n", clientType))
+	tui.configText.SetText(fmt.Sprintf("Starting %s...\nClient output is shown in the log viewer (Ctrl+Y)\n", clientType))
 
 	go tui.startClientProcess(clientType, config.Name, command)
 }
@@ -53,21 +56,21 @@ func (tui *TUI) getSelectedConfig() (Config, bool) {
 	return tui.configs.Configurations[currentIndex], true
 }
 
-// prepareConfigFile parses, saves JSON, and updates last used time
-func (tui *TUI) prepareConfigFile(config Config, parser func(string) (interface{}, error)) bool {
-	parsedConfig, err := parser(config.Link)
+// prepareConfigFile parses, saves the client config file, and updates last used time
+func (tui *TUI) prepareConfigFile(config Config, parser func(Config) (interface{}, error), configFile string) bool {
+	parsedConfig, err := parser(config)
 	if err != nil {
 		tui.updateStatus(fmt.Sprintf("Error parsing VMess: %v", err), tcell.ColorRed)
 		return false
 	}
 
-	configJSON, err := json.MarshalIndent(parsedConfig, "", "  ")
+	data, err := marshalClientConfig(configFile, parsedConfig)
 	if err != nil {
 		tui.updateStatus(fmt.Sprintf("Error marshaling config: %v", err), tcell.ColorRed)
 		return false
 	}
 
-	if err := os.WriteFile("config.json", configJSON, 0644); err != nil {
+	if err := os.WriteFile(configFile, data, 0644); err != nil {
 		tui.updateStatus(fmt.Sprintf("Error saving config: %v", err), tcell.ColorRed)
 		return false
 	}
@@ -77,47 +80,73 @@ func (tui *TUI) prepareConfigFile(config Config, parser func(string) (interface{
 	return true
 }
 
-// startClientProcess launches the command and manages its lifecycle
-func (tui *TUI) startClientProcess(clientType, configName string, command []string) {
-	cmd := exec.Command(command[0], command[1:]...)
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		tui.showPipeError("stdout", err)
-		return
+// marshalClientConfig encodes a config as YAML for .yaml files and as indented JSON otherwise
+func marshalClientConfig(configFile string, config interface{}) ([]byte, error) {
+	if strings.HasSuffix(configFile, ".yaml") || strings.HasSuffix(configFile, ".yml") {
+		return yaml.Marshal(config)
 	}
-	stderr, err := cmd.StderrPipe()
-	if err != nil {
-		tui.showPipeError("stderr", err)
-		return
+	return json.MarshalIndent(config, "", "  ")
+}
+
+// startClientProcess launches the command under the supervisor and manages its
+// lifecycle, restarting it when it crashes
+func (tui *TUI) startClientProcess(clientType, configName string, command []string) {
+	// Only one client can own the proxy port; replace the one already running
+	if tui.supervisor.Running() != nil || tui.supervisor.Restarting() {
+		tui.supervisor.Stop()
+		tui.restoreSystemProxy()
 	}
 
-	if err := cmd.Start(); err != nil {
+	proc, err := tui.launchClient(tui.supervisor.Start, command)
+	if err != nil {
 		tui.showStartError(clientType, err)
 		return
 	}
 
+	systemProxyNote := tui.enableSystemProxy(clientType)
+
 	tui.app.QueueUpdateDraw(func() {
 		tui.isConnected = true
 		tui.clientType = clientType
 		tui.connectedConfig = configName
-		tui.updateStatus(fmt.Sprintf("%s started successfully with config: %s! Check your proxy settings (127.0.0.1:1080)", clientType, configName), tcell.ColorGreen)
+		tui.clientInbound = tui.configs.Settings.Inbound.WithDefaults()
+		tui.restarts = 0
+		tui.startTrafficPolling(clientType)
+		if clientType == clientSingBoxTUN {
+			tui.updateStatus(fmt.Sprintf("%s started successfully with config: %s (PID %d)! All traffic is routed through the TUN device (proxy also on %s)%s", clientType, configName, proc.PID(), tui.proxyAddress(), systemProxyNote), tcell.ColorGreen)
+		} else {
+			tui.updateStatus(fmt.Sprintf("%s started successfully with config: %s (PID %d)! Check your proxy settings (%s)%s", clientType, configName, proc.PID(), tui.proxyAddress(), systemProxyNote), tcell.ColorGreen)
+		}
 		tui.updateConnectionStatus()
 	})
 
-	go tui.streamOutput(stdout, "")
-	go tui.streamOutput(stderr, "[ERROR] ")
+	tui.superviseClient(clientType, configName, command, proc)
+}
 
-	if err := cmd.Wait(); err != nil {
-		tui.handleClientExit(clientType, err)
+// launchClient starts the command with start and streams its output until it exits
+func (tui *TUI) launchClient(start func(*exec.Cmd) (*clientProcess, error), command []string) (*clientProcess, error) {
+	cmd := exec.Command(command[0], command[1:]...)
+	stdout, stdoutWriter := io.Pipe()
+	stderr, stderrWriter := io.Pipe()
+	cmd.Stdout = stdoutWriter
+	cmd.Stderr = stderrWriter
+
+	proc, err := start(cmd)
+	if err != nil {
+		stdoutWriter.Close()
+		stderrWriter.Close()
+		return nil, err
 	}
-}
 
-// showPipeError displays an error if pipe creation fails
-func (tui *TUI) showPipeError(pipeName string, err error) {
-	tui.app.QueueUpdateDraw(func() {
-		tui.configText.SetText(fmt.Sprintf("Error creating %s pipe: %v", pipeName, err))
-	})
+	go tui.streamOutput(stdout, logInfo)
+	go tui.streamOutput(stderr, logError)
+	go func() {
+		proc.Wait()
+		stdoutWriter.Close()
+		stderrWriter.Close()
+	}()
+
+	return proc, nil
 }
 
 // showStartError displays an error if starting the process fails
@@ -128,32 +157,36 @@ func (tui *TUI) showStartError(clientType string, err error) {
 	})
 }
 
-// handleClientExit updates UI when process ends
-func (tui *TUI) handleClientExit(clientType string, err error) {
+// handleClientExit updates UI when the process ends without being stopped and
+// will not be restarted
+func (tui *TUI) handleClientExit(clientType string, err error, restarts int) {
+	message := fmt.Sprintf("%s exited unexpectedly", clientType)
+	if err != nil {
+		message = fmt.Sprintf("%s stopped with error: %v", clientType, err)
+	}
+	if restarts > 0 {
+		message = fmt.Sprintf("%s - gave up after %d restart attempts", message, restarts)
+	}
+
 	tui.app.QueueUpdateDraw(func() {
 		tui.isConnected = false
 		tui.clientType = ""
 		tui.connectedConfig = ""
-		tui.updateStatus(fmt.Sprintf("%s stopped with error: %v", clientType, err), tcell.ColorRed)
+		tui.restarts = 0
+		tui.stopTrafficPolling()
+		tui.updateStatus(message, tcell.ColorRed)
 		tui.updateConnectionStatus()
 	})
 }
 
-// streamOutput reads process output and updates UI
-func (tui *TUI) streamOutput(pipe io.Reader, prefix string) {
+// streamOutput reads process output into the log viewer. Lines without a
+// recognised level get fallback.
+func (tui *TUI) streamOutput(pipe io.Reader, fallback logLevel) {
 	scanner := bufio.NewScanner(pipe)
 	for scanner.Scan() {
-		line := scanner.Text()
+		entry := parseLogLine(scanner.Text(), fallback, time.Now())
 		tui.app.QueueUpdateDraw(func() {
-			currentText := tui.configText.GetText(true)
-			if len(currentText) > 10000 {
-				lines := strings.Split(currentText, "\n")
-				if len(lines) > 100 {
-					currentText = strings.Join(lines[len(lines)-100:], "\n")
-				}
-			}
-			tui.configText.SetText(currentText + "\n" + prefix + line)
-			tui.configText.ScrollToEnd()
+			tui.appendLog(entry)
 		})
 	}
 }
@@ -174,17 +207,20 @@ func (tui *TUI) connectToConfig() {
 
 	clientModal := tview.NewModal().
 		SetText(fmt.Sprintf("Choose client for configuration: %s (%s)", config.Name, config.Protocol)).
-		AddButtons([]string{"V2Ray", "SingBox", "Cancel"}).
+		AddButtons(supportedButtons(clientButtons(tui.configProtocols(config)...), tui.configNodes(config))).
 		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			launch := clientLaunches[buttonLabel]
 			switch buttonLabel {
 			case "V2Ray":
-				tui.connectClient("v2ray", func(link string) (interface{}, error) {
-					return tui.parseForV2Ray(link, config.Protocol)
-				}, []string{"v2ray", "run", "config.json"})
+				tui.connectClient(launch.clientType, tui.parseForV2Ray, launch.configFile, launch.command)
+			case "Xray":
+				tui.connectClient(launch.clientType, tui.parseForXray, launch.configFile, launch.command)
 			case "SingBox":
-				tui.connectClient("singbox", func(link string) (interface{}, error) {
-					return tui.parseForSingBox(link, config.Protocol)
-				}, []string{"sing-box", "run", "-c", "config.json"})
+				tui.connectClient(launch.clientType, tui.parseForSingBox, launch.configFile, launch.command)
+			case "SingBox TUN":
+				tui.connectSingBoxTUN()
+			case "Mihomo":
+				tui.connectClient(launch.clientType, tui.parseForMihomo, launch.configFile, launch.command)
 			}
 			tui.app.SetRoot(tui.mainFlex, true)
 		})
@@ -192,30 +228,113 @@ func (tui *TUI) connectToConfig() {
 	tui.app.SetRoot(clientModal, true)
 }
 
-// parseForV2Ray parses a proxy link for V2Ray based on protocol
-func (tui *TUI) parseForV2Ray(link, protocol string) (interface{}, error) {
-	switch protocol {
-	case "vmess":
-		return parser.VMessToV2ray(link)
-	case "shadowsocks":
-		return parser.SSToV2ray(link)
-	case "vless":
-		return parser.VLESSToV2Ray(link)
+// clientLaunch describes how a connect dialog choice is rendered and started
+type clientLaunch struct {
+	clientType string
+	target     parser.Target
+	configFile string
+	command    []string
+}
+
+// clientLaunches are the plain clients of the connect dialog by button label
+var clientLaunches = map[string]clientLaunch{
+	"V2Ray":   {clientType: "v2ray", target: parser.TargetV2Ray, configFile: "config.json", command: []string{"v2ray", "run", "config.json"}},
+	"Xray":    {clientType: "xray", target: parser.TargetXray, configFile: "config.json", command: []string{"xray", "run", "-c", "config.json"}},
+	"SingBox": {clientType: "singbox", target: parser.TargetSingBox, configFile: "config.json", command: []string{"sing-box", "run", "-c", "config.json"}},
+	"Mihomo":  {clientType: "mihomo", target: parser.TargetMihomo, configFile: "config.yaml", command: []string{"mihomo", "-f", "config.yaml"}},
+}
+
+// clientButtons returns the client choices that can run all of the given protocols
+func clientButtons(protocols ...string) []string {
+	var buttons []string
+	if supportsAll(protocols, parser.TargetV2Ray) {
+		buttons = append(buttons, "V2Ray")
+	}
+	if supportsAll(protocols, parser.TargetXray) {
+		buttons = append(buttons, "Xray")
+	}
+	if supportsAll(protocols, parser.TargetSingBox) {
+		buttons = append(buttons, "SingBox", "SingBox TUN")
+	}
+	if supportsAll(protocols, parser.TargetMihomo) {
+		buttons = append(buttons, "Mihomo")
+	}
+	return append(buttons, "Cancel")
+}
+
+// supportsAll reports whether target can render every protocol
+func supportsAll(protocols []string, target parser.Target) bool {
+	for _, protocol := range protocols {
+		if !parser.Supports(protocol, target) {
+			return false
+		}
+	}
+	return len(protocols) > 0
+}
+
+// supportedButtons drops the client choices that cannot render one of nodes,
+// such as V2Ray for a server using the XTLS Vision flow
+func supportedButtons(buttons []string, nodes []*parser.ProxyNode) []string {
+	return slices.DeleteFunc(buttons, func(label string) bool {
+		launch, ok := clientLaunches[strings.TrimSuffix(label, " TUN")]
+		if !ok {
+			return false // Cancel
+		}
+		for _, node := range nodes {
+			if !parser.SupportsNode(node, launch.target) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// parseForV2Ray renders a configuration for V2Ray based on protocol
+func (tui *TUI) parseForV2Ray(config Config) (interface{}, error) {
+	switch config.Protocol {
+	case "vmess", "shadowsocks", "vless", "trojan", protocolChain:
+		return tui.renderConfig(config, parser.TargetV2Ray)
+	case "hysteria2", "tuic":
+		return nil, fmt.Errorf("%s is not supported by V2Ray, please connect with sing-box", config.Protocol)
 	default:
-		return nil, fmt.Errorf("unsupported protocol for V2Ray: %s", protocol)
+		return nil, fmt.Errorf("unsupported protocol for V2Ray: %s", config.Protocol)
 	}
 }
 
-// parseForSingBox parses a proxy link for sing-box based on protocol
-func (tui *TUI) parseForSingBox(link, protocol string) (interface{}, error) {
-	switch protocol {
-	case "vmess":
-		return parser.VMessToSingBox(link)
-	case "shadowsocks":
-		return parser.SSToSingBox(link)
-	case "vless":
-		return parser.VLESSToSingBox(link)
+// parseForSingBox renders a configuration for sing-box based on protocol
+func (tui *TUI) parseForSingBox(config Config) (interface{}, error) {
+	switch config.Protocol {
+	case "vmess", "shadowsocks", "vless", "trojan", "hysteria2", "tuic", protocolChain:
+		return tui.renderConfig(config, parser.TargetSingBox)
 	default:
-		return nil, fmt.Errorf("unsupported protocol for sing-box: %s", protocol)
+		return nil, fmt.Errorf("unsupported protocol for sing-box: %s", config.Protocol)
+	}
+}
+
+// parseForXray renders a configuration as an Xray-core config
+func (tui *TUI) parseForXray(config Config) (interface{}, error) {
+	return tui.renderConfig(config, parser.TargetXray)
+}
+
+// parseForMihomo renders a configuration as a mihomo config
+func (tui *TUI) parseForMihomo(config Config) (interface{}, error) {
+	return tui.renderConfig(config, parser.TargetMihomo)
+}
+
+// renderConfig parses a configuration's link and renders it for target with the
+// current settings and the configuration's routing rules
+func (tui *TUI) renderConfig(config Config, target parser.Target) (map[string]any, error) {
+	return tui.renderConfigWithOptions(config, target, tui.renderOptions(config))
+}
+
+// renderConfigWithOptions parses a configuration's link and renders it with explicit options
+func (tui *TUI) renderConfigWithOptions(config Config, target parser.Target, opts parser.Options) (map[string]any, error) {
+	if config.Protocol == protocolChain {
+		return tui.renderChain(config, target, opts)
+	}
+	node, err := parser.Parse(config.Link)
+	if err != nil {
+		return nil, err
 	}
+	return parser.RenderWithOptions(node, target, opts)
 }
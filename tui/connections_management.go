@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const connectionsHelp = "Enter/Delete: close | r: refresh | Esc: back"
+
+// connectionsHeader is the first row of the connections table
+var connectionsHeader = []string{"Host", "Network", "Rule", "Outbound", "Upload", "Download"}
+
+// connectedClashAPI returns the Clash API of the connected client
+func (tui *TUI) connectedClashAPI() (clashAPI, error) {
+	if !tui.isConnected {
+		return clashAPI{}, fmt.Errorf("connect to a configuration first")
+	}
+	switch tui.clientType {
+	case "singbox", clientSingBoxTUN, "mihomo":
+	default:
+		return clashAPI{}, fmt.Errorf("%s has no connections API, connect with sing-box or mihomo", tui.clientType)
+	}
+	api := tui.controlAPI()
+	if api.Listen == "" {
+		return clashAPI{}, fmt.Errorf("the stats API is turned off in Settings")
+	}
+	return newClashAPI(api.Listen, api.Secret)
+}
+
+// showConnections opens the live connections of the connected client
+func (tui *TUI) showConnections() {
+	api, err := tui.connectedClashAPI()
+	if err != nil {
+		tui.updateStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.stopConnectionsRefresh()
+	ctx, cancel := context.WithCancel(context.Background())
+	tui.connectionsAPI, tui.connectionsCancel = api, cancel
+	tui.connections = nil
+	tui.refreshConnectionsTable()
+	tui.setConnectionsStatus("Loading connections... | "+connectionsHelp, tcell.ColorYellow)
+	tui.app.SetRoot(tui.connectionsView, true)
+	tui.app.SetFocus(tui.connectionsTable)
+
+	go tui.pollConnections(ctx, api, tui.connectionsRefresh, trafficInterval)
+}
+
+// hideConnections stops refreshing and returns to the main screen
+func (tui *TUI) hideConnections() {
+	tui.stopConnectionsRefresh()
+	tui.app.SetRoot(tui.mainFlex, true)
+}
+
+// stopConnectionsRefresh ends the periodic refresh. Must run on the UI goroutine.
+func (tui *TUI) stopConnectionsRefresh() {
+	if tui.connectionsCancel != nil {
+		tui.connectionsCancel()
+	}
+	tui.connectionsCancel = nil
+}
+
+// createConnectionsView builds the live connections screen
+func (tui *TUI) createConnectionsView() *tview.Flex {
+	tui.connectionsTable = tview.NewTable().
+		SetSelectable(true, false).
+		SetFixed(1, 0)
+	tui.connectionsTable.SetBorder(true)
+	tui.connectionsTable.SetTitle(" Active Connections ")
+	tui.connectionsTable.SetSelectedFunc(func(row, column int) {
+		tui.closeSelectedConnection()
+	})
+	tui.connectionsTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyDelete:
+			tui.closeSelectedConnection()
+		case event.Key() == tcell.KeyEscape:
+			tui.hideConnections()
+		case event.Key() == tcell.KeyRune && event.Rune() == 'r':
+			select {
+			case tui.connectionsRefresh <- struct{}{}:
+			default: // a refresh is already pending
+			}
+		default:
+			return event
+		}
+		return nil
+	})
+
+	tui.connectionsStatus = tview.NewTextView()
+	tui.connectionsStatus.SetTextAlign(tview.AlignCenter)
+	tui.connectionsStatus.SetBorder(true)
+	tui.connectionsStatus.SetTitle(" Status ")
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tui.connectionsTable, 0, 1, true).
+		AddItem(tui.connectionsStatus, 3, 0, false)
+}
+
+// pollConnections reloads the connections every interval, and whenever refresh
+// receives, until ctx is cancelled
+func (tui *TUI) pollConnections(ctx context.Context, api clashAPI, refresh <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		tui.loadConnections(ctx, api)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-refresh:
+		}
+	}
+}
+
+// loadConnections fetches the connections and shows them unless ctx was cancelled meanwhile
+func (tui *TUI) loadConnections(ctx context.Context, api clashAPI) {
+	queryCtx, cancel := context.WithTimeout(ctx, trafficInterval)
+	conns, err := api.Connections(queryCtx)
+	cancel()
+
+	tui.app.QueueUpdateDraw(func() {
+		if ctx.Err() != nil {
+			return // left the screen
+		}
+		if err != nil {
+			tui.setConnectionsStatus(fmt.Sprintf("Error: %v | %s", err, connectionsHelp), tcell.ColorRed)
+			return
+		}
+		tui.setConnections(conns.Connections)
+		tui.setConnectionsStatus(fmt.Sprintf("%d connections | %s", len(tui.connections), connectionsHelp), tcell.ColorGreen)
+	})
+}
+
+// setConnections shows conns newest first, keeping the selected connection selected
+func (tui *TUI) setConnections(conns []clashConnection) {
+	selected, hasSelection := tui.selectedConnection()
+
+	slices.SortStableFunc(conns, func(a, b clashConnection) int {
+		return b.Start.Compare(a.Start)
+	})
+	tui.connections = conns
+	tui.refreshConnectionsTable()
+
+	if hasSelection {
+		for i, conn := range conns {
+			if conn.ID == selected.ID {
+				tui.connectionsTable.Select(i+1, 0)
+				break
+			}
+		}
+	}
+}
+
+// refreshConnectionsTable redraws the table from tui.connections
+func (tui *TUI) refreshConnectionsTable() {
+	row, _ := tui.connectionsTable.GetSelection()
+	tui.connectionsTable.Clear()
+
+	for column, title := range connectionsHeader {
+		tui.connectionsTable.SetCell(0, column, tview.NewTableCell(title).
+			SetTextColor(tcell.ColorYellow).
+			SetSelectable(false).
+			SetExpansion(1))
+	}
+	for i, conn := range tui.connections {
+		cells := []string{conn.destination(), conn.Metadata.Network, conn.rule(), conn.outbound(), formatBytes(conn.Upload), formatBytes(conn.Download)}
+		for column, text := range cells {
+			tui.connectionsTable.SetCell(i+1, column, tview.NewTableCell(text).SetExpansion(1))
+		}
+	}
+
+	// Keep the cursor in range when connections went away
+	if row > len(tui.connections) {
+		row = len(tui.connections)
+	}
+	if row < 1 {
+		row = 1
+	}
+	tui.connectionsTable.Select(row, 0)
+}
+
+// selectedConnection returns the connection highlighted in the table
+func (tui *TUI) selectedConnection() (clashConnection, bool) {
+	row, _ := tui.connectionsTable.GetSelection()
+	if row < 1 || row > len(tui.connections) {
+		return clashConnection{}, false
+	}
+	return tui.connections[row-1], true
+}
+
+// closeSelectedConnection asks the core to close the highlighted connection
+func (tui *TUI) closeSelectedConnection() {
+	conn, ok := tui.selectedConnection()
+	if !ok {
+		tui.setConnectionsStatus("No connection selected | "+connectionsHelp, tcell.ColorYellow)
+		return
+	}
+
+	api := tui.connectionsAPI
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), trafficInterval)
+		err := api.CloseConnection(ctx, conn.ID)
+		cancel()
+
+		tui.app.QueueUpdateDraw(func() {
+			if err != nil {
+				tui.setConnectionsStatus(fmt.Sprintf("Error closing connection to %s: %v", conn.destination(), err), tcell.ColorRed)
+				return
+			}
+			tui.connections = slices.DeleteFunc(tui.connections, func(c clashConnection) bool {
+				return c.ID == conn.ID
+			})
+			tui.refreshConnectionsTable()
+			tui.setConnectionsStatus(fmt.Sprintf("Closed connection to %s | %s", conn.destination(), connectionsHelp), tcell.ColorGreen)
+		})
+	}()
+}
+
+// setConnectionsStatus updates the connections screen status line
+func (tui *TUI) setConnectionsStatus(message string, color tcell.Color) {
+	tui.connectionsStatus.SetText(message).SetTextColor(color)
+}
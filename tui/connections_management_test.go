@@ -0,0 +1,220 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClashServer serves /connections like sing-box and mihomo and closes
+// connections on DELETE
+type fakeClashServer struct {
+	*httptest.Server
+	secret string
+
+	mu          sync.Mutex
+	connections []clashConnection
+}
+
+func newFakeClashServer(t *testing.T, secret string, conns []clashConnection) *fakeClashServer {
+	s := &fakeClashServer{secret: secret, connections: conns}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.secret {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/connections":
+			json.NewEncoder(w).Encode(clashConnections{UploadTotal: 1, DownloadTotal: 2, Connections: s.connections})
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/connections/"):
+			id := strings.TrimPrefix(r.URL.Path, "/connections/")
+			for i, conn := range s.connections {
+				if conn.ID == id {
+					s.connections = append(s.connections[:i], s.connections[i+1:]...)
+					w.WriteHeader(http.StatusNoContent)
+					return
+				}
+			}
+			http.NotFound(w, r)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeClashServer) listen() string {
+	return strings.TrimPrefix(s.URL, "http://")
+}
+
+func (s *fakeClashServer) count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.connections)
+}
+
+// testConnection returns a connection to host started age ago
+func testConnection(id, host string, age time.Duration) clashConnection {
+	var conn clashConnection
+	conn.ID = id
+	conn.Metadata.Network = "tcp"
+	conn.Metadata.Host = host
+	conn.Metadata.DestinationPort = "443"
+	conn.Upload, conn.Download = 1536, 4096
+	conn.Start = time.Now().Add(-age)
+	conn.Chains = []string{"proxy-1", "proxy"}
+	conn.Rule = "final"
+	return conn
+}
+
+func TestClashConnection_Columns(t *testing.T) {
+	tests := []struct {
+		name            string
+		conn            clashConnection
+		wantDestination string
+		wantRule        string
+		wantOutbound    string
+	}{
+		{
+			name:            "host name",
+			conn:            testConnection("1", "example.com", 0),
+			wantDestination: "example.com:443",
+			wantRule:        "final",
+			wantOutbound:    "proxy > proxy-1",
+		},
+		{
+			name: "IP only with rule payload",
+			conn: func() clashConnection {
+				c := testConnection("2", "", 0)
+				c.Metadata.DestinationIP = "2001:db8::1"
+				c.Rule, c.RulePayload = "DomainSuffix", "ads.example"
+				c.Chains = []string{"block"}
+				return c
+			}(),
+			wantDestination: "[2001:db8::1]:443",
+			wantRule:        "DomainSuffix(ads.example)",
+			wantOutbound:    "block",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.conn.destination(); got != tt.wantDestination {
+				t.Errorf("destination() = %q, want %q", got, tt.wantDestination)
+			}
+			if got := tt.conn.rule(); got != tt.wantRule {
+				t.Errorf("rule() = %q, want %q", got, tt.wantRule)
+			}
+			if got := tt.conn.outbound(); got != tt.wantOutbound {
+				t.Errorf("outbound() = %q, want %q", got, tt.wantOutbound)
+			}
+		})
+	}
+}
+
+func TestClashAPI_Secret(t *testing.T) {
+	server := newFakeClashServer(t, "s3cret", []clashConnection{testConnection("1", "example.com", 0)})
+
+	api, err := newClashAPI(server.listen(), "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	conns, err := api.Connections(context.Background())
+	if err != nil {
+		t.Fatalf("Connections() unexpected error: %v", err)
+	}
+	if len(conns.Connections) != 1 || conns.Connections[0].Metadata.Host != "example.com" {
+		t.Errorf("Connections() = %+v", conns)
+	}
+
+	api.secret = "wrong"
+	if _, err := api.Connections(context.Background()); err == nil {
+		t.Error("Connections() should fail with the wrong secret")
+	}
+}
+
+func TestShowConnections(t *testing.T) {
+	server := newFakeClashServer(t, "s3cret", []clashConnection{
+		testConnection("old", "old.example.com", time.Minute),
+		testConnection("new", "new.example.com", time.Second),
+		testConnection("mid", "mid.example.com", 10*time.Second),
+	})
+
+	tui := newTestTUI(t)
+	tui.isConnected, tui.clientType = true, "singbox"
+	tui.configs.Settings.StatsAPI = server.listen()
+	tui.apiSecret = server.secret
+	runTestApp(t, tui)
+
+	tui.app.QueueUpdate(tui.showConnections)
+	rows := func() []string {
+		var hosts []string
+		tui.app.QueueUpdate(func() {
+			for row := 1; row < tui.connectionsTable.GetRowCount(); row++ {
+				hosts = append(hosts, tui.connectionsTable.GetCell(row, 0).Text)
+			}
+		})
+		return hosts
+	}
+	waitFor(t, "the connections table", func() bool { return len(rows()) == 3 })
+	if got := strings.Join(rows(), ","); got != "new.example.com:443,mid.example.com:443,old.example.com:443" {
+		t.Errorf("rows = %s, want newest first", got)
+	}
+
+	tui.app.QueueUpdate(func() {
+		tui.connectionsTable.Select(2, 0)
+		tui.closeSelectedConnection()
+	})
+	waitFor(t, "the connection to close", func() bool { return len(rows()) == 2 })
+	if server.count() != 2 {
+		t.Errorf("server has %d connections after closing one, want 2", server.count())
+	}
+	if got := strings.Join(rows(), ","); got != "new.example.com:443,old.example.com:443" {
+		t.Errorf("rows after closing = %s", got)
+	}
+
+	tui.app.QueueUpdate(tui.hideConnections)
+	var cancel func()
+	tui.app.QueueUpdate(func() { cancel = tui.connectionsCancel })
+	if cancel != nil {
+		t.Error("hideConnections() should stop the refresh")
+	}
+}
+
+func TestShowConnections_Unavailable(t *testing.T) {
+	tests := []struct {
+		name       string
+		connected  bool
+		clientType string
+		statsAPI   string
+	}{
+		{name: "not connected"},
+		{name: "V2Ray client", connected: true, clientType: "v2ray"},
+		{name: "API off", connected: true, clientType: "mihomo", statsAPI: statsOff},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := newTestTUI(t)
+			tui.isConnected, tui.clientType = tt.connected, tt.clientType
+			tui.configs.Settings.StatsAPI = tt.statsAPI
+
+			tui.showConnections()
+			if !strings.HasPrefix(tui.statusText.GetText(true), "Error:") {
+				t.Errorf("status = %q, want an error", tui.statusText.GetText(true))
+			}
+			if tui.connectionsCancel != nil {
+				t.Error("showConnections() should not start refreshing")
+			}
+		})
+	}
+}
@@ -1,136 +1,80 @@
 package tui
 
 import (
-	"context"
 	"fmt"
-	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/gdamore/tcell/v2"
 )
 
-// disconnect stops the current connection by killing processes on port 1080 (PID method only)
+// disconnect stops the client process started by this application: SIGTERM first,
+// SIGKILL if it has not exited within the stop timeout
 func (tui *TUI) disconnect() {
-	go func() {
-		var (
-			logs        []string
-			finalStatus string
-			finalColor  tcell.Color
-		)
+	port := tui.proxyPort()
 
-		logs = append(logs, "Checking port 1080 status...")
+	go func() {
+		var logs []string
 
-		// If port is free — nothing to do
-		if !tui.isPort1080InUse() {
-			tui.handleNoPortUse(&logs, &finalStatus, &finalColor)
+		proc := tui.supervisor.Running()
+		if proc == nil && tui.supervisor.Restarting() {
+			clientType := tui.clientType
+			tui.supervisor.Stop()
+			tui.resetConnectionState()
+			logs = append(logs, fmt.Sprintf("Cancelled the scheduled restart of %s.", clientType))
+			tui.updateDisconnectUI(logs, fmt.Sprintf("Disconnected from %s (restart cancelled)", clientType), tcell.ColorGreen)
 			return
 		}
-
-		logs = append(logs, "Attempting to disconnect using PID method...")
-		pids := tui.getPIDsOnPort1080()
-
-		if len(pids) == 0 {
-			tui.handleNoProcessesFound(&logs, &finalStatus, &finalColor)
+		if proc == nil {
+			logs = append(logs, "No client process started by this application is running.")
+			finalStatus := "No client is running - nothing to disconnect"
+			if tui.isProxyPortInUse() {
+				logs = append(logs, fmt.Sprintf("Port %d is used by another program, which is left untouched.", port))
+				finalStatus = fmt.Sprintf("No client is running - port %d is used by another program", port)
+			}
+			tui.resetConnectionState()
+			tui.updateDisconnectUI(logs, finalStatus, tcell.ColorYellow)
 			return
 		}
 
-		logs = append(logs, fmt.Sprintf("Found %d process(es) on port 1080. Attempting to kill...", len(pids)))
-		success, pidLogs := tui.killProcessesByPID(context.Background(), pids)
-		logs = append(logs, pidLogs...)
+		clientType := tui.clientType
+		logs = append(logs, fmt.Sprintf("Stopping %s (PID %d)...", clientType, proc.PID()))
+
+		killed, err := tui.supervisor.Stop()
+		switch {
+		case err != nil:
+			logs = append(logs, fmt.Sprintf("Process already exited: %v", err))
+		case killed:
+			logs = append(logs, fmt.Sprintf("Process did not exit after SIGTERM within %s and was killed.", tui.supervisor.stopTimeout))
+		default:
+			logs = append(logs, "Process exited after SIGTERM.")
+		}
 
-		if success {
-			clientType := tui.clientType
-			tui.resetConnectionState()
-			finalStatus = fmt.Sprintf("Disconnected from %s (port 1080 freed)", clientType)
-			finalColor = tcell.ColorGreen
-		} else {
-			finalStatus = "Failed to free port 1080 - check logs"
-			finalColor = tcell.ColorRed
+		tui.resetConnectionState()
+		finalStatus := fmt.Sprintf("Disconnected from %s", clientType)
+		finalColor := tcell.ColorGreen
+		if tui.isProxyPortInUse() {
+			logs = append(logs, fmt.Sprintf("Port %d is still in use by another program.", port))
+			finalStatus = fmt.Sprintf("Disconnected from %s (port %d is still used by another program)", clientType, port)
+			finalColor = tcell.ColorYellow
 		}
 
 		tui.updateDisconnectUI(logs, finalStatus, finalColor)
 	}()
 }
 
-// killProcessesByPID safely kills processes by their PIDs with context (no UI calls here)
-func (tui *TUI) killProcessesByPID(ctx context.Context, pids []string) (bool, []string) {
-	var pidLogs []string
-	successCount := 0
-
-	for _, pid := range pids {
-		pid = strings.TrimSpace(pid)
-		if pid == "" {
-			continue
-		}
-
-		killCmd := exec.CommandContext(ctx, "kill", pid)
-		err := killCmd.Run()
-
-		if err == nil {
-			successCount++
-			pidLogs = append(pidLogs, fmt.Sprintf("Successfully killed process %s", pid))
-		} else {
-			pidLogs = append(pidLogs, fmt.Sprintf("Failed to kill process %s: %v", pid, err))
-		}
-	}
-
-	time.Sleep(1 * time.Second)
-	return !tui.isPort1080InUse(), pidLogs
-}
-
-// getPIDsOnPort1080 returns the PIDs of processes using port 1080
-func (tui *TUI) getPIDsOnPort1080() []string {
-	var pids []string
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "lsof", "-tiTCP:1080", "-sTCP:LISTEN")
-	output, err := cmd.Output()
-	if err != nil {
-		return pids
-	}
-
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, line := range lines {
-		if line = strings.TrimSpace(line); line != "" {
-			pids = append(pids, line)
-		}
-	}
-
-	return pids
-}
-
-func (tui *TUI) handleNoPortUse(logs *[]string, finalStatus *string, finalColor *tcell.Color) {
-	*logs = append(*logs, "Port 1080 is not in use.\nNo active connections found.")
-	tui.resetConnectionState()
-	*finalStatus = "Port 1080 is not in use - nothing to disconnect"
-	*finalColor = tcell.ColorYellow
-	tui.updateDisconnectUI(*logs, *finalStatus, *finalColor)
-}
-
-// handleNoProcessesFound handles the "no PIDs found" case
-func (tui *TUI) handleNoProcessesFound(logs *[]string, finalStatus *string, finalColor *tcell.Color) {
-	*logs = append(*logs, "No processes found using port 1080.")
-	*finalStatus = "No processes to kill"
-	*finalColor = tcell.ColorYellow
-	tui.updateDisconnectUI(*logs, *finalStatus, *finalColor)
-}
-
 func (tui *TUI) resetConnectionState() {
+	tui.restoreSystemProxy()
 	tui.isConnected = false
 	tui.clientType = ""
 	tui.connectedConfig = ""
+	tui.restarts = 0
 }
 
 func (tui *TUI) updateDisconnectUI(logs []string, status string, color tcell.Color) {
 	tui.app.QueueUpdateDraw(func() {
+		tui.stopTrafficPolling()
 		tui.configText.SetText(strings.Join(logs, "\n"))
 		tui.updateStatus(status, color)
-		if !tui.isConnected {
-			tui.connectionStatus.SetText("Status: Not Connected (Port 1080 Free)").
-				SetTextColor(tcell.ColorRed)
-		}
+		tui.updateConnectionStatus()
 	})
 }
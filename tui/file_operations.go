@@ -22,6 +22,8 @@ func (tui *TUI) exportConfig() {
 // showFileDialog switches to file explorer for export location
 func (tui *TUI) showFileDialog(configText string) {
 	// TODO: implement actual file picker selection, currently just shows explorer
+	tui.fileSelectHandler = nil
+	tui.loadDirectory(tui.currentPath)
 	tui.app.SetRoot(tui.fileExplorer, true)
 }
 
@@ -80,10 +82,21 @@ func (tui *TUI) loadDirectory(path string) {
 			fileName := file.Name()
 			label := "📄 " + fileName
 			desc := "File"
-			if strings.HasSuffix(fileName, ".json") {
+			switch {
+			case strings.HasSuffix(fileName, ".json"):
 				desc = "JSON file"
+			case strings.HasSuffix(fileName, ".yaml"), strings.HasSuffix(fileName, ".yml"):
+				desc = "YAML file"
 			}
-			tui.fileList.AddItem(label, desc, 0, nil)
+
+			var selected func()
+			if tui.fileSelectHandler != nil {
+				filePath := filepath.Join(path, fileName)
+				selected = func() {
+					tui.fileSelectHandler(filePath)
+				}
+			}
+			tui.fileList.AddItem(label, desc, 0, selected)
 		}
 	}
 }
@@ -0,0 +1,360 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	groupName      = "Name"
+	groupType      = "Type"
+	groupURL       = "Health check URL"
+	groupInterval  = "Check interval (seconds)"
+	groupTolerance = "Tolerance (ms, urltest)"
+)
+
+// addGroup validates and stores a new group over the given configuration IDs
+func (tui *TUI) addGroup(name string, members []string, policy parser.Group) (ConfigGroup, error) {
+	if len(members) < 2 {
+		return ConfigGroup{}, fmt.Errorf("select at least two configurations")
+	}
+	for _, id := range members {
+		index := tui.findConfig(id)
+		if index < 0 {
+			return ConfigGroup{}, fmt.Errorf("configuration %s not found", id)
+		}
+		if config := tui.configs.Configurations[index]; config.Protocol == protocolChain {
+			return ConfigGroup{}, fmt.Errorf("chain %s cannot be a group member", config.Name)
+		}
+	}
+	if err := policy.Validate(); err != nil {
+		return ConfigGroup{}, err
+	}
+
+	group := ConfigGroup{
+		ID:      tui.nextGroupID(),
+		Name:    strings.TrimSpace(name),
+		Members: members,
+		Policy:  policy,
+	}
+	if group.Name == "" {
+		group.Name = fmt.Sprintf("Group %d", len(tui.configs.Groups)+1)
+	}
+	tui.configs.Groups = append(tui.configs.Groups, group)
+
+	return group, tui.saveConfigsToFile()
+}
+
+// deleteGroup removes a group, its configurations are kept
+func (tui *TUI) deleteGroup(groupID string) (ConfigGroup, error) {
+	for i, group := range tui.configs.Groups {
+		if group.ID == groupID {
+			tui.configs.Groups = append(tui.configs.Groups[:i], tui.configs.Groups[i+1:]...)
+			return group, tui.saveConfigsToFile()
+		}
+	}
+	return ConfigGroup{}, fmt.Errorf("group not found")
+}
+
+// groupMembers returns the group's configurations that still exist
+func (tui *TUI) groupMembers(group ConfigGroup) []Config {
+	var members []Config
+	for _, id := range group.Members {
+		if index := tui.findConfig(id); index >= 0 {
+			members = append(members, tui.configs.Configurations[index])
+		}
+	}
+	return members
+}
+
+// findConfig returns the index of the configuration with the given ID, -1 if missing
+func (tui *TUI) findConfig(configID string) int {
+	for i, config := range tui.configs.Configurations {
+		if config.ID == configID {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextGroupID returns an ID not used by any group
+func (tui *TUI) nextGroupID() string {
+	maxID := 0
+	for _, group := range tui.configs.Groups {
+		if id, err := strconv.Atoi(strings.TrimPrefix(group.ID, "group-")); err == nil && id > maxID {
+			maxID = id
+		}
+	}
+	return fmt.Sprintf("group-%d", maxID+1)
+}
+
+// renderGroup renders the group's members for target with the current settings
+// and the default routing
+func (tui *TUI) renderGroup(group ConfigGroup, target parser.Target) (map[string]any, error) {
+	members := tui.groupMembers(group)
+	if len(members) == 0 {
+		return nil, fmt.Errorf("group %s has no configurations left", group.Name)
+	}
+
+	var nodes []*parser.ProxyNode
+	for _, config := range members {
+		node, err := parser.Parse(config.Link)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", config.Name, err)
+		}
+		nodes = append(nodes, node)
+	}
+
+	opts := tui.renderOptions(Config{})
+	opts.Group = group.Policy
+	return parser.RenderGroup(nodes, target, opts)
+}
+
+// groupClientButtons returns the client choices that can run every member of the group
+func (tui *TUI) groupClientButtons(group ConfigGroup) []string {
+	var protocols []string
+	var nodes []*parser.ProxyNode
+	for _, config := range tui.groupMembers(group) {
+		protocols = append(protocols, config.Protocol)
+		nodes = append(nodes, tui.configNodes(config)...)
+	}
+
+	var buttons []string
+	for _, label := range []string{"V2Ray", "Xray", "SingBox", "Mihomo"} {
+		target := clientLaunches[label].target
+		// V2Ray and Xray balancers cannot be switched by hand
+		if group.Policy.Type == parser.GroupSelector && (target == parser.TargetV2Ray || target == parser.TargetXray) {
+			continue
+		}
+		if supportsAll(protocols, target) {
+			buttons = append(buttons, label)
+		}
+	}
+	return supportedButtons(append(buttons, "Cancel"), nodes)
+}
+
+// connectGroup writes the group's config for the chosen client and starts it
+func (tui *TUI) connectGroup(group ConfigGroup, label string) {
+	launch, ok := clientLaunches[label]
+	if !ok {
+		return
+	}
+
+	rendered, err := tui.renderGroup(group, launch.target)
+	if err != nil {
+		tui.updateStatus(fmt.Sprintf("Error rendering group: %v", err), tcell.ColorRed)
+		return
+	}
+	data, err := marshalClientConfig(launch.configFile, rendered)
+	if err != nil {
+		tui.updateStatus(fmt.Sprintf("Error marshaling config: %v", err), tcell.ColorRed)
+		return
+	}
+	if err := os.WriteFile(launch.configFile, data, 0644); err != nil {
+		tui.updateStatus(fmt.Sprintf("Error saving config: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.updateStatus(fmt.Sprintf("Starting %s with group: %s (%d servers, %s)...", launch.clientType, group.Name, len(tui.groupMembers(group)), group.Policy.WithDefaults().Type), tcell.ColorBlue)
+	tui.configText.SetText(fmt.Sprintf("Starting %s...\nClient output is shown in the log viewer (Ctrl+Y)\n", launch.clientType))
+
+	go tui.startClientProcess(launch.clientType, group.Name, launch.command)
+}
+
+// showGroups switches to the group management screen
+func (tui *TUI) showGroups() {
+	tui.groupMarks = make(map[string]bool)
+	tui.refreshGroupList()
+	tui.refreshGroupMemberList()
+	tui.app.SetRoot(tui.groupView, true)
+	tui.app.SetFocus(tui.groupMemberList)
+}
+
+// createGroupView builds the group management screen
+func (tui *TUI) createGroupView() *tview.Flex {
+	tui.groupList = tview.NewList()
+	tui.groupList.SetBorder(true)
+	tui.groupList.SetTitle(" Groups ")
+
+	tui.groupMemberList = tview.NewList()
+	tui.groupMemberList.SetBorder(true)
+	tui.groupMemberList.SetTitle(" Members (Enter toggles) ")
+
+	tui.groupStatus = tview.NewTextView()
+	tui.groupStatus.SetText("Mark at least two configurations and press Add")
+	tui.groupStatus.SetTextAlign(tview.AlignCenter)
+	tui.groupStatus.SetBorder(true)
+	tui.groupStatus.SetTitle(" Status ")
+
+	tui.groupForm = tview.NewForm().
+		AddInputField(groupName, "", 30, nil, nil).
+		AddDropDown(groupType, parser.GroupTypes, 0, nil).
+		AddInputField(groupURL, parser.DefaultGroupURL, 40, nil, nil).
+		AddInputField(groupInterval, strconv.Itoa(parser.DefaultGroupInterval), 6, tview.InputFieldInteger, nil).
+		AddInputField(groupTolerance, strconv.Itoa(parser.DefaultGroupTolerance), 6, tview.InputFieldInteger, nil)
+
+	tui.groupForm.
+		AddButton("Add", func() {
+			tui.addGroupFromForm()
+		}).
+		AddButton("Connect", func() {
+			tui.connectSelectedGroup()
+		}).
+		AddButton("Delete", func() {
+			tui.deleteSelectedGroup()
+		}).
+		AddButton("Back", func() {
+			tui.app.SetRoot(tui.mainFlex, true)
+		})
+	tui.groupForm.SetBorder(true)
+	tui.groupForm.SetTitle(" New Group ")
+
+	content := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(tui.groupList, 0, 1, false).
+		AddItem(tui.groupMemberList, 0, 1, true).
+		AddItem(tui.groupForm, 0, 1, false)
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(content, 0, 1, true).
+		AddItem(tui.groupStatus, 3, 0, false)
+}
+
+// toggleGroupMember marks or unmarks the configuration at index for the new group
+func (tui *TUI) toggleGroupMember(index int) {
+	if index < 0 || index >= len(tui.configs.Configurations) {
+		return
+	}
+	id := tui.configs.Configurations[index].ID
+	tui.groupMarks[id] = !tui.groupMarks[id]
+	tui.refreshGroupMemberList()
+	tui.groupMemberList.SetCurrentItem(index)
+}
+
+// addGroupFromForm stores a group over the marked configurations
+func (tui *TUI) addGroupFromForm() {
+	var members []string
+	for _, config := range tui.configs.Configurations {
+		if tui.groupMarks[config.ID] {
+			members = append(members, config.ID)
+		}
+	}
+
+	policy := parser.Group{URL: strings.TrimSpace(tui.groupInput(groupURL).GetText())}
+	_, policy.Type = tui.groupForm.GetFormItemByLabel(groupType).(*tview.DropDown).GetCurrentOption()
+	var err error
+	if policy.Interval, err = atoiField(strings.TrimSpace(tui.groupInput(groupInterval).GetText())); err != nil {
+		tui.setGroupStatus("Error: Check interval must be a number", tcell.ColorRed)
+		return
+	}
+	if policy.Tolerance, err = atoiField(strings.TrimSpace(tui.groupInput(groupTolerance).GetText())); err != nil {
+		tui.setGroupStatus("Error: Tolerance must be a number", tcell.ColorRed)
+		return
+	}
+
+	group, err := tui.addGroup(tui.groupInput(groupName).GetText(), members, policy)
+	if err != nil {
+		tui.setGroupStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.groupMarks = make(map[string]bool)
+	tui.groupInput(groupName).SetText("")
+	tui.refreshGroupList()
+	tui.refreshGroupMemberList()
+	tui.setGroupStatus(fmt.Sprintf("Group '%s' with %d configurations saved", group.Name, len(group.Members)), tcell.ColorGreen)
+}
+
+// connectSelectedGroup asks for a client and connects the group highlighted in the list
+func (tui *TUI) connectSelectedGroup() {
+	group, ok := tui.selectedGroup()
+	if !ok {
+		return
+	}
+
+	clientModal := tview.NewModal().
+		SetText(fmt.Sprintf("Choose client for group: %s (%s)", group.Name, group.Policy.WithDefaults().Type)).
+		AddButtons(tui.groupClientButtons(group)).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			tui.app.SetRoot(tui.mainFlex, true)
+			tui.connectGroup(group, buttonLabel)
+		})
+
+	tui.app.SetRoot(clientModal, true)
+}
+
+// deleteSelectedGroup removes the group highlighted in the list
+func (tui *TUI) deleteSelectedGroup() {
+	group, ok := tui.selectedGroup()
+	if !ok {
+		return
+	}
+
+	if _, err := tui.deleteGroup(group.ID); err != nil {
+		tui.setGroupStatus(fmt.Sprintf("Error deleting group: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.refreshGroupList()
+	tui.setGroupStatus(fmt.Sprintf("Group '%s' deleted", group.Name), tcell.ColorGreen)
+}
+
+// selectedGroup returns the group highlighted in the list
+func (tui *TUI) selectedGroup() (ConfigGroup, bool) {
+	index := tui.groupList.GetCurrentItem()
+	if index < 0 || index >= len(tui.configs.Groups) {
+		tui.setGroupStatus("Please select a group first", tcell.ColorYellow)
+		return ConfigGroup{}, false
+	}
+	return tui.configs.Groups[index], true
+}
+
+// refreshGroupList redraws the group list
+func (tui *TUI) refreshGroupList() {
+	tui.groupList.Clear()
+	if len(tui.configs.Groups) == 0 {
+		tui.groupList.AddItem("No groups yet", "Mark configurations and press Add", 0, nil)
+		return
+	}
+
+	for _, group := range tui.configs.Groups {
+		var names []string
+		for _, config := range tui.groupMembers(group) {
+			names = append(names, config.Name)
+		}
+		secondaryText := fmt.Sprintf("%s | %s", group.Policy.WithDefaults().Type, strings.Join(names, ", "))
+		tui.groupList.AddItem(tview.Escape(group.Name), tview.Escape(secondaryText), 0, nil)
+	}
+}
+
+// refreshGroupMemberList redraws the configurations with their marks
+func (tui *TUI) refreshGroupMemberList() {
+	tui.groupMemberList.Clear()
+	for i, config := range tui.configs.Configurations {
+		index := i
+		mark := "[ ]"
+		if tui.groupMarks[config.ID] {
+			mark = "[x]"
+		}
+		tui.groupMemberList.AddItem(tview.Escape(fmt.Sprintf("%s %s (%s)", mark, config.Name, config.Protocol)), "", 0, func() {
+			tui.toggleGroupMember(index)
+		})
+	}
+	tui.groupMemberList.ShowSecondaryText(false)
+}
+
+// setGroupStatus shows a message on the group screen
+func (tui *TUI) setGroupStatus(message string, color tcell.Color) {
+	tui.groupStatus.SetText(message).SetTextColor(color)
+}
+
+// groupInput returns an input field of the group form by label
+func (tui *TUI) groupInput(label string) *tview.InputField {
+	return tui.groupForm.GetFormItemByLabel(label).(*tview.InputField)
+}
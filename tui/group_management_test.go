@@ -0,0 +1,146 @@
+package tui
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"tui_proxy_client/parser"
+)
+
+// groupTestTUI creates a TUI with three saved configurations
+func groupTestTUI(t *testing.T) *TUI {
+	t.Helper()
+	tui := newTestTUI(t)
+	now := "2024-01-01T00:00:00Z"
+	tui.configs.Configurations = []Config{
+		{ID: "1", Name: "VMess", Protocol: "vmess", Link: testVMessLink, CreatedAt: now, LastUsed: now},
+		{ID: "2", Name: "SS", Protocol: "shadowsocks", Link: testSSLink, CreatedAt: now, LastUsed: now},
+		{ID: "3", Name: "Hysteria", Protocol: "hysteria2", Link: "hysteria2://secret@hy.example.com:443#Hysteria", CreatedAt: now, LastUsed: now},
+	}
+	return tui
+}
+
+func TestAddGroup(t *testing.T) {
+	tests := []struct {
+		name        string
+		members     []string
+		policy      parser.Group
+		expectError bool
+	}{
+		{name: "urltest", members: []string{"1", "2"}},
+		{name: "fallback", members: []string{"1", "2", "3"}, policy: parser.Group{Type: parser.GroupFallback}},
+		{name: "single member", members: []string{"1"}, expectError: true},
+		{name: "missing member", members: []string{"1", "9"}, expectError: true},
+		{name: "invalid policy", members: []string{"1", "2"}, policy: parser.Group{URL: "example.com"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := groupTestTUI(t)
+			group, err := tui.addGroup("", tt.members, tt.policy)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("addGroup() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				if len(tui.configs.Groups) != 0 {
+					t.Errorf("failed addGroup() stored %v", tui.configs.Groups)
+				}
+				return
+			}
+			if group.ID != "group-1" || group.Name != "Group 1" {
+				t.Errorf("addGroup() = %+v, want ID group-1 and name Group 1", group)
+			}
+			if _, err := os.Stat("configs.json"); err != nil {
+				t.Errorf("group was not saved: %v", err)
+			}
+		})
+	}
+}
+
+func TestRenderGroup_SkipsDeletedMembers(t *testing.T) {
+	tui := groupTestTUI(t)
+	group, err := tui.addGroup("Mixed", []string{"1", "2"}, parser.Group{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rendered, err := tui.renderGroup(group, parser.TargetSingBox)
+	if err != nil {
+		t.Fatalf("renderGroup() unexpected error: %v", err)
+	}
+	outbounds := rendered["outbounds"].([]map[string]any)
+	if outbounds[0]["type"] != "urltest" || !reflect.DeepEqual(outbounds[0]["outbounds"], []string{"proxy-1", "proxy-2"}) {
+		t.Errorf("group outbound = %v", outbounds[0])
+	}
+
+	tui.configs.Configurations = tui.configs.Configurations[1:]
+	rendered, err = tui.renderGroup(group, parser.TargetSingBox)
+	if err != nil {
+		t.Fatalf("renderGroup() unexpected error: %v", err)
+	}
+	outbounds = rendered["outbounds"].([]map[string]any)
+	if !reflect.DeepEqual(outbounds[0]["outbounds"], []string{"proxy-1"}) || outbounds[1]["type"] != "shadowsocks" {
+		t.Errorf("outbounds = %v, want only the remaining member", outbounds)
+	}
+
+	tui.configs.Configurations = nil
+	if _, err := tui.renderGroup(group, parser.TargetSingBox); err == nil {
+		t.Error("renderGroup() should fail once every member is deleted")
+	}
+}
+
+func TestGroupClientButtons(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		policy  parser.Group
+		want    []string
+	}{
+		{name: "all clients", members: []string{"1", "2"}, want: []string{"V2Ray", "Xray", "SingBox", "Mihomo", "Cancel"}},
+		{name: "selector", members: []string{"1", "2"}, policy: parser.Group{Type: parser.GroupSelector}, want: []string{"SingBox", "Mihomo", "Cancel"}},
+		{name: "hysteria2 member", members: []string{"1", "3"}, want: []string{"SingBox", "Mihomo", "Cancel"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := groupTestTUI(t)
+			group := ConfigGroup{Members: tt.members, Policy: tt.policy}
+			if got := tui.groupClientButtons(group); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groupClientButtons() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddGroupFromForm(t *testing.T) {
+	tui := groupTestTUI(t)
+	tui.showGroups()
+
+	tui.toggleGroupMember(0)
+	tui.addGroupFromForm()
+	if len(tui.configs.Groups) != 0 {
+		t.Fatal("a group with one member should be rejected")
+	}
+
+	tui.toggleGroupMember(2)
+	tui.groupInput(groupName).SetText("Failover")
+	tui.groupInput(groupInterval).SetText("60")
+	tui.addGroupFromForm()
+
+	if len(tui.configs.Groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(tui.configs.Groups))
+	}
+	group := tui.configs.Groups[0]
+	if group.Name != "Failover" || !reflect.DeepEqual(group.Members, []string{"1", "3"}) || group.Policy.Interval != 60 {
+		t.Errorf("group = %+v", group)
+	}
+	if len(tui.groupMarks) != 0 {
+		t.Errorf("marks = %v, want them cleared after adding", tui.groupMarks)
+	}
+
+	tui.deleteSelectedGroup()
+	if len(tui.configs.Groups) != 0 {
+		t.Errorf("group was not deleted: %v", tui.configs.Groups)
+	}
+}
@@ -0,0 +1,278 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// importSummary reports the outcome of importing several links at once
+type importSummary struct {
+	Added      int
+	Duplicates int
+	Failures   []string
+	Dropped    []string // "label: settings" for added entries whose link lost settings
+}
+
+// String formats the summary as a single status line
+func (s importSummary) String() string {
+	return fmt.Sprintf("Import finished: %d added, %d duplicate(s) skipped, %d failed", s.Added, s.Duplicates, len(s.Failures))
+}
+
+// importCandidate is a share link waiting to be saved as a config
+type importCandidate struct {
+	Link  string
+	Name  string // empty falls back to the link remark
+	Label string // identifies the entry in failure messages
+
+	Dropped []string // settings of the imported entry the link cannot carry
+}
+
+// importLinks adds every link of a newline separated list or base64 blob as a saved config
+func (tui *TUI) importLinks(blob string) (importSummary, error) {
+	links, err := parser.SplitLinks(blob)
+	if err != nil {
+		return importSummary{}, err
+	}
+
+	candidates := make([]importCandidate, 0, len(links))
+	for i, link := range links {
+		candidates = append(candidates, importCandidate{Link: link, Label: fmt.Sprintf("link %d", i+1)})
+	}
+
+	return tui.importCandidates(candidates, importSummary{})
+}
+
+// importConfigFile adds every supported proxy of a client config file as a saved config.
+// .json files are read as sing-box / V2Ray / Xray configs, anything else as Clash / Mihomo YAML.
+func (tui *TUI) importConfigFile(path string) (importSummary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return importSummary{}, err
+	}
+
+	var result *parser.ImportResult
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		result, err = parser.ParseJSONConfig(data)
+	} else {
+		result, err = parser.ParseClash(data)
+	}
+	if err != nil {
+		return importSummary{}, err
+	}
+
+	summary := importSummary{Failures: append([]string(nil), result.Skipped...)}
+	candidates := make([]importCandidate, 0, len(result.Nodes))
+	for i, node := range result.Nodes {
+		label := node.Remark
+		if label == "" {
+			label = fmt.Sprintf("entry %d", i+1)
+		}
+
+		// Configurations are stored as share links, so file entries go through the link encoder
+		link, err := parser.Encode(node)
+		if err != nil {
+			summary.Failures = append(summary.Failures, fmt.Sprintf("%s: %v", label, err))
+			continue
+		}
+		candidates = append(candidates, importCandidate{Link: link, Name: node.Remark, Label: label, Dropped: parser.Unencoded(node)})
+	}
+
+	return tui.importCandidates(candidates, summary)
+}
+
+// importCandidates validates and saves candidates, skipping links that are already stored
+func (tui *TUI) importCandidates(candidates []importCandidate, summary importSummary) (importSummary, error) {
+	saved := make(map[string]bool, len(tui.configs.Configurations))
+	for _, config := range tui.configs.Configurations {
+		saved[config.Link] = true
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	for _, candidate := range candidates {
+		link := candidate.Link
+		if saved[link] {
+			summary.Duplicates++
+			continue
+		}
+
+		protocol, _, err := parseLinkForSingBox(link)
+		if errors.Is(err, errUnsupportedLink) {
+			summary.Failures = append(summary.Failures, fmt.Sprintf("%s: unsupported link format", candidate.Label))
+			continue
+		}
+		if err != nil {
+			summary.Failures = append(summary.Failures, fmt.Sprintf("%s (%s): %v", candidate.Label, protocol, err))
+			continue
+		}
+
+		name := strings.TrimSpace(candidate.Name)
+		if name == "" {
+			name = linkRemark(link)
+		}
+		if name == "" {
+			name = fmt.Sprintf("Config %d", len(tui.configs.Configurations)+1)
+		}
+
+		tui.configs.Configurations = append(tui.configs.Configurations, Config{
+			ID:        tui.nextConfigID(),
+			Name:      name,
+			Protocol:  protocol,
+			Link:      link,
+			CreatedAt: now,
+			LastUsed:  now,
+		})
+		saved[link] = true
+		summary.Added++
+		if len(candidate.Dropped) > 0 {
+			summary.Dropped = append(summary.Dropped, fmt.Sprintf("%s: %s", candidate.Label, strings.Join(candidate.Dropped, ", ")))
+		}
+	}
+
+	if summary.Added > 0 {
+		if err := tui.saveConfigsToFile(); err != nil {
+			return summary, fmt.Errorf("error saving configs: %w", err)
+		}
+	}
+
+	return summary, nil
+}
+
+// linkRemark returns the name embedded in a share link, if any
+func linkRemark(link string) string {
+	node, err := parser.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(node.Remark)
+}
+
+// isMultiLinkInput reports whether input holds more than a single share link
+func isMultiLinkInput(input string) bool {
+	input = strings.TrimSpace(input)
+	if strings.ContainsAny(input, "\r\n") {
+		return true
+	}
+	// A single line without a scheme can only be a base64 blob
+	return input != "" && !strings.Contains(input, "://")
+}
+
+// runImport imports a blob of links and reports the result in the main UI
+func (tui *TUI) runImport(blob string) {
+	summary, err := tui.importLinks(blob)
+	tui.reportImport(summary, err)
+}
+
+// runFileImport imports a client config file and reports the result in the main UI
+func (tui *TUI) runFileImport(path string) {
+	tui.app.SetRoot(tui.mainFlex, true)
+	summary, err := tui.importConfigFile(path)
+	tui.reportImport(summary, err)
+}
+
+// reportImport shows an import summary in the status bar and log pane
+func (tui *TUI) reportImport(summary importSummary, err error) {
+	if err != nil && summary.Added == 0 {
+		tui.updateStatus(fmt.Sprintf("Error importing links: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.refreshConfigList()
+	tui.configText.SetText(formatImportSummary(summary))
+
+	color := tcell.ColorGreen
+	if len(summary.Failures) > 0 || len(summary.Dropped) > 0 {
+		color = tcell.ColorYellow
+	}
+	tui.updateStatus(summary.String(), color)
+}
+
+// formatImportSummary renders the summary and failure reasons for the log pane
+func formatImportSummary(summary importSummary) string {
+	var b strings.Builder
+	b.WriteString(summary.String())
+	if len(summary.Failures) > 0 {
+		b.WriteString("\n\nFailed entries:\n")
+		for _, failure := range summary.Failures {
+			b.WriteString("  - " + failure + "\n")
+		}
+	}
+	if len(summary.Dropped) > 0 {
+		b.WriteString("\n\nSettings share links cannot carry, not imported:\n")
+		for _, dropped := range summary.Dropped {
+			b.WriteString("  - " + dropped + "\n")
+		}
+	}
+	return b.String()
+}
+
+// showBulkImport opens the bulk import screen
+func (tui *TUI) showBulkImport() {
+	textArea := tview.NewTextArea()
+	textArea.SetPlaceholder("Paste one link per line, or a base64 blob as served by subscriptions")
+	textArea.SetBorder(true)
+	textArea.SetTitle(" Bulk Import ")
+	if input := tui.vmessInput.GetText(); isMultiLinkInput(input) {
+		textArea.SetText(input, true)
+	}
+
+	// Ctrl+X, Ctrl+V and friends edit the text here instead of running shortcuts
+	tui.shortcutsOff = true
+	leave := func() {
+		tui.shortcutsOff = false
+		tui.app.SetRoot(tui.mainFlex, true)
+	}
+
+	importBtn := tview.NewButton("Import").SetSelectedFunc(func() {
+		leave()
+		tui.runImport(textArea.GetText())
+	})
+
+	backBtn := tview.NewButton("Back to Main").SetSelectedFunc(leave)
+
+	// Tab and Esc leave the text for the buttons, which Tab cycles through
+	textArea.SetFinishedFunc(func(key tcell.Key) {
+		tui.app.SetFocus(importBtn)
+	})
+	importBtn.SetExitFunc(func(key tcell.Key) {
+		if key == tcell.KeyTab {
+			tui.app.SetFocus(backBtn)
+			return
+		}
+		tui.app.SetFocus(textArea)
+	})
+	backBtn.SetExitFunc(func(key tcell.Key) {
+		if key == tcell.KeyBacktab {
+			tui.app.SetFocus(importBtn)
+			return
+		}
+		tui.app.SetFocus(textArea)
+	})
+
+	buttons := tview.NewFlex().
+		AddItem(importBtn, 0, 1, false).
+		AddItem(backBtn, 0, 1, false)
+
+	view := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(textArea, 0, 1, true).
+		AddItem(buttons, 3, 0, false)
+
+	tui.app.SetRoot(view, true)
+	tui.app.SetFocus(textArea)
+}
+
+// showImportFileDialog opens the file explorer to pick a Clash YAML or sing-box / V2Ray JSON config
+func (tui *TUI) showImportFileDialog() {
+	tui.fileSelectHandler = tui.runFileImport
+	tui.loadDirectory(tui.currentPath)
+	tui.app.SetRoot(tui.fileExplorer, true)
+	tui.updateStatus("Select a Clash / Mihomo YAML or sing-box / V2Ray JSON file to import", tcell.ColorBlue)
+}
@@ -0,0 +1,292 @@
+package tui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestTUI_ImportLinks(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.configs.Configurations = []Config{{ID: "1", Name: "Existing", Protocol: "shadowsocks", Link: testSSLink}}
+
+	blob := strings.Join([]string{
+		testVMessLink,
+		testSSLink,
+		"",
+		testVLESSLink,
+		testVLESSLink,
+		"vless://not-a-uuid@example.com:443",
+		"http://example.com",
+	}, "\n")
+
+	summary, err := tui.importLinks(blob)
+	if err != nil {
+		t.Fatalf("importLinks() unexpected error: %v", err)
+	}
+
+	if summary.Added != 2 {
+		t.Errorf("importLinks() added = %d, want 2", summary.Added)
+	}
+	if summary.Duplicates != 2 {
+		t.Errorf("importLinks() duplicates = %d, want 2", summary.Duplicates)
+	}
+	if len(summary.Failures) != 2 {
+		t.Fatalf("importLinks() failures = %v, want 2 entries", summary.Failures)
+	}
+	if !strings.Contains(summary.Failures[0], "vless") || !strings.Contains(summary.Failures[1], "unsupported") {
+		t.Errorf("importLinks() failure reasons = %v", summary.Failures)
+	}
+
+	if len(tui.configs.Configurations) != 3 {
+		t.Fatalf("expected 3 configurations, got %d", len(tui.configs.Configurations))
+	}
+	vless := tui.configs.Configurations[2]
+	if vless.Name != "VLESS Node" || vless.Protocol != "vless" {
+		t.Errorf("importLinks() stored %+v, want VLESS Node (vless)", vless)
+	}
+}
+
+func TestTUI_ImportLinks_Base64(t *testing.T) {
+	tui := newTestTUI(t)
+
+	blob := base64.StdEncoding.EncodeToString([]byte(testVMessLink + "\n" + testSSLink + "\n"))
+	summary, err := tui.importLinks(blob)
+	if err != nil {
+		t.Fatalf("importLinks() unexpected error: %v", err)
+	}
+	if summary.Added != 2 || summary.Duplicates != 0 || len(summary.Failures) != 0 {
+		t.Errorf("importLinks() = %+v, want 2 added", summary)
+	}
+
+	if _, err := tui.importLinks("!!! not links !!!"); err == nil {
+		t.Error("importLinks() expected error for garbage input")
+	}
+}
+
+func TestTUI_AddConfig_MultipleLinks(t *testing.T) {
+	tui := newTestTUI(t)
+
+	tui.vmessInput.SetText(testVMessLink + "\n" + testVLESSLink)
+	tui.addConfig()
+
+	if len(tui.configs.Configurations) != 2 {
+		t.Errorf("addConfig() with two links should add 2 configs, got %d", len(tui.configs.Configurations))
+	}
+	if status := tui.statusText.GetText(true); !strings.Contains(status, "2 added") {
+		t.Errorf("addConfig() status = %q, want import summary", status)
+	}
+}
+
+func TestTUI_ShowBulkImport_Keyboard(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.showBulkImport()
+
+	capture := tui.app.GetInputCapture()
+	ctrlX := tcell.NewEventKey(tcell.KeyCtrlX, 0, tcell.ModCtrl)
+	if capture(ctrlX) != ctrlX {
+		t.Error("Ctrl+X should reach the text area while bulk import is open")
+	}
+
+	// press sends a key to the focused primitive like the application does
+	press := func(key tcell.Key) tview.Primitive {
+		tui.app.GetFocus().InputHandler()(tcell.NewEventKey(key, 0, tcell.ModNone), func(p tview.Primitive) {
+			tui.app.SetFocus(p)
+		})
+		return tui.app.GetFocus()
+	}
+	label := func(p tview.Primitive) string {
+		if button, ok := p.(*tview.Button); ok {
+			return button.GetLabel()
+		}
+		return fmt.Sprintf("%T", p)
+	}
+
+	for _, step := range []struct {
+		key  tcell.Key
+		want string
+	}{
+		{tcell.KeyTab, "Import"},
+		{tcell.KeyTab, "Back to Main"},
+		{tcell.KeyBacktab, "Import"},
+		{tcell.KeyEscape, "*tview.TextArea"},
+		{tcell.KeyEscape, "Import"},
+	} {
+		if got := label(press(step.key)); got != step.want {
+			t.Fatalf("focus after %s = %s, want %s", tcell.KeyNames[step.key], got, step.want)
+		}
+	}
+
+	press(tcell.KeyTab)
+	press(tcell.KeyEnter)
+	if tui.shortcutsOff {
+		t.Error("leaving bulk import should turn the shortcuts back on")
+	}
+	if capture(ctrlX) != nil {
+		t.Error("Ctrl+X should run its shortcut again after leaving bulk import")
+	}
+}
+
+func TestIsMultiLinkInput(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{testVMessLink, false},
+		{"  " + testSSLink + "  ", false},
+		{testVMessLink + "\n" + testSSLink, true},
+		{base64.StdEncoding.EncodeToString([]byte(testSSLink)), true},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if result := isMultiLinkInput(tt.input); result != tt.expected {
+			t.Errorf("isMultiLinkInput(%q) = %v, want %v", tt.input, result, tt.expected)
+		}
+	}
+}
+
+func TestTUI_ImportConfigFile_Clash(t *testing.T) {
+	tui := newTestTUI(t)
+
+	yaml := `proxies:
+  - name: Clash Trojan
+    type: trojan
+    server: trojan.example.com
+    port: 443
+    password: secret
+    sni: trojan.example.com
+  - name: Clash SS
+    type: ss
+    server: ss.example.com
+    port: 8388
+    cipher: aes-256-gcm
+    password: secret
+  - name: Snell
+    type: snell
+    server: snell.example.com
+    port: 443
+`
+	path := filepath.Join(t.TempDir(), "clash.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0644); err != nil {
+		t.Fatalf("failed to write clash config: %v", err)
+	}
+
+	summary, err := tui.importConfigFile(path)
+	if err != nil {
+		t.Fatalf("importConfigFile() unexpected error: %v", err)
+	}
+	if summary.Added != 2 || len(summary.Failures) != 1 || !strings.HasPrefix(summary.Failures[0], "Snell:") {
+		t.Errorf("importConfigFile() = %+v, want 2 added and Snell skipped", summary)
+	}
+
+	if len(tui.configs.Configurations) != 2 {
+		t.Fatalf("expected 2 configurations, got %d", len(tui.configs.Configurations))
+	}
+	trojan := tui.configs.Configurations[0]
+	if trojan.Name != "Clash Trojan" || trojan.Protocol != "trojan" || !strings.HasPrefix(trojan.Link, "trojan://") {
+		t.Errorf("importConfigFile() stored %+v", trojan)
+	}
+
+	summary, err = tui.importConfigFile(path)
+	if err != nil {
+		t.Fatalf("importConfigFile() second run unexpected error: %v", err)
+	}
+	if summary.Added != 0 || summary.Duplicates != 2 {
+		t.Errorf("importConfigFile() second run = %+v, want 2 duplicates", summary)
+	}
+
+	muxYAML := `proxies:
+  - name: Clash Mux
+    type: trojan
+    server: mux.example.com
+    port: 443
+    password: secret
+    smux:
+      enabled: true
+`
+	if err := os.WriteFile(path, []byte(muxYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	summary, err = tui.importConfigFile(path)
+	if err != nil {
+		t.Fatalf("importConfigFile() with smux unexpected error: %v", err)
+	}
+	if summary.Added != 1 || len(summary.Dropped) != 1 || summary.Dropped[0] != "Clash Mux: mux" {
+		t.Errorf("importConfigFile() with smux = %+v, want the mux reported as dropped", summary)
+	}
+	if !strings.Contains(formatImportSummary(summary), "Clash Mux: mux") {
+		t.Errorf("formatImportSummary() = %q, want the dropped mux listed", formatImportSummary(summary))
+	}
+
+	if _, err := tui.importConfigFile(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("importConfigFile() expected error for missing file")
+	}
+}
+
+func TestTUI_LoadDirectory_FileSelection(t *testing.T) {
+	tui := newTestTUI(t)
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "clash.yml"), []byte("proxies: []"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	var picked string
+	tui.fileSelectHandler = func(path string) { picked = path }
+	tui.loadDirectory(dir)
+
+	if tui.fileList.GetItemCount() != 2 {
+		t.Fatalf("loadDirectory() listed %d items, want parent and one file", tui.fileList.GetItemCount())
+	}
+	if _, desc := tui.fileList.GetItemText(1); desc != "YAML file" {
+		t.Errorf("loadDirectory() file description = %q, want YAML file", desc)
+	}
+
+	tui.fileList.SetCurrentItem(1)
+	tui.fileList.InputHandler()(tcell.NewEventKey(tcell.KeyEnter, 0, tcell.ModNone), func(tview.Primitive) {})
+	if picked != filepath.Join(dir, "clash.yml") {
+		t.Errorf("selecting a file called handler with %q", picked)
+	}
+}
+
+func TestTUI_ImportConfigFile_JSON(t *testing.T) {
+	tui := newTestTUI(t)
+
+	config := `{
+  "outbounds": [
+    {"type": "vless", "tag": "Work VLESS", "server": "example.com", "server_port": 443,
+     "uuid": "12345678-1234-1234-1234-123456789012",
+     "transport": {"type": "ws", "path": "/ws", "headers": {"Host": "cdn.example.com"}},
+     "tls": {"enabled": true, "server_name": "cdn.example.com"}},
+    {"type": "wireguard", "tag": "wg"},
+    {"type": "direct", "tag": "direct"}
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	summary, err := tui.importConfigFile(path)
+	if err != nil {
+		t.Fatalf("importConfigFile() unexpected error: %v", err)
+	}
+	if summary.Added != 1 || len(summary.Failures) != 1 || !strings.HasPrefix(summary.Failures[0], "wg:") {
+		t.Errorf("importConfigFile() = %+v, want 1 added and wg skipped", summary)
+	}
+
+	stored := tui.configs.Configurations[0]
+	if stored.Name != "Work VLESS" || stored.Protocol != "vless" {
+		t.Errorf("importConfigFile() stored %+v", stored)
+	}
+	if _, _, err := parseLinkForSingBox(stored.Link); err != nil {
+		t.Errorf("importConfigFile() stored unusable link %s: %v", stored.Link, err)
+	}
+}
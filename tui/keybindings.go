@@ -2,12 +2,31 @@ package tui
 
 import (
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 )
 
+// mainScreenKey reports whether a shortcut that clashes with input field editing
+// (Ctrl+U, Ctrl+K, Ctrl+E, Ctrl+W) may fire: on the main screen, outside the link input
+func (tui *TUI) mainScreenKey() bool {
+	if _, editing := tui.app.GetFocus().(*tview.InputField); editing {
+		return false
+	}
+	return tui.mainFlex.HasFocus()
+}
+
 // setupKeybindings registers all global and input-specific shortcuts
 func (tui *TUI) setupKeybindings() {
 	// Global keybindings
 	tui.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if tui.shortcutsOff {
+			return event // a text editor has the keyboard
+		}
+		switch event.Key() {
+		case tcell.KeyCtrlU, tcell.KeyCtrlK, tcell.KeyCtrlE, tcell.KeyCtrlW:
+			if !tui.mainScreenKey() {
+				return event // leave the key to the focused input field or screen
+			}
+		}
 		switch {
 		case event.Key() == tcell.KeyCtrlA:
 			tui.addConfig()
@@ -17,8 +36,30 @@ func (tui *TUI) setupKeybindings() {
 			tui.deleteSelectedConfig()
 		case event.Key() == tcell.KeyCtrlR:
 			tui.renameSelectedConfig()
+		case event.Key() == tcell.KeyCtrlK:
+			tui.shareSelectedConfig()
+		case event.Key() == tcell.KeyCtrlE:
+			tui.showRouting()
 		case event.Key() == tcell.KeyCtrlF:
 			tui.refreshConfigurations()
+		case event.Key() == tcell.KeyCtrlT:
+			tui.testLatency()
+		case event.Key() == tcell.KeyCtrlB:
+			tui.showBulkImport()
+		case event.Key() == tcell.KeyCtrlO:
+			tui.showImportFileDialog()
+		case event.Key() == tcell.KeyCtrlG:
+			tui.showGroups()
+		case event.Key() == tcell.KeyCtrlN:
+			tui.showChains()
+		case event.Key() == tcell.KeyCtrlW:
+			tui.showConnections()
+		case event.Key() == tcell.KeyCtrlY:
+			tui.showLogs()
+		case event.Key() == tcell.KeyCtrlU:
+			tui.showSubscriptions()
+		case event.Key() == tcell.KeyCtrlP:
+			tui.showSettings()
 		case event.Key() == tcell.KeyCtrlL:
 			tui.clearUI()
 		case event.Key() == tcell.KeyCtrlX:
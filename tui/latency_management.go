@@ -0,0 +1,320 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	latencyTCP  = "tcp"  // TCP connect to server:port
+	latencyCore = "core" // HTTP request through a briefly launched core
+
+	defaultLatencyURL  = "https://www.gstatic.com/generate_204"
+	latencyWorkers     = 8
+	latencyTimeout     = 5 * time.Second
+	coreLatencyTimeout = 15 * time.Second
+)
+
+// latencyModes are the settings dropdown entries; "tcp" is stored as empty
+var latencyModes = []string{latencyTCP, latencyCore}
+
+// latencyResult is the outcome of a latency test of one configuration
+type latencyResult struct {
+	Latency time.Duration
+	Err     error
+}
+
+// String formats the result for the configuration list
+func (r latencyResult) String() string {
+	var netErr net.Error
+	switch {
+	case r.Err == nil:
+		return fmt.Sprintf("%d ms", r.Latency.Milliseconds())
+	case errors.Is(r.Err, context.DeadlineExceeded), errors.As(r.Err, &netErr) && netErr.Timeout():
+		return "timeout"
+	default:
+		return fmt.Sprintf("error: %v", r.Err)
+	}
+}
+
+// latencyTest measures one configuration
+type latencyTest func(ctx context.Context, config Config) (time.Duration, error)
+
+// latencyCoreBinary is a client able to run a rendered config for a latency test
+type latencyCoreBinary struct {
+	target parser.Target
+	binary string
+	args   []string // arguments before the config file
+	ext    string   // config file extension
+}
+
+// latencyCoreBinaries are tried in order, the first installed one supporting the protocol is used
+var latencyCoreBinaries = []latencyCoreBinary{
+	{target: parser.TargetSingBox, binary: "sing-box", args: []string{"run", "-c"}, ext: ".json"},
+	{target: parser.TargetXray, binary: "xray", args: []string{"run", "-c"}, ext: ".json"},
+	{target: parser.TargetV2Ray, binary: "v2ray", args: []string{"run"}, ext: ".json"},
+	{target: parser.TargetMihomo, binary: "mihomo", args: []string{"-f"}, ext: ".yaml"},
+}
+
+// runLatencyTests runs test for every configuration with at most workers tests
+// in flight and reports each result as soon as it is known
+func runLatencyTests(ctx context.Context, configs []Config, workers int, timeout time.Duration, test latencyTest, report func(Config, latencyResult)) {
+	jobs := make(chan Config)
+	var wg sync.WaitGroup
+
+	for i := 0; i < min(workers, len(configs)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for config := range jobs {
+				testCtx, cancel := context.WithTimeout(ctx, timeout)
+				latency, err := test(testCtx, config)
+				cancel()
+				report(config, latencyResult{Latency: latency, Err: err})
+			}
+		}()
+	}
+
+	for _, config := range configs {
+		jobs <- config
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// tcpLatency measures how long a TCP connect to the configuration's server takes
+func tcpLatency(ctx context.Context, config Config) (time.Duration, error) {
+	node, err := parser.Parse(config.Link)
+	if err != nil {
+		return 0, err
+	}
+	switch node.Protocol {
+	case "hysteria2", "tuic":
+		return 0, fmt.Errorf("%s runs over UDP, use the core latency test", node.Protocol)
+	}
+
+	var dialer net.Dialer
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(node.Server, strconv.Itoa(node.Port)))
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	conn.Close()
+	return latency, nil
+}
+
+// httpLatency measures a request to testURL through proxy, nil meaning a direct request
+func httpLatency(ctx context.Context, proxy *url.URL, testURL string) (time.Duration, error) {
+	transport := &http.Transport{DisableKeepAlives: true}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	client := &http.Client{Transport: transport}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, testURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return latency, nil
+}
+
+// coreLatency returns a test that launches an installed core with the
+// configuration on an ephemeral port and measures a request to testURL through
+// it. opts and testURL are taken on the UI goroutine, the test runs in workers.
+func coreLatency(opts parser.Options, testURL string) latencyTest {
+	opts.TUN = parser.TUN{}
+	opts.API = parser.API{} // the connected client may hold the API port
+	return func(ctx context.Context, config Config) (time.Duration, error) {
+		return runCoreLatency(ctx, config, opts, testURL)
+	}
+}
+
+// runCoreLatency is a single core latency test
+func runCoreLatency(ctx context.Context, config Config, opts parser.Options, testURL string) (time.Duration, error) {
+	node, err := parser.Parse(config.Link)
+	if err != nil {
+		return 0, err
+	}
+	core, err := latencyCoreFor(node)
+	if err != nil {
+		return 0, err
+	}
+	port, err := freePort()
+	if err != nil {
+		return 0, err
+	}
+
+	opts.Inbound = parser.Inbound{Listen: "127.0.0.1", SocksPort: port}
+	opts.Routing = configRouting(config)
+	rendered, err := parser.RenderWithOptions(node, core.target, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	dir, err := os.MkdirTemp("", "latency-")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	configFile := filepath.Join(dir, "config"+core.ext)
+	data, err := marshalClientConfig(configFile, rendered)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(configFile, data, 0600); err != nil {
+		return 0, err
+	}
+
+	cmd := exec.CommandContext(ctx, core.binary, append(core.args, configFile)...)
+	cmd.Dir = dir
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+	defer func() {
+		cmd.Process.Kill()
+		cmd.Wait()
+	}()
+
+	if err := waitForPort(ctx, port); err != nil {
+		return 0, fmt.Errorf("%s did not start: %w", core.binary, err)
+	}
+
+	return httpLatency(ctx, &url.URL{Scheme: "socks5", Host: net.JoinHostPort("127.0.0.1", strconv.Itoa(port))}, testURL)
+}
+
+// latencyCoreFor returns the first installed core able to run node
+func latencyCoreFor(node *parser.ProxyNode) (latencyCoreBinary, error) {
+	for _, core := range latencyCoreBinaries {
+		if !parser.SupportsNode(node, core.target) {
+			continue
+		}
+		if path, err := exec.LookPath(core.binary); err == nil {
+			core.binary = path
+			return core, nil
+		}
+	}
+	return latencyCoreBinary{}, fmt.Errorf("no installed client supports this %s server", node.Protocol)
+}
+
+// freePort asks the system for an unused local TCP port
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// waitForPort polls until something listens on the local port or ctx ends
+func waitForPort(ctx context.Context, port int) error {
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	for {
+		if conn, err := net.DialTimeout("tcp", address, 100*time.Millisecond); err == nil {
+			conn.Close()
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+// latencyTestForSettings returns the configured test and its per-configuration
+// timeout. Must run on the UI goroutine, which owns the settings.
+func (tui *TUI) latencyTestForSettings() (string, latencyTest, time.Duration) {
+	if tui.configs.Settings.LatencyTest == latencyCore {
+		testURL := tui.configs.Settings.LatencyURL
+		if testURL == "" {
+			testURL = defaultLatencyURL
+		}
+		return latencyCore, coreLatency(tui.renderOptions(Config{}), testURL), coreLatencyTimeout
+	}
+	return latencyTCP, tcpLatency, latencyTimeout
+}
+
+// testLatency measures every saved configuration in the background and shows
+// the results in the configuration list as they arrive
+func (tui *TUI) testLatency() {
+	var configs []Config
+	for _, config := range tui.configs.Configurations {
+		// Chains have no server of their own, their hops are tested instead
+		if config.Protocol != protocolChain {
+			configs = append(configs, config)
+		}
+	}
+	if len(configs) == 0 {
+		tui.updateStatus("Error: No configurations to test", tcell.ColorRed)
+		return
+	}
+	if tui.latencyRunning {
+		tui.updateStatus("Latency test already running", tcell.ColorYellow)
+		return
+	}
+
+	mode, test, timeout := tui.latencyTestForSettings()
+	tui.latencyRunning = true
+	tui.updateStatus(fmt.Sprintf("Testing latency of %d configuration(s) (%s)...", len(configs), mode), tcell.ColorBlue)
+
+	go func() {
+		tested, reachable := 0, 0
+		runLatencyTests(context.Background(), configs, latencyWorkers, timeout, test, func(config Config, result latencyResult) {
+			tui.app.QueueUpdateDraw(func() {
+				tested++
+				if result.Err == nil {
+					reachable++
+				}
+				tui.latency[config.Link] = result
+				tui.updateLatencyItem(config.Link)
+				tui.updateStatus(fmt.Sprintf("Testing latency (%s): %d/%d done", mode, tested, len(configs)), tcell.ColorBlue)
+			})
+		})
+
+		tui.app.QueueUpdateDraw(func() {
+			tui.latencyRunning = false
+			tui.updateStatus(fmt.Sprintf("Latency test finished: %d of %d configuration(s) reachable", reachable, len(configs)), tcell.ColorGreen)
+		})
+	}()
+}
+
+// updateLatencyItem refreshes the secondary text of the list items showing link
+// without rebuilding the list, so the selection is kept
+func (tui *TUI) updateLatencyItem(link string) {
+	for i, config := range tui.configs.Configurations {
+		if config.Link != link || i >= tui.configList.GetItemCount() {
+			continue
+		}
+		main, _ := tui.configList.GetItemText(i)
+		tui.configList.SetItemText(i, main, tui.configSecondaryText(config))
+	}
+}
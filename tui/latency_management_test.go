@@ -0,0 +1,264 @@
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tui_proxy_client/parser"
+)
+
+// localTrojanLink returns a share link pointing at a local port
+func localTrojanLink(port int) string {
+	return fmt.Sprintf("trojan://secret@127.0.0.1:%d#Local", port)
+}
+
+func TestTCPLatency(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	open := listener.Addr().(*net.TCPAddr).Port
+
+	closed, err := freePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		link        string
+		expectError bool
+	}{
+		{name: "listening server", link: localTrojanLink(open)},
+		{name: "closed port", link: localTrojanLink(closed), expectError: true},
+		{name: "UDP protocol", link: "hysteria2://secret@127.0.0.1:443#Hy2", expectError: true},
+		{name: "invalid link", link: "not a link", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+
+			latency, err := tcpLatency(ctx, Config{Link: tt.link})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("tcpLatency() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err == nil && latency <= 0 {
+				t.Errorf("tcpLatency() = %s, want a positive latency", latency)
+			}
+		})
+	}
+}
+
+func TestHTTPLatency(t *testing.T) {
+	var requested string
+	status := http.StatusNoContent
+	// The stand-in answers every request, acting as an HTTP proxy for absolute URLs
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = r.URL.String()
+		w.WriteHeader(status)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	latency, err := httpLatency(context.Background(), proxyURL, "http://latency.test/generate_204")
+	if err != nil {
+		t.Fatalf("httpLatency() unexpected error: %v", err)
+	}
+	if latency <= 0 {
+		t.Errorf("httpLatency() = %s, want a positive latency", latency)
+	}
+	if requested != "http://latency.test/generate_204" {
+		t.Errorf("proxy received %q, want the test URL", requested)
+	}
+
+	status = http.StatusBadGateway
+	if _, err := httpLatency(context.Background(), proxyURL, "http://latency.test/generate_204"); err == nil {
+		t.Error("httpLatency() should fail on an error status")
+	}
+}
+
+func TestRunLatencyTests(t *testing.T) {
+	var configs []Config
+	for i := 0; i < 20; i++ {
+		configs = append(configs, Config{Link: fmt.Sprintf("link-%d", i)})
+	}
+	configs[0].Link = "slow"
+
+	var inFlight, maxInFlight atomic.Int32
+	test := func(ctx context.Context, config Config) (time.Duration, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			peak := maxInFlight.Load()
+			if current <= peak || maxInFlight.CompareAndSwap(peak, current) {
+				break
+			}
+		}
+		if config.Link == "slow" {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		time.Sleep(5 * time.Millisecond)
+		return 42 * time.Millisecond, nil
+	}
+
+	var mu sync.Mutex
+	results := make(map[string]latencyResult)
+	runLatencyTests(context.Background(), configs, 3, 50*time.Millisecond, test, func(config Config, result latencyResult) {
+		mu.Lock()
+		results[config.Link] = result
+		mu.Unlock()
+	})
+
+	if len(results) != len(configs) {
+		t.Errorf("got %d results, want %d", len(results), len(configs))
+	}
+	if peak := maxInFlight.Load(); peak > 3 {
+		t.Errorf("%d tests ran concurrently, want at most 3", peak)
+	}
+	if got := results["slow"].String(); got != "timeout" {
+		t.Errorf("slow result = %q, want timeout", got)
+	}
+	if got := results["link-1"].String(); got != "42 ms" {
+		t.Errorf("result = %q, want 42 ms", got)
+	}
+}
+
+func TestLatencyResult_String(t *testing.T) {
+	tests := []struct {
+		result latencyResult
+		want   string
+	}{
+		{result: latencyResult{Latency: 1500 * time.Microsecond}, want: "1 ms"},
+		{result: latencyResult{Err: context.DeadlineExceeded}, want: "timeout"},
+		{result: latencyResult{Err: errors.New("connection refused")}, want: "error: connection refused"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.result.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTestLatency(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	closed, err := freePort()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tui := newTestTUI(t)
+	now := time.Now().Format(time.RFC3339)
+	tui.configs.Configurations = []Config{
+		{ID: "1", Name: "Up", Protocol: "trojan", Link: localTrojanLink(listener.Addr().(*net.TCPAddr).Port), CreatedAt: now, LastUsed: now},
+		{ID: "2", Name: "Down", Protocol: "trojan", Link: localTrojanLink(closed), CreatedAt: now, LastUsed: now},
+	}
+	tui.refreshConfigList()
+	tui.configList.SetCurrentItem(1)
+	runTestApp(t, tui)
+
+	tui.app.QueueUpdate(tui.testLatency)
+	waitFor(t, "the latency test to finish", func() bool {
+		return strings.HasPrefix(statusText(tui), "Latency test finished: 1 of 2")
+	})
+
+	var up, down string
+	var current int
+	tui.app.QueueUpdate(func() {
+		_, up = tui.configList.GetItemText(0)
+		_, down = tui.configList.GetItemText(1)
+		current = tui.configList.GetCurrentItem()
+	})
+	if !strings.Contains(up, "Latency: ") || !strings.HasSuffix(up, " ms") {
+		t.Errorf("secondary text = %q, want a latency in ms", up)
+	}
+	if !strings.Contains(down, "Latency: error:") {
+		t.Errorf("secondary text = %q, want an error", down)
+	}
+	if current != 1 {
+		t.Errorf("selection moved to %d, want it kept on 1", current)
+	}
+}
+
+func TestLatencyCoreFor(t *testing.T) {
+	// Only V2Ray and Xray are installed
+	dir := t.TempDir()
+	for _, name := range []string{"v2ray", "xray"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", dir)
+
+	tests := []struct {
+		name string
+		link string
+		want string // binary, empty when no installed core can run it
+	}{
+		{"vmess", testVMessLink, "xray"},
+		{"reality vision", "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=reality&sni=www.microsoft.com&pbk=PUBLICKEY&flow=xtls-rprx-vision", "xray"},
+		{"vision over websocket", "vless://12345678-1234-1234-1234-123456789012@example.com:443?security=tls&sni=example.com&type=ws&flow=xtls-rprx-vision", ""},
+		{"hysteria2", "hysteria2://secret@example.com:443", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := parser.Parse(tt.link)
+			if err != nil {
+				t.Fatal(err)
+			}
+			core, err := latencyCoreFor(node)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("latencyCoreFor() = %s, want no core", core.binary)
+				}
+				return
+			}
+			if err != nil || filepath.Base(core.binary) != tt.want {
+				t.Errorf("latencyCoreFor() = %s, %v; want %s", core.binary, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplySettings_Latency(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    Settings
+		expectError bool
+	}{
+		{name: "tcp default", settings: Settings{}},
+		{name: "core with URL", settings: Settings{LatencyTest: latencyCore, LatencyURL: "http://cp.cloudflare.com/"}},
+		{name: "unknown mode", settings: Settings{LatencyTest: "icmp"}, expectError: true},
+		{name: "URL without scheme", settings: Settings{LatencyTest: latencyCore, LatencyURL: "example.com/204"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := newTestTUI(t)
+			if err := tui.applySettings(tt.settings); (err != nil) != tt.expectError {
+				t.Errorf("applySettings() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// logCapacity is the number of client log lines kept in memory
+const logCapacity = 5000
+
+const (
+	logFilterLevel  = "Level"
+	logFilterSearch = "Search"
+)
+
+const logHelp = "p: pause | Esc: back"
+
+// logLevel is the severity of a client log line
+type logLevel int
+
+const (
+	logDebug logLevel = iota
+	logInfo
+	logWarn
+	logError
+)
+
+// logLevelNames are the level filter options, in logLevel order
+var logLevelNames = []string{"debug", "info", "warning", "error"}
+
+func (l logLevel) String() string {
+	return [...]string{"DEBUG", "INFO", "WARN", "ERROR"}[l]
+}
+
+// color returns the color lines of the level are shown in
+func (l logLevel) color() string {
+	return [...]string{"gray", "white", "yellow", "red"}[l]
+}
+
+// parseLogLevel reads the level names of sing-box, V2Ray, Xray and mihomo,
+// including the four letter logrus forms such as ERRO
+func parseLogLevel(name string) (logLevel, bool) {
+	name = strings.ToLower(name)
+	if len(name) > 4 {
+		name = name[:4]
+	}
+	switch name {
+	case "trac", "debu":
+		return logDebug, true
+	case "info":
+		return logInfo, true
+	case "warn":
+		return logWarn, true
+	case "erro", "fata", "pani":
+		return logError, true
+	default:
+		return 0, false
+	}
+}
+
+// logEntry is a parsed client log line
+type logEntry struct {
+	Time    time.Time
+	Level   logLevel
+	Message string
+}
+
+// String formats the entry as saved to a file
+func (e logEntry) String() string {
+	return fmt.Sprintf("%s %-5s %s", e.Time.Format("2006-01-02 15:04:05"), e.Level, e.Message)
+}
+
+var (
+	ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+	// -0700 2006-01-02 15:04:05 INFO [3615621385 0ms] inbound/mixed[mixed-in]: ...
+	// The timestamp is left out when log.timestamp is disabled.
+	singBoxLogLine = regexp.MustCompile(`^(?:([+-]\d{4} \d{4}-\d{2}-\d{2} \d{2}:\d{2}:\d{2}) )?(TRACE|DEBUG|INFO|WARN|ERROR|FATAL|PANIC) (.*)$`)
+	// FATAL[0000] decode config at config.json: ...
+	logrusLogLine = regexp.MustCompile(`^([A-Z]{4,5})\[\d+\] (.*)$`)
+	// 2006/01/02 15:04:05.000000 [Warning] ... or an access log line without a level
+	v2rayLogLine = regexp.MustCompile(`^(\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2})(?:\.\d+)? (?:\[(\w+)\] )?(.*)$`)
+	// time="2006-01-02T15:04:05.000000000+07:00" level=info msg="..."
+	mihomoLogLine = regexp.MustCompile(`^time="([^"]+)" level=(\w+) msg="(.*)"$`)
+)
+
+// parseLogLine splits a client log line into time, level and message. Parts the
+// line lacks are taken from now and fallback.
+func parseLogLine(line string, fallback logLevel, now time.Time) logEntry {
+	line = strings.TrimSpace(ansiEscape.ReplaceAllString(line, ""))
+	entry := logEntry{Time: now, Level: fallback, Message: line}
+
+	setLevel := func(name string) {
+		if level, ok := parseLogLevel(name); ok {
+			entry.Level = level
+		}
+	}
+	setTime := func(layout, value string) {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			entry.Time = t
+		}
+	}
+
+	if m := singBoxLogLine.FindStringSubmatch(line); m != nil {
+		setTime("-0700 2006-01-02 15:04:05", m[1])
+		setLevel(m[2])
+		entry.Message = m[3]
+	} else if m := logrusLogLine.FindStringSubmatch(line); m != nil {
+		if _, ok := parseLogLevel(m[1]); ok {
+			setLevel(m[1])
+			entry.Message = m[2]
+		}
+	} else if m := v2rayLogLine.FindStringSubmatch(line); m != nil {
+		setTime("2006/01/02 15:04:05", m[1])
+		if m[2] != "" {
+			setLevel(m[2])
+		} else {
+			entry.Level = logInfo // access log
+		}
+		entry.Message = m[3]
+	} else if m := mihomoLogLine.FindStringSubmatch(line); m != nil {
+		setTime(time.RFC3339Nano, m[1])
+		setLevel(m[2])
+		if msg, err := strconv.Unquote(`"` + m[3] + `"`); err == nil {
+			entry.Message = msg
+		} else {
+			entry.Message = m[3]
+		}
+	}
+	return entry
+}
+
+// logBuffer is a ring buffer keeping the latest entries
+type logBuffer struct {
+	entries  []logEntry
+	next     int // oldest entry, overwritten next once the buffer is full
+	capacity int
+}
+
+// newLogBuffer returns an empty buffer holding up to capacity entries
+func newLogBuffer(capacity int) *logBuffer {
+	return &logBuffer{capacity: capacity}
+}
+
+// add appends an entry, dropping the oldest one when the buffer is full
+func (b *logBuffer) add(e logEntry) {
+	if len(b.entries) < b.capacity {
+		b.entries = append(b.entries, e)
+		return
+	}
+	b.entries[b.next] = e
+	b.next = (b.next + 1) % b.capacity
+}
+
+// all returns the entries oldest first
+func (b *logBuffer) all() []logEntry {
+	return append(slices.Clone(b.entries[b.next:]), b.entries[:b.next]...)
+}
+
+// clear removes all entries
+func (b *logBuffer) clear() {
+	b.entries, b.next = nil, 0
+}
+
+// logMatches reports whether the entry passes the level and search filters
+func (tui *TUI) logMatches(e logEntry) bool {
+	if e.Level < tui.logLevel {
+		return false
+	}
+	return tui.logSearch == "" || strings.Contains(strings.ToLower(e.Message), strings.ToLower(tui.logSearch))
+}
+
+// appendLog stores a client log entry and shows it unless filtered out or
+// paused. Must run on the UI goroutine.
+func (tui *TUI) appendLog(e logEntry) {
+	tui.logs.add(e)
+	if !tui.logMatches(e) {
+		return
+	}
+	if tui.logPaused {
+		tui.logPending++
+		tui.updateLogStatus()
+		return
+	}
+	tui.writeLogEntry(e)
+	tui.logText.ScrollToEnd()
+}
+
+// writeLogEntry adds one colored line to the log view
+func (tui *TUI) writeLogEntry(e logEntry) {
+	fmt.Fprintf(tui.logText, "[%s]%s %-5s %s[-]\n", e.Level.color(), e.Time.Format("15:04:05"), e.Level, tview.Escape(e.Message))
+}
+
+// renderLogs redraws the log view from the buffer with the current filters
+func (tui *TUI) renderLogs() {
+	tui.logText.Clear()
+	for _, e := range tui.logs.all() {
+		if tui.logMatches(e) {
+			tui.writeLogEntry(e)
+		}
+	}
+	tui.logPending = 0
+	if !tui.logPaused {
+		tui.logText.ScrollToEnd()
+	}
+	tui.updateLogStatus()
+}
+
+// toggleLogPause freezes the view for reading or resumes following new lines
+func (tui *TUI) toggleLogPause() {
+	tui.logPaused = !tui.logPaused
+	if tui.logPaused {
+		tui.updateLogStatus()
+		return
+	}
+	tui.renderLogs()
+}
+
+// saveLogs writes every buffered entry, regardless of the filters, to a new
+// file in the directory of the file browser
+func (tui *TUI) saveLogs() (string, error) {
+	var sb strings.Builder
+	for _, e := range tui.logs.all() {
+		sb.WriteString(e.String())
+		sb.WriteString("\n")
+	}
+
+	filename := filepath.Join(tui.currentPath, fmt.Sprintf("client_log_%s.log", time.Now().Format("20060102_150405")))
+	if err := os.WriteFile(filename, []byte(sb.String()), 0644); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// showLogs opens the client log viewer
+func (tui *TUI) showLogs() {
+	tui.renderLogs()
+	tui.app.SetRoot(tui.logView, true)
+	tui.app.SetFocus(tui.logText)
+}
+
+// createLogView builds the client log viewer
+func (tui *TUI) createLogView() *tview.Flex {
+	tui.logText = tview.NewTextView().
+		SetDynamicColors(true).
+		SetScrollable(true).
+		SetMaxLines(logCapacity)
+	tui.logText.SetBorder(true)
+	tui.logText.SetTitle(" Client Logs ")
+	tui.logText.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape:
+			tui.app.SetRoot(tui.mainFlex, true)
+		case event.Key() == tcell.KeyRune && event.Rune() == 'p':
+			tui.toggleLogPause()
+		case event.Key() == tcell.KeyTab:
+			tui.app.SetFocus(tui.logForm)
+		case event.Key() == tcell.KeyRune && event.Rune() == '/':
+			tui.app.SetFocus(tui.logForm.GetFormItemByLabel(logFilterSearch))
+		default:
+			return event
+		}
+		return nil
+	})
+
+	tui.logStatus = tview.NewTextView()
+	tui.logStatus.SetTextAlign(tview.AlignCenter)
+	tui.logStatus.SetBorder(true)
+	tui.logStatus.SetTitle(" Status ")
+
+	tui.logForm = tview.NewForm().
+		SetHorizontal(true).
+		AddDropDown(logFilterLevel, logLevelNames, int(logDebug), func(option string, index int) {
+			if index < 0 {
+				return
+			}
+			tui.logLevel = logLevel(index)
+			tui.renderLogs()
+		}).
+		AddInputField(logFilterSearch, "", 30, nil, func(text string) {
+			tui.logSearch = text
+			tui.renderLogs()
+		})
+
+	tui.logForm.
+		AddButton("Pause/Resume", func() {
+			tui.toggleLogPause()
+		}).
+		AddButton("Save", func() {
+			filename, err := tui.saveLogs()
+			if err != nil {
+				tui.setLogStatus(fmt.Sprintf("Error saving log: %v", err), tcell.ColorRed)
+				return
+			}
+			tui.setLogStatus(fmt.Sprintf("Saved %d lines to %s", len(tui.logs.entries), filename), tcell.ColorGreen)
+		}).
+		AddButton("Clear", func() {
+			tui.logs.clear()
+			tui.renderLogs()
+		}).
+		AddButton("Back", func() {
+			tui.app.SetRoot(tui.mainFlex, true)
+		})
+	tui.logForm.SetBorder(true)
+	tui.logForm.SetTitle(" Filter ")
+	// Esc in the filters returns to the log, which Tab and / leave for the filters
+	tui.logForm.SetCancelFunc(func() {
+		tui.app.SetFocus(tui.logText)
+	})
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tui.logForm, 3, 0, false).
+		AddItem(tui.logText, 0, 1, true).
+		AddItem(tui.logStatus, 3, 0, false)
+}
+
+// updateLogStatus shows how many lines are kept and whether the view is paused
+func (tui *TUI) updateLogStatus() {
+	if tui.logPaused {
+		tui.setLogStatus(fmt.Sprintf("Paused - %d new lines | %s", tui.logPending, logHelp), tcell.ColorYellow)
+		return
+	}
+	tui.setLogStatus(fmt.Sprintf("%d of %d lines kept | %s", len(tui.logs.entries), logCapacity, logHelp), tcell.ColorWhite)
+}
+
+// setLogStatus updates the log viewer status line
+func (tui *TUI) setLogStatus(message string, color tcell.Color) {
+	tui.logStatus.SetText(message).SetTextColor(color)
+}
@@ -0,0 +1,218 @@
+package tui
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func TestParseLogLine(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.Local)
+
+	tests := []struct {
+		name        string
+		line        string
+		fallback    logLevel
+		wantLevel   logLevel
+		wantMessage string
+		wantTime    time.Time // zero for now
+	}{
+		{
+			name:        "sing-box",
+			line:        "+0000 2025-03-04 10:20:30 WARN [1234 0ms] outbound/vmess[proxy]: connection reset",
+			fallback:    logError,
+			wantLevel:   logWarn,
+			wantMessage: "[1234 0ms] outbound/vmess[proxy]: connection reset",
+			wantTime:    time.Date(2025, 3, 4, 10, 20, 30, 0, time.UTC),
+		},
+		{
+			name:        "sing-box colored without timestamp",
+			line:        "\x1b[36mINFO\x1b[0m router: updated default interface",
+			fallback:    logError,
+			wantLevel:   logInfo,
+			wantMessage: "router: updated default interface",
+		},
+		{
+			name:        "sing-box startup error",
+			line:        "FATAL[0000] decode config at config.json: unknown field",
+			fallback:    logInfo,
+			wantLevel:   logError,
+			wantMessage: "decode config at config.json: unknown field",
+		},
+		{
+			name:        "V2Ray",
+			line:        "2025/03/04 10:20:30.123456 [Debug] app/dns: domain example.com",
+			fallback:    logError,
+			wantLevel:   logDebug,
+			wantMessage: "app/dns: domain example.com",
+			wantTime:    time.Date(2025, 3, 4, 10, 20, 30, 0, time.Local),
+		},
+		{
+			name:        "V2Ray access log",
+			line:        "2025/03/04 10:20:30 127.0.0.1:50000 accepted tcp:example.com:443 [proxy]",
+			fallback:    logError,
+			wantLevel:   logInfo,
+			wantMessage: "127.0.0.1:50000 accepted tcp:example.com:443 [proxy]",
+			wantTime:    time.Date(2025, 3, 4, 10, 20, 30, 0, time.Local),
+		},
+		{
+			name:        "mihomo",
+			line:        `time="2025-03-04T10:20:30.5Z" level=error msg="dial \"proxy\" failed"`,
+			fallback:    logInfo,
+			wantLevel:   logError,
+			wantMessage: `dial "proxy" failed`,
+			wantTime:    time.Date(2025, 3, 4, 10, 20, 30, 500_000_000, time.UTC),
+		},
+		{
+			name:        "unknown format",
+			line:        "panic: runtime error",
+			fallback:    logError,
+			wantLevel:   logError,
+			wantMessage: "panic: runtime error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseLogLine(tt.line, tt.fallback, now)
+			if got.Level != tt.wantLevel {
+				t.Errorf("Level = %v, want %v", got.Level, tt.wantLevel)
+			}
+			if got.Message != tt.wantMessage {
+				t.Errorf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			wantTime := tt.wantTime
+			if wantTime.IsZero() {
+				wantTime = now
+			}
+			if !got.Time.Equal(wantTime) {
+				t.Errorf("Time = %v, want %v", got.Time, wantTime)
+			}
+		})
+	}
+}
+
+func TestLogBuffer(t *testing.T) {
+	buffer := newLogBuffer(3)
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		buffer.add(logEntry{Message: msg})
+	}
+
+	var got []string
+	for _, e := range buffer.all() {
+		got = append(got, e.Message)
+	}
+	if strings.Join(got, "") != "cde" {
+		t.Errorf("all() = %v, want the latest three oldest first", got)
+	}
+
+	buffer.clear()
+	if len(buffer.all()) != 0 {
+		t.Error("clear() left entries behind")
+	}
+}
+
+func TestLogViewer_FilterAndPause(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.logs = newLogBuffer(logCapacity)
+
+	tui.appendLog(logEntry{Level: logDebug, Message: "dns lookup example.com"})
+	tui.appendLog(logEntry{Level: logInfo, Message: "inbound connection"})
+	tui.appendLog(logEntry{Level: logError, Message: "dial example.com failed"})
+
+	shown := func() string { return tui.logText.GetText(true) }
+	if strings.Count(shown(), "\n") != 3 {
+		t.Fatalf("view = %q, want all three lines", shown())
+	}
+
+	tui.logLevel = logInfo
+	tui.logSearch = "EXAMPLE"
+	tui.renderLogs()
+	if got := shown(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "dial example.com failed") {
+		t.Errorf("filtered view = %q, want only the error", got)
+	}
+
+	tui.toggleLogPause()
+	tui.appendLog(logEntry{Level: logWarn, Message: "example.com slow"})
+	tui.appendLog(logEntry{Level: logWarn, Message: "filtered out"})
+	if strings.Contains(shown(), "slow") || tui.logPending != 1 {
+		t.Errorf("paused view = %q with %d pending, want it frozen with 1 pending", shown(), tui.logPending)
+	}
+
+	tui.toggleLogPause()
+	if !strings.Contains(shown(), "example.com slow") {
+		t.Errorf("view after resuming = %q, want the line received while paused", shown())
+	}
+	if len(tui.logs.all()) != 5 {
+		t.Errorf("buffer holds %d entries, want all 5 regardless of filters", len(tui.logs.all()))
+	}
+}
+
+func TestLogViewer_Focus(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.showLogs()
+
+	// press sends a key to the focused primitive like the application does
+	press := func(key tcell.Key, r rune) tview.Primitive {
+		tui.app.GetFocus().InputHandler()(tcell.NewEventKey(key, r, tcell.ModNone), func(p tview.Primitive) {
+			tui.app.SetFocus(p)
+		})
+		return tui.app.GetFocus()
+	}
+
+	if focus := press(tcell.KeyTab, 0); focus != tui.logForm.GetFormItemByLabel(logFilterLevel) {
+		t.Errorf("Tab in the log focused %T, want the level filter", focus)
+	}
+	if focus := press(tcell.KeyEscape, 0); focus != tui.logText {
+		t.Errorf("Esc in the filters focused %T, want the log", focus)
+	}
+	if focus := press(tcell.KeyRune, '/'); focus != tui.logForm.GetFormItemByLabel(logFilterSearch) {
+		t.Errorf("/ in the log focused %T, want the search field", focus)
+	}
+	if focus := press(tcell.KeyEscape, 0); focus != tui.logText {
+		t.Errorf("Esc in the search field focused %T, want the log", focus)
+	}
+}
+
+func TestSaveLogs(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.currentPath = t.TempDir()
+	tui.logs = newLogBuffer(logCapacity)
+	at := time.Date(2025, 3, 4, 10, 20, 30, 0, time.Local)
+	tui.appendLog(logEntry{Time: at, Level: logInfo, Message: "started"})
+	tui.appendLog(logEntry{Time: at, Level: logError, Message: "failed"})
+	tui.logLevel = logError // filters do not apply to the saved file
+
+	filename, err := tui.saveLogs()
+	if err != nil {
+		t.Fatalf("saveLogs() unexpected error: %v", err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "2025-03-04 10:20:30 INFO  started\n2025-03-04 10:20:30 ERROR failed\n"
+	if string(data) != want {
+		t.Errorf("saved log = %q, want %q", data, want)
+	}
+}
+
+func TestStreamOutput(t *testing.T) {
+	tui := newTestTUI(t)
+	runTestApp(t, tui)
+
+	tui.streamOutput(strings.NewReader("+0000 2025-03-04 10:20:30 WARN router: slow\nplain line\n"), logError)
+
+	var entries []logEntry
+	tui.app.QueueUpdate(func() { entries = tui.logs.all() })
+	if len(entries) != 2 {
+		t.Fatalf("got %d log entries, want 2", len(entries))
+	}
+	if entries[0].Level != logWarn || entries[1].Level != logError || entries[1].Message != "plain line" {
+		t.Errorf("entries = %+v", entries)
+	}
+}
@@ -0,0 +1,244 @@
+package tui
+
+import (
+	"errors"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// defaultStopTimeout is how long a client gets to exit after SIGTERM before it is killed
+const defaultStopTimeout = 5 * time.Second
+
+var (
+	// errNoClient is returned when there is no supervised client to stop
+	errNoClient = errors.New("no client process is running")
+	// errRestartCancelled is returned by Restart when Stop or Start cancelled the scheduled restart
+	errRestartCancelled = errors.New("restart cancelled")
+)
+
+// clientProcess is a client started by the supervisor
+type clientProcess struct {
+	cmd       *exec.Cmd
+	startedAt time.Time
+	done      chan struct{} // closed once the process has exited
+	err       error         // exit error, valid after done
+	stopped   bool          // Stop was requested, the exit is not a crash
+}
+
+// Wait blocks until the process exits and reports whether Stop caused it
+func (p *clientProcess) Wait() (err error, stopped bool) {
+	<-p.done
+	return p.err, p.stopped
+}
+
+// PID returns the operating system process ID
+func (p *clientProcess) PID() int {
+	return p.cmd.Process.Pid
+}
+
+// supervisor owns the single client process this application started, so
+// disconnecting never touches other programs that happen to use the port
+type supervisor struct {
+	mu          sync.Mutex
+	current     *clientProcess
+	pending     chan struct{} // non-nil after a crash until the restart, closed to cancel it
+	stopTimeout time.Duration
+}
+
+// newSupervisor creates a supervisor with the default stop timeout
+func newSupervisor() *supervisor {
+	return &supervisor{stopTimeout: defaultStopTimeout}
+}
+
+// Start launches cmd, which must not be started yet, and watches it until it
+// exits. A scheduled restart of the previous client is cancelled.
+func (s *supervisor) Start(cmd *exec.Cmd) (*clientProcess, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cancelPending()
+	return s.start(cmd)
+}
+
+// Restart launches cmd in place of a crashed client unless Stop or Start
+// cancelled the restart meanwhile
+func (s *supervisor) Restart(cmd *exec.Cmd) (*clientProcess, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pending == nil {
+		return nil, errRestartCancelled
+	}
+	p, err := s.start(cmd)
+	if err != nil {
+		// Stay pending so the caller can back off and try again
+		return nil, err
+	}
+	s.pending = nil
+	return p, nil
+}
+
+// Backoff waits for delay before restarting a crashed client. It returns false
+// when the restart was cancelled by Stop or Start.
+func (s *supervisor) Backoff(delay time.Duration) bool {
+	s.mu.Lock()
+	pending := s.pending
+	s.mu.Unlock()
+
+	if pending == nil {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-pending:
+		return false
+	}
+}
+
+// Restarting reports whether a crashed client is waiting to be restarted
+func (s *supervisor) Restarting() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pending != nil
+}
+
+// CancelRestart drops the restart of a crashed client, leaving a running client alone
+func (s *supervisor) CancelRestart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cancelPending()
+}
+
+// cancelPending cancels a scheduled restart, s.mu must be held
+func (s *supervisor) cancelPending() {
+	if s.pending != nil {
+		close(s.pending)
+		s.pending = nil
+	}
+}
+
+// start launches cmd, s.mu must be held
+func (s *supervisor) start(cmd *exec.Cmd) (*clientProcess, error) {
+	if s.current != nil {
+		return nil, errors.New("a client process is already running")
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &clientProcess{cmd: cmd, startedAt: time.Now(), done: make(chan struct{})}
+	s.current = p
+
+	go func() {
+		err := cmd.Wait()
+
+		s.mu.Lock()
+		p.err = err
+		if s.current == p {
+			s.current = nil
+			// Mark the crash before anyone can observe the missing process, so a
+			// concurrent Stop cancels the restart instead of finding nothing to stop
+			if !p.stopped {
+				s.pending = make(chan struct{})
+			}
+		}
+		s.mu.Unlock()
+
+		close(p.done)
+	}()
+
+	return p, nil
+}
+
+// Running returns the supervised process, nil when none is running
+func (s *supervisor) Running() *clientProcess {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// Stop sends SIGTERM, kills the process if it has not exited within the stop
+// timeout and waits for the exit. killed reports whether SIGKILL was needed.
+// Between crashes it cancels the scheduled restart instead.
+func (s *supervisor) Stop() (killed bool, err error) {
+	s.mu.Lock()
+	p := s.current
+	restarting := s.pending != nil
+	if p != nil {
+		p.stopped = true
+	}
+	s.cancelPending()
+	s.mu.Unlock()
+
+	if p == nil {
+		if restarting {
+			return false, nil
+		}
+		return false, errNoClient
+	}
+
+	// Windows has no SIGTERM; the signal fails and the process is killed right away
+	if err := p.cmd.Process.Signal(syscall.SIGTERM); err != nil {
+		if errors.Is(err, os.ErrProcessDone) {
+			// The client exited on its own before the signal
+			<-p.done
+			return false, nil
+		}
+		killed = true
+		p.cmd.Process.Kill()
+	}
+
+	select {
+	case <-p.done:
+	case <-time.After(s.stopTimeout):
+		killed = true
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+
+	return killed, nil
+}
+
+// portAccepting reports whether something accepts TCP connections on host:port
+func portAccepting(host string, port int) bool {
+	conn, err := net.DialTimeout("tcp", probeAddress(host, port), 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+// portInUse reports whether something accepts or holds TCP connections on
+// host:port. It briefly binds the port, which must not race a client that is
+// about to listen on it; use portAccepting then.
+func portInUse(host string, port int) bool {
+	if portAccepting(host, port) {
+		return true
+	}
+
+	listener, err := net.Listen("tcp", probeAddress(host, port))
+	if err != nil {
+		return true
+	}
+	listener.Close()
+	return false
+}
+
+// probeAddress returns host:port, reaching an unspecified host through the loopback address
+func probeAddress(host string, port int) string {
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
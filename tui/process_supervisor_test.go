@@ -0,0 +1,178 @@
+package tui
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is not a real test; it stands in for a client binary when
+// GO_WANT_HELPER_PROCESS is set
+func TestHelperProcess(t *testing.T) {
+	mode := os.Getenv("GO_WANT_HELPER_PROCESS")
+	if mode == "" {
+		return
+	}
+
+	switch mode {
+	case "ignore-term":
+		signal.Ignore(syscall.SIGTERM)
+	case "exit":
+		os.Exit(3)
+	case "crash":
+		// Record the launch and crash for the first HELPER_CRASHES launches
+		counter, _ := os.OpenFile(os.Getenv("HELPER_COUNTER"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		counter.Write([]byte("x"))
+		info, _ := counter.Stat()
+		counter.Close()
+		crashes, err := strconv.ParseInt(os.Getenv("HELPER_CRASHES"), 10, 64)
+		if err != nil || info.Size() <= crashes {
+			os.Exit(1)
+		}
+	}
+	time.Sleep(time.Minute)
+	os.Exit(0)
+}
+
+// helperCommand returns a command running TestHelperProcess in the given mode
+func helperCommand(t *testing.T, mode string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperProcess$")
+	cmd.Env = append(os.Environ(), "GO_WANT_HELPER_PROCESS="+mode)
+	return cmd
+}
+
+func TestSupervisor_StopWithSIGTERM(t *testing.T) {
+	s := newSupervisor()
+	proc, err := s.Start(helperCommand(t, "sleep"))
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	if s.Running() != proc {
+		t.Fatal("Running() should return the started process")
+	}
+	if _, err := s.Start(helperCommand(t, "sleep")); err == nil {
+		t.Error("Start() should refuse a second process")
+	}
+
+	killed, err := s.Stop()
+	if err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+	if killed {
+		t.Error("Stop() killed a process that exits on SIGTERM")
+	}
+	if _, stopped := proc.Wait(); !stopped {
+		t.Error("Wait() should report the exit as stopped")
+	}
+	if s.Running() != nil {
+		t.Error("Running() should be nil after Stop()")
+	}
+	if _, err := s.Stop(); err != errNoClient {
+		t.Errorf("Stop() without a process error = %v, want %v", err, errNoClient)
+	}
+}
+
+func TestSupervisor_KillAfterTimeout(t *testing.T) {
+	s := newSupervisor()
+	s.stopTimeout = 200 * time.Millisecond
+
+	proc, err := s.Start(helperCommand(t, "ignore-term"))
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+	// Give the helper time to install its signal handler
+	time.Sleep(300 * time.Millisecond)
+
+	killed, err := s.Stop()
+	if err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+	if !killed {
+		t.Error("Stop() should kill a process that ignores SIGTERM")
+	}
+	if _, stopped := proc.Wait(); !stopped {
+		t.Error("Wait() should report the exit as stopped")
+	}
+}
+
+func TestSupervisor_StopAfterExit(t *testing.T) {
+	// The client has exited, but the supervisor has not noticed yet
+	cmd := helperCommand(t, "exit")
+	if err := cmd.Run(); err == nil {
+		t.Fatal("helper process should exit with an error")
+	}
+	p := &clientProcess{cmd: cmd, done: make(chan struct{})}
+	close(p.done)
+	s := newSupervisor()
+	s.current = p
+
+	killed, err := s.Stop()
+	if err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+	if killed {
+		t.Error("Stop() reported a kill for a process that had already exited")
+	}
+}
+
+func TestSupervisor_UnexpectedExit(t *testing.T) {
+	s := newSupervisor()
+	proc, err := s.Start(helperCommand(t, "exit"))
+	if err != nil {
+		t.Fatalf("Start() unexpected error: %v", err)
+	}
+
+	err, stopped := proc.Wait()
+	if err == nil || stopped {
+		t.Errorf("Wait() = %v, %v; want an exit error and not stopped", err, stopped)
+	}
+	if s.Running() != nil {
+		t.Error("Running() should be nil after the process exits")
+	}
+	if !s.Restarting() {
+		t.Fatal("Restarting() should be true after a crash")
+	}
+
+	// Stop cancels the pending restart
+	if _, err := s.Stop(); err != nil {
+		t.Errorf("Stop() unexpected error: %v", err)
+	}
+	if s.Restarting() || s.Backoff(time.Millisecond) {
+		t.Error("restart should be cancelled by Stop()")
+	}
+	if _, err := s.Restart(helperCommand(t, "sleep")); err != errRestartCancelled {
+		t.Errorf("Restart() error = %v, want %v", err, errRestartCancelled)
+	}
+}
+
+func TestPortInUse(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	if !portInUse("127.0.0.1", port) {
+		t.Error("portInUse() = false for a listening port")
+	}
+	if !portInUse("0.0.0.0", port) {
+		t.Error("portInUse() = false for a listening port on the unspecified address")
+	}
+	if !portAccepting("0.0.0.0", port) {
+		t.Error("portAccepting() = false for a listening port")
+	}
+
+	listener.Close()
+	if portInUse("127.0.0.1", port) {
+		t.Error("portInUse() = true for a closed port")
+	}
+	if portAccepting("127.0.0.1", port) {
+		t.Error("portAccepting() = true for a closed port")
+	}
+}
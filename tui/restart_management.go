@@ -0,0 +1,113 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// restartPolicy controls how a crashed client is relaunched
+type restartPolicy struct {
+	MaxRetries     int           // consecutive restarts before giving up, 0 disables restarting
+	InitialBackoff time.Duration // delay before the first restart, doubled for every further one
+	MaxBackoff     time.Duration // upper bound for the delay
+	StableAfter    time.Duration // uptime after which the restart count starts over
+}
+
+// defaultRestartPolicy returns the policy used for every connection
+func defaultRestartPolicy() restartPolicy {
+	return restartPolicy{
+		MaxRetries:     5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     30 * time.Second,
+		StableAfter:    time.Minute,
+	}
+}
+
+// backoff returns the delay before the given restart attempt, counting from 1
+func (p restartPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// superviseClient waits for the client to exit and relaunches it with the same
+// command until it is stopped deliberately or the restart policy gives up
+func (tui *TUI) superviseClient(clientType, configName string, command []string, proc *clientProcess) {
+	restarts := 0
+	for {
+		err, stopped := proc.Wait()
+		if stopped {
+			return
+		}
+		if time.Since(proc.startedAt) >= tui.restartPolicy.StableAfter {
+			restarts = 0
+		}
+
+		proc, restarts = tui.restartClient(clientType, command, restarts, err)
+		if proc == nil {
+			return
+		}
+		tui.showRestarted(clientType, configName, proc, restarts)
+	}
+}
+
+// restartClient relaunches the client after the backoff delay, retrying failed
+// launches. It returns nil when the restart was cancelled or the retries ran out.
+func (tui *TUI) restartClient(clientType string, command []string, restarts int, exitErr error) (*clientProcess, int) {
+	for restarts < tui.restartPolicy.MaxRetries {
+		restarts++
+		delay := tui.restartPolicy.backoff(restarts)
+		tui.showRestarting(clientType, exitErr, restarts, delay)
+
+		if !tui.supervisor.Backoff(delay) {
+			return nil, restarts
+		}
+		proc, err := tui.launchClient(tui.supervisor.Restart, command)
+		if err == nil {
+			return proc, restarts
+		}
+		if errors.Is(err, errRestartCancelled) {
+			return nil, restarts
+		}
+		exitErr = err
+	}
+
+	tui.supervisor.CancelRestart()
+	tui.restoreSystemProxy()
+	tui.handleClientExit(clientType, exitErr, restarts)
+	return nil, restarts
+}
+
+// showRestarting reports a crash and the scheduled restart
+func (tui *TUI) showRestarting(clientType string, err error, attempt int, delay time.Duration) {
+	reason := "exited"
+	if err != nil {
+		reason = fmt.Sprintf("crashed: %v", err)
+	}
+	message := fmt.Sprintf("%s %s - restarting in %s (attempt %d/%d)", clientType, reason, delay, attempt, tui.restartPolicy.MaxRetries)
+
+	tui.app.QueueUpdateDraw(func() {
+		tui.restarts = attempt
+		tui.updateStatus(message, tcell.ColorYellow)
+		tui.updateConnectionStatus()
+	})
+}
+
+// showRestarted reports a successful relaunch
+func (tui *TUI) showRestarted(clientType, configName string, proc *clientProcess, attempt int) {
+	message := fmt.Sprintf("%s restarted with config: %s (PID %d, attempt %d/%d)", clientType, configName, proc.PID(), attempt, tui.restartPolicy.MaxRetries)
+
+	tui.app.QueueUpdateDraw(func() {
+		tui.restarts = attempt
+		tui.updateStatus(message, tcell.ColorGreen)
+		tui.updateConnectionStatus()
+	})
+}
@@ -0,0 +1,176 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestRestartPolicy_Backoff(t *testing.T) {
+	policy := restartPolicy{InitialBackoff: time.Second, MaxBackoff: 10 * time.Second}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 8 * time.Second},
+		{attempt: 5, want: 10 * time.Second},
+		{attempt: 50, want: 10 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := policy.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+// runTestApp runs the application on a simulation screen so queued UI updates execute
+func runTestApp(t *testing.T, tui *TUI) {
+	tui.app.SetScreen(tcell.NewSimulationScreen(""))
+	go tui.app.Run()
+	t.Cleanup(tui.app.Stop)
+}
+
+// statusText reads the status line on the UI goroutine
+func statusText(tui *TUI) string {
+	var text string
+	tui.app.QueueUpdate(func() {
+		text = tui.statusText.GetText(true)
+	})
+	return text
+}
+
+// crashingClient makes the helper process crash on its first crashes launches
+// and returns the client command and a function counting the launches
+func crashingClient(t *testing.T, crashes int) ([]string, func() int) {
+	counter := filepath.Join(t.TempDir(), "launches")
+	t.Setenv("GO_WANT_HELPER_PROCESS", "crash")
+	t.Setenv("HELPER_COUNTER", counter)
+	t.Setenv("HELPER_CRASHES", strconv.Itoa(crashes))
+
+	launches := func() int {
+		data, _ := os.ReadFile(counter)
+		return len(data)
+	}
+	return []string{os.Args[0], "-test.run=^TestHelperProcess$"}, launches
+}
+
+// startSupervisedClient runs startClientProcess in the background and returns a
+// channel closed when it returns
+func startSupervisedClient(tui *TUI, command []string) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		tui.startClientProcess("fake", "Test Config", command)
+		close(done)
+	}()
+	return done
+}
+
+// waitFor polls cond until it holds or the test times out
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSuperviseClient_GivesUp(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.restartPolicy = restartPolicy{MaxRetries: 3, InitialBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, StableAfter: time.Hour}
+	runTestApp(t, tui)
+	command, launches := crashingClient(t, 1000)
+
+	select {
+	case <-startSupervisedClient(tui, command):
+	case <-time.After(10 * time.Second):
+		t.Fatal("startClientProcess() did not give up")
+	}
+
+	if got := launches(); got != 4 {
+		t.Errorf("launches = %d, want the first start and 3 restarts", got)
+	}
+	if tui.supervisor.Running() != nil || tui.supervisor.Restarting() {
+		t.Error("supervisor should be idle after giving up")
+	}
+	if status := statusText(tui); !strings.Contains(status, "gave up after 3 restart attempts") {
+		t.Errorf("status = %q, want it to report giving up", status)
+	}
+}
+
+func TestSuperviseClient_RecoversAfterCrashes(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.restartPolicy = restartPolicy{MaxRetries: 3, InitialBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond, StableAfter: time.Hour}
+	runTestApp(t, tui)
+	command, launches := crashingClient(t, 2)
+
+	done := startSupervisedClient(tui, command)
+	waitFor(t, "the third launch to keep running", func() bool {
+		return launches() == 3 && tui.supervisor.Running() != nil
+	})
+	waitFor(t, "the restart status", func() bool {
+		return strings.Contains(statusText(tui), "restarted with config: Test Config")
+	})
+
+	if _, err := tui.supervisor.Stop(); err != nil {
+		t.Fatalf("Stop() unexpected error: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("startClientProcess() did not return after Stop()")
+	}
+	if got := launches(); got != 3 {
+		t.Errorf("launches = %d, want no restart after Stop()", got)
+	}
+}
+
+func TestSuperviseClient_StopCancelsBackoff(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.restartPolicy = restartPolicy{MaxRetries: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour, StableAfter: time.Hour}
+	runTestApp(t, tui)
+	command, launches := crashingClient(t, 1000)
+
+	done := startSupervisedClient(tui, command)
+	waitFor(t, "the crash", tui.supervisor.Restarting)
+
+	tui.supervisor.Stop()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("startClientProcess() did not return after Stop()")
+	}
+	if got := launches(); got != 1 {
+		t.Errorf("launches = %d, want no restart after Stop()", got)
+	}
+}
+
+func TestSuperviseClient_StableUptimeResetsRetries(t *testing.T) {
+	tui := newTestTUI(t)
+	// Every run counts as stable, so a single retry is never used up
+	tui.restartPolicy = restartPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	runTestApp(t, tui)
+	command, launches := crashingClient(t, 1000)
+
+	done := startSupervisedClient(tui, command)
+	waitFor(t, "repeated restarts", func() bool { return launches() >= 4 })
+
+	tui.supervisor.Stop()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("startClientProcess() did not return after Stop()")
+	}
+}
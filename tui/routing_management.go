@@ -0,0 +1,203 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	routingBypassPrivate = "Bypass LAN and private IPs"
+	routingBlockAds      = "Block ads (geosite)"
+	routingDirectDomains = "Direct domain suffixes"
+	routingDirectCIDRs   = "Direct IP CIDRs"
+	routingDirectGeoIP   = "Direct GeoIP (e.g. cn)"
+	routingDirectGeoSite = "Direct GeoSite (e.g. cn)"
+	routingProxyDomains  = "Proxy domain suffixes"
+	routingBlockDomains  = "Block domain suffixes"
+)
+
+// routingLists are the comma separated list fields of the routing form
+type routingLists struct {
+	DirectDomains []string
+	DirectCIDRs   []string
+	DirectGeoIP   []string
+	DirectGeoSite []string
+	ProxyDomains  []string
+	BlockDomains  []string
+}
+
+// configRouting returns the routing rules a configuration connects with
+func configRouting(config Config) parser.Routing {
+	if config.Routing == nil {
+		return parser.DefaultRouting()
+	}
+	return *config.Routing
+}
+
+// routingFromLists builds the routing model: explicit block and proxy domains
+// are matched before the direct lists so they can carve out exceptions.
+func routingFromLists(bypassPrivate, blockAds bool, lists routingLists) parser.Routing {
+	routing := parser.Routing{BypassPrivate: bypassPrivate, BlockAds: blockAds}
+
+	candidates := []parser.Rule{
+		{Outbound: parser.OutboundBlock, DomainSuffix: lists.BlockDomains},
+		{Outbound: parser.OutboundProxy, DomainSuffix: lists.ProxyDomains},
+		{
+			Outbound:     parser.OutboundDirect,
+			DomainSuffix: lists.DirectDomains,
+			IPCIDR:       lists.DirectCIDRs,
+			GeoIP:        lists.DirectGeoIP,
+			GeoSite:      lists.DirectGeoSite,
+		},
+	}
+	for _, rule := range candidates {
+		if len(rule.DomainSuffix)+len(rule.IPCIDR)+len(rule.GeoIP)+len(rule.GeoSite) > 0 {
+			routing.Rules = append(routing.Rules, rule)
+		}
+	}
+	return routing
+}
+
+// routingToLists flattens routing rules back into the form lists
+func routingToLists(routing parser.Routing) routingLists {
+	var lists routingLists
+	for _, rule := range routing.Rules {
+		switch rule.Outbound {
+		case parser.OutboundBlock:
+			lists.BlockDomains = append(lists.BlockDomains, rule.DomainSuffix...)
+		case parser.OutboundProxy:
+			lists.ProxyDomains = append(lists.ProxyDomains, rule.DomainSuffix...)
+		case parser.OutboundDirect:
+			lists.DirectDomains = append(lists.DirectDomains, rule.DomainSuffix...)
+			lists.DirectCIDRs = append(lists.DirectCIDRs, rule.IPCIDR...)
+			lists.DirectGeoIP = append(lists.DirectGeoIP, rule.GeoIP...)
+			lists.DirectGeoSite = append(lists.DirectGeoSite, rule.GeoSite...)
+		}
+	}
+	return lists
+}
+
+// splitListField splits a comma or space separated form field
+func splitListField(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == ';'
+	})
+}
+
+// createRoutingView builds the per-configuration routing screen
+func (tui *TUI) createRoutingView() *tview.Flex {
+	tui.routingStatus = tview.NewTextView()
+	tui.routingStatus.SetTextAlign(tview.AlignCenter)
+	tui.routingStatus.SetBorder(true)
+	tui.routingStatus.SetTitle(" Status ")
+
+	tui.routingForm = tview.NewForm().
+		AddCheckbox(routingBypassPrivate, true, nil).
+		AddCheckbox(routingBlockAds, false, nil).
+		AddInputField(routingDirectDomains, "", 50, nil, nil).
+		AddInputField(routingDirectCIDRs, "", 50, nil, nil).
+		AddInputField(routingDirectGeoIP, "", 50, nil, nil).
+		AddInputField(routingDirectGeoSite, "", 50, nil, nil).
+		AddInputField(routingProxyDomains, "", 50, nil, nil).
+		AddInputField(routingBlockDomains, "", 50, nil, nil)
+
+	tui.routingForm.
+		AddButton("Save", func() {
+			tui.saveRoutingFromForm()
+		}).
+		AddButton("Back", func() {
+			tui.app.SetRoot(tui.mainFlex, true)
+		})
+	tui.routingForm.SetBorder(true)
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tui.routingForm, 0, 1, true).
+		AddItem(tui.routingStatus, 3, 0, false)
+}
+
+// showRouting opens the routing screen for the selected configuration
+func (tui *TUI) showRouting() {
+	currentIndex := tui.configList.GetCurrentItem()
+	if currentIndex < 0 || currentIndex >= len(tui.configs.Configurations) {
+		tui.updateStatus("Error: Please select a configuration to edit its routing", tcell.ColorYellow)
+		return
+	}
+
+	config := tui.configs.Configurations[currentIndex]
+	tui.routingConfigID = config.ID
+	routing := configRouting(config)
+	lists := routingToLists(routing)
+
+	tui.routingCheckbox(routingBypassPrivate).SetChecked(routing.BypassPrivate)
+	tui.routingCheckbox(routingBlockAds).SetChecked(routing.BlockAds)
+	tui.routingInput(routingDirectDomains).SetText(strings.Join(lists.DirectDomains, ", "))
+	tui.routingInput(routingDirectCIDRs).SetText(strings.Join(lists.DirectCIDRs, ", "))
+	tui.routingInput(routingDirectGeoIP).SetText(strings.Join(lists.DirectGeoIP, ", "))
+	tui.routingInput(routingDirectGeoSite).SetText(strings.Join(lists.DirectGeoSite, ", "))
+	tui.routingInput(routingProxyDomains).SetText(strings.Join(lists.ProxyDomains, ", "))
+	tui.routingInput(routingBlockDomains).SetText(strings.Join(lists.BlockDomains, ", "))
+
+	tui.routingForm.SetTitle(fmt.Sprintf(" Routing: %s ", config.Name))
+	tui.setRoutingStatus("Everything not matched goes through the proxy", tcell.ColorWhite)
+	tui.app.SetRoot(tui.routingView, true)
+	tui.app.SetFocus(tui.routingForm)
+}
+
+// saveRoutingFromForm validates the routing form and stores it on the configuration
+func (tui *TUI) saveRoutingFromForm() {
+	index := tui.findConfig(tui.routingConfigID)
+	if index < 0 {
+		tui.setRoutingStatus("Error: configuration no longer exists", tcell.ColorRed)
+		return
+	}
+
+	routing := routingFromLists(
+		tui.routingCheckbox(routingBypassPrivate).IsChecked(),
+		tui.routingCheckbox(routingBlockAds).IsChecked(),
+		routingLists{
+			DirectDomains: splitListField(tui.routingInput(routingDirectDomains).GetText()),
+			DirectCIDRs:   splitListField(tui.routingInput(routingDirectCIDRs).GetText()),
+			DirectGeoIP:   splitListField(tui.routingInput(routingDirectGeoIP).GetText()),
+			DirectGeoSite: splitListField(tui.routingInput(routingDirectGeoSite).GetText()),
+			ProxyDomains:  splitListField(tui.routingInput(routingProxyDomains).GetText()),
+			BlockDomains:  splitListField(tui.routingInput(routingBlockDomains).GetText()),
+		},
+	)
+	if err := routing.Validate(); err != nil {
+		tui.setRoutingStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	config := &tui.configs.Configurations[index]
+	config.Routing = &routing
+	if err := tui.saveConfigsToFile(); err != nil {
+		tui.setRoutingStatus(fmt.Sprintf("Error saving routing: %v", err), tcell.ColorRed)
+		return
+	}
+
+	message := fmt.Sprintf("Routing saved for %s (%d rules)", config.Name, len(routing.Rules))
+	if tui.isConnected && tui.connectedConfig == config.Name {
+		message += " - reconnect to apply"
+	}
+	tui.setRoutingStatus(message, tcell.ColorGreen)
+}
+
+// setRoutingStatus shows a message below the routing form
+func (tui *TUI) setRoutingStatus(message string, color tcell.Color) {
+	tui.routingStatus.SetText(message).SetTextColor(color)
+}
+
+// routingInput returns an input field of the routing form by label
+func (tui *TUI) routingInput(label string) *tview.InputField {
+	return tui.routingForm.GetFormItemByLabel(label).(*tview.InputField)
+}
+
+// routingCheckbox returns a checkbox of the routing form by label
+func (tui *TUI) routingCheckbox(label string) *tview.Checkbox {
+	return tui.routingForm.GetFormItemByLabel(label).(*tview.Checkbox)
+}
@@ -0,0 +1,136 @@
+package tui
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"tui_proxy_client/parser"
+)
+
+func TestRoutingLists_RoundTrip(t *testing.T) {
+	lists := routingLists{
+		DirectDomains: []string{"example.cn"},
+		DirectCIDRs:   []string{"203.0.113.0/24"},
+		DirectGeoIP:   []string{"cn"},
+		DirectGeoSite: []string{"cn"},
+		ProxyDomains:  []string{"google.cn"},
+		BlockDomains:  []string{"ads.example.com"},
+	}
+
+	routing := routingFromLists(true, true, lists)
+	if err := routing.Validate(); err != nil {
+		t.Fatalf("routingFromLists() produced invalid routing: %v", err)
+	}
+
+	outbounds := []string{}
+	for _, rule := range routing.Rules {
+		outbounds = append(outbounds, rule.Outbound)
+	}
+	if strings.Join(outbounds, ",") != "block,proxy,direct" {
+		t.Errorf("rule order = %v, want block, proxy, direct", outbounds)
+	}
+
+	if got := routingToLists(routing); !reflect.DeepEqual(got, lists) {
+		t.Errorf("routingToLists() = %+v, want %+v", got, lists)
+	}
+
+	// Empty lists produce no rules
+	if empty := routingFromLists(false, false, routingLists{}); len(empty.Rules) != 0 {
+		t.Errorf("routingFromLists() with empty lists = %+v, want no rules", empty.Rules)
+	}
+}
+
+func TestSplitListField(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", []string{}},
+		{"cn", []string{"cn"}},
+		{"a.com, b.com;c.com  d.com", []string{"a.com", "b.com", "c.com", "d.com"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitListField(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitListField(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSaveRoutingFromForm(t *testing.T) {
+	tui := newTestTUI(t)
+	now := time.Now().Format(time.RFC3339)
+	tui.configs.Configurations = []Config{
+		{ID: "1", Name: "VLESS Node", Protocol: "vless", Link: testVLESSLink, CreatedAt: now, LastUsed: now},
+	}
+	tui.refreshConfigList()
+	tui.configList.SetCurrentItem(0)
+
+	tui.showRouting()
+	if !tui.routingCheckbox(routingBypassPrivate).IsChecked() {
+		t.Error("new profile should bypass private addresses by default")
+	}
+
+	// Invalid CIDRs are rejected and nothing is stored
+	tui.routingInput(routingDirectCIDRs).SetText("not-a-cidr")
+	tui.saveRoutingFromForm()
+	if tui.configs.Configurations[0].Routing != nil {
+		t.Fatal("invalid routing should not be saved")
+	}
+	if !strings.HasPrefix(tui.routingStatus.GetText(true), "Error:") {
+		t.Errorf("status = %q, want an error", tui.routingStatus.GetText(true))
+	}
+
+	tui.routingInput(routingDirectCIDRs).SetText("")
+	tui.routingInput(routingDirectGeoIP).SetText("cn")
+	tui.routingCheckbox(routingBlockAds).SetChecked(true)
+	tui.saveRoutingFromForm()
+
+	routing := tui.configs.Configurations[0].Routing
+	if routing == nil || !routing.BlockAds || len(routing.Rules) != 1 {
+		t.Fatalf("saved routing = %+v, want block ads and one direct rule", routing)
+	}
+
+	// The profile's rules reach the rendered config
+	config, err := tui.parseForV2Ray(tui.configs.Configurations[0])
+	if err != nil {
+		t.Fatalf("parseForV2Ray() unexpected error: %v", err)
+	}
+	rules := config.(map[string]any)["routing"].(map[string]any)["rules"].([]map[string]any)
+	last := rules[len(rules)-1]
+	if ips := last["ip"].([]string); len(ips) != 1 || ips[0] != "geoip:cn" || last["outboundTag"] != parser.OutboundDirect {
+		t.Errorf("last V2Ray rule = %v, want geoip:cn direct", last)
+	}
+}
+
+func TestSaveRoutingFromForm_ListChanged(t *testing.T) {
+	tui := newTestTUI(t)
+	now := time.Now().Format(time.RFC3339)
+	tui.configs.Configurations = []Config{
+		{ID: "1", Name: "First", Protocol: "vless", Link: testVLESSLink, CreatedAt: now, LastUsed: now},
+		{ID: "2", Name: "Second", Protocol: "vless", Link: testVLESSLink, CreatedAt: now, LastUsed: now},
+	}
+	tui.refreshConfigList()
+	tui.configList.SetCurrentItem(1)
+	tui.showRouting()
+
+	// Deleting an earlier configuration shifts the edited one
+	tui.configs.Configurations = tui.configs.Configurations[1:]
+	tui.routingCheckbox(routingBlockAds).SetChecked(true)
+	tui.saveRoutingFromForm()
+	if routing := tui.configs.Configurations[0].Routing; routing == nil || !routing.BlockAds {
+		t.Fatalf("routing of Second = %+v, want it saved", routing)
+	}
+
+	// Deleting the edited configuration saves nothing
+	tui.configs.Configurations = []Config{{ID: "1", Name: "First", Protocol: "vless", Link: testVLESSLink}}
+	tui.saveRoutingFromForm()
+	if tui.configs.Configurations[0].Routing != nil {
+		t.Error("routing was saved on another configuration")
+	}
+	if status := tui.routingStatus.GetText(true); !strings.Contains(status, "no longer exists") {
+		t.Errorf("status = %q, want the configuration reported missing", status)
+	}
+}
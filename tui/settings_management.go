@@ -0,0 +1,314 @@
+package tui
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	settingsListen    = "Listen address"
+	settingsSocksPort = "SOCKS port"
+	settingsHTTPPort  = "HTTP port (0 = off)"
+	settingsMixed     = "Mixed SOCKS/HTTP inbound (sing-box)"
+	settingsUsername  = "Username (optional)"
+	settingsPassword  = "Password (optional)"
+	settingsRemoteDNS = "Remote DNS (via proxy)"
+	settingsDirectDNS = "Direct DNS"
+	settingsFakeIP    = "Fake-IP"
+	settingsStrategy  = "DNS strategy"
+	settingsTUNStack  = "TUN stack"
+	settingsAutoRoute = "TUN auto route"
+	settingsStrict    = "TUN strict route"
+	settingsExcludes  = "TUN excluded routes"
+	settingsSysProxy  = "System proxy on connect"
+	settingsEnvFile   = "Proxy env file (env mode)"
+	settingsLatency   = "Latency test"
+	settingsTestURL   = "Latency test URL (core mode)"
+	settingsStatsAPI  = "Stats API (host:port or off)"
+)
+
+// dnsStrategyOptions are the strategy dropdown entries, the first keeps the core default
+var dnsStrategyOptions = append([]string{"default"}, parser.DNSStrategies...)
+
+// renderOptions returns the client settings and the profile's routing to render config with
+func (tui *TUI) renderOptions(config Config) parser.Options {
+	return parser.Options{
+		Inbound: tui.configs.Settings.Inbound.WithDefaults(),
+		Routing: configRouting(config),
+		DNS:     tui.configs.Settings.DNS.WithDefaults(),
+		TUN:     tui.tunSettings(),
+		API:     tui.controlAPI(),
+	}
+}
+
+// activeInbound returns the inbound the running client listens on, or the
+// saved one when no client runs
+func (tui *TUI) activeInbound() parser.Inbound {
+	if tui.isConnected {
+		return tui.clientInbound.WithDefaults()
+	}
+	return tui.configs.Settings.Inbound.WithDefaults()
+}
+
+// proxyPort returns the local SOCKS port the client listens on
+func (tui *TUI) proxyPort() int {
+	return tui.activeInbound().SocksPort
+}
+
+// proxyAddress returns the local SOCKS address in host:port form
+func (tui *TUI) proxyAddress() string {
+	return inboundAddress(tui.activeInbound())
+}
+
+// inboundAddress returns the SOCKS address of in, in host:port form
+func inboundAddress(in parser.Inbound) string {
+	return net.JoinHostPort(in.Listen, strconv.Itoa(in.SocksPort))
+}
+
+// applySettings validates and stores new inbound, DNS and TUN settings
+func (tui *TUI) applySettings(settings Settings) error {
+	if err := settings.Inbound.Validate(); err != nil {
+		return err
+	}
+	if err := settings.DNS.Validate(); err != nil {
+		return err
+	}
+	if settings.TUN != nil {
+		if err := settings.TUN.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := settings.statsAPI().Validate(settings.Inbound); err != nil {
+		return err
+	}
+	switch settings.SystemProxy {
+	case systemProxyOff, systemProxyAuto, systemProxyGNOME, systemProxyKDE, systemProxyEnv:
+	default:
+		return fmt.Errorf("invalid system proxy mode: %s", settings.SystemProxy)
+	}
+	switch settings.LatencyTest {
+	case "", latencyCore:
+	default:
+		return fmt.Errorf("invalid latency test: %s", settings.LatencyTest)
+	}
+	if settings.LatencyURL != "" {
+		if u, err := url.Parse(settings.LatencyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid latency test URL: %s", settings.LatencyURL)
+		}
+	}
+
+	tui.configs.Settings = settings
+	if err := tui.saveConfigsToFile(); err != nil {
+		return fmt.Errorf("error saving settings: %w", err)
+	}
+	return nil
+}
+
+// createSettingsView builds the settings screen
+func (tui *TUI) createSettingsView() *tview.Flex {
+	tui.settingsStatus = tview.NewTextView()
+	tui.settingsStatus.SetText("Changes apply to the next connection")
+	tui.settingsStatus.SetTextAlign(tview.AlignCenter)
+	tui.settingsStatus.SetBorder(true)
+	tui.settingsStatus.SetTitle(" Status ")
+
+	tui.settingsForm = tview.NewForm().
+		AddInputField(settingsListen, "", 20, nil, nil).
+		AddInputField(settingsSocksPort, "", 6, tview.InputFieldInteger, nil).
+		AddInputField(settingsHTTPPort, "", 6, tview.InputFieldInteger, nil).
+		AddCheckbox(settingsMixed, false, nil).
+		AddInputField(settingsUsername, "", 20, nil, nil).
+		AddPasswordField(settingsPassword, "", 20, '*', nil).
+		AddInputField(settingsRemoteDNS, "", 40, nil, nil).
+		AddInputField(settingsDirectDNS, "", 40, nil, nil).
+		AddCheckbox(settingsFakeIP, false, nil).
+		AddDropDown(settingsStrategy, dnsStrategyOptions, 0, nil).
+		AddDropDown(settingsTUNStack, parser.TUNStacks, 0, nil).
+		AddCheckbox(settingsAutoRoute, true, nil).
+		AddCheckbox(settingsStrict, false, nil).
+		AddInputField(settingsExcludes, "", 40, nil, nil).
+		AddDropDown(settingsSysProxy, systemProxyModes, 0, nil).
+		AddInputField(settingsEnvFile, "", 40, nil, nil).
+		AddDropDown(settingsLatency, latencyModes, 0, nil).
+		AddInputField(settingsTestURL, "", 40, nil, nil).
+		AddInputField(settingsStatsAPI, "", 40, nil, nil)
+
+	tui.settingsForm.
+		AddButton("Save", func() {
+			tui.saveSettingsFromForm()
+		}).
+		AddButton("Back", func() {
+			tui.app.SetRoot(tui.mainFlex, true)
+		})
+	tui.settingsForm.SetBorder(true)
+	tui.settingsForm.SetTitle(" Local Proxy Settings ")
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tui.settingsForm, 0, 1, true).
+		AddItem(tui.settingsStatus, 3, 0, false)
+}
+
+// showSettings opens the settings screen filled with the current values
+func (tui *TUI) showSettings() {
+	in := tui.configs.Settings.Inbound.WithDefaults()
+
+	tui.settingsInput(settingsListen).SetText(in.Listen)
+	tui.settingsInput(settingsSocksPort).SetText(strconv.Itoa(in.SocksPort))
+	tui.settingsInput(settingsHTTPPort).SetText(strconv.Itoa(in.HTTPPort))
+	tui.settingsForm.GetFormItemByLabel(settingsMixed).(*tview.Checkbox).SetChecked(in.Mixed)
+	tui.settingsInput(settingsUsername).SetText(in.Username)
+	tui.settingsInput(settingsPassword).SetText(in.Password)
+
+	dns := tui.configs.Settings.DNS.WithDefaults()
+	tui.settingsInput(settingsRemoteDNS).SetText(dns.Remote)
+	tui.settingsInput(settingsDirectDNS).SetText(dns.Direct)
+	tui.settingsForm.GetFormItemByLabel(settingsFakeIP).(*tview.Checkbox).SetChecked(dns.FakeIP)
+	strategy := 0
+	for i, option := range dnsStrategyOptions {
+		if option == dns.Strategy {
+			strategy = i
+		}
+	}
+	tui.settingsForm.GetFormItemByLabel(settingsStrategy).(*tview.DropDown).SetCurrentOption(strategy)
+
+	tun := tui.tunSettings()
+	for i, stack := range parser.TUNStacks {
+		if stack == tun.Stack {
+			tui.settingsForm.GetFormItemByLabel(settingsTUNStack).(*tview.DropDown).SetCurrentOption(i)
+		}
+	}
+	tui.settingsForm.GetFormItemByLabel(settingsAutoRoute).(*tview.Checkbox).SetChecked(tun.AutoRoute)
+	tui.settingsForm.GetFormItemByLabel(settingsStrict).(*tview.Checkbox).SetChecked(tun.StrictRoute)
+	tui.settingsInput(settingsExcludes).SetText(strings.Join(tun.ExcludeRoutes, ", "))
+
+	sysProxy := 0
+	for i, mode := range systemProxyModes {
+		if mode == tui.configs.Settings.SystemProxy {
+			sysProxy = i
+		}
+	}
+	tui.settingsForm.GetFormItemByLabel(settingsSysProxy).(*tview.DropDown).SetCurrentOption(sysProxy)
+	tui.settingsInput(settingsEnvFile).SetText(tui.configs.Settings.ProxyEnvFile)
+
+	latencyMode := 0
+	for i, mode := range latencyModes {
+		if mode == tui.configs.Settings.LatencyTest {
+			latencyMode = i
+		}
+	}
+	tui.settingsForm.GetFormItemByLabel(settingsLatency).(*tview.DropDown).SetCurrentOption(latencyMode)
+	testURL := tui.configs.Settings.LatencyURL
+	if testURL == "" {
+		testURL = defaultLatencyURL
+	}
+	tui.settingsInput(settingsTestURL).SetText(testURL)
+	statsAPI := tui.configs.Settings.StatsAPI
+	if statsAPI == "" {
+		statsAPI = parser.DefaultAPIListen
+	}
+	tui.settingsInput(settingsStatsAPI).SetText(statsAPI)
+
+	tui.setSettingsStatus("Changes apply to the next connection", tcell.ColorWhite)
+	tui.app.SetRoot(tui.settingsView, true)
+	tui.app.SetFocus(tui.settingsForm)
+}
+
+// saveSettingsFromForm reads the settings form and stores it
+func (tui *TUI) saveSettingsFromForm() {
+	in := parser.Inbound{
+		Listen:   tui.settingsText(settingsListen),
+		Mixed:    tui.settingsForm.GetFormItemByLabel(settingsMixed).(*tview.Checkbox).IsChecked(),
+		Username: tui.settingsText(settingsUsername),
+		Password: tui.settingsText(settingsPassword),
+	}
+
+	var err error
+	if in.SocksPort, err = atoiField(tui.settingsText(settingsSocksPort)); err != nil {
+		tui.setSettingsStatus("Error: SOCKS port must be a number", tcell.ColorRed)
+		return
+	}
+	if in.HTTPPort, err = atoiField(tui.settingsText(settingsHTTPPort)); err != nil {
+		tui.setSettingsStatus("Error: HTTP port must be a number", tcell.ColorRed)
+		return
+	}
+
+	dns := parser.DNS{
+		Remote: tui.settingsText(settingsRemoteDNS),
+		Direct: tui.settingsText(settingsDirectDNS),
+		FakeIP: tui.settingsForm.GetFormItemByLabel(settingsFakeIP).(*tview.Checkbox).IsChecked(),
+	}
+	if index, _ := tui.settingsForm.GetFormItemByLabel(settingsStrategy).(*tview.DropDown).GetCurrentOption(); index > 0 {
+		dns.Strategy = dnsStrategyOptions[index]
+	}
+
+	tun := parser.TUN{
+		AutoRoute:     tui.settingsForm.GetFormItemByLabel(settingsAutoRoute).(*tview.Checkbox).IsChecked(),
+		StrictRoute:   tui.settingsForm.GetFormItemByLabel(settingsStrict).(*tview.Checkbox).IsChecked(),
+		ExcludeRoutes: splitListField(tui.settingsText(settingsExcludes)),
+	}
+	_, tun.Stack = tui.settingsForm.GetFormItemByLabel(settingsTUNStack).(*tview.DropDown).GetCurrentOption()
+
+	settings := Settings{
+		Inbound:      in,
+		DNS:          dns,
+		TUN:          &tun,
+		ProxyEnvFile: tui.settingsText(settingsEnvFile),
+	}
+	if index, mode := tui.settingsForm.GetFormItemByLabel(settingsSysProxy).(*tview.DropDown).GetCurrentOption(); index > 0 {
+		settings.SystemProxy = mode
+	}
+	if index, mode := tui.settingsForm.GetFormItemByLabel(settingsLatency).(*tview.DropDown).GetCurrentOption(); index > 0 {
+		settings.LatencyTest = mode
+	}
+	if testURL := tui.settingsText(settingsTestURL); testURL != defaultLatencyURL {
+		settings.LatencyURL = testURL
+	}
+	if statsAPI := tui.settingsText(settingsStatsAPI); statsAPI != parser.DefaultAPIListen {
+		settings.StatsAPI = statsAPI
+	}
+
+	if err := tui.applySettings(settings); err != nil {
+		tui.setSettingsStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	message := fmt.Sprintf("Settings saved: proxy on %s", inboundAddress(tui.configs.Settings.Inbound.WithDefaults()))
+	color := tcell.ColorGreen
+	if tui.isConnected {
+		message += fmt.Sprintf(" at the next connect, %s keeps running on %s until then", tui.clientType, tui.proxyAddress())
+		color = tcell.ColorYellow
+	}
+	tui.setSettingsStatus(message, color)
+	tui.updateConnectionStatus()
+}
+
+// atoiField parses an optional numeric form field, empty meaning 0
+func atoiField(text string) (int, error) {
+	if text == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(text)
+}
+
+// setSettingsStatus shows a message below the settings form
+func (tui *TUI) setSettingsStatus(message string, color tcell.Color) {
+	tui.settingsStatus.SetText(message).SetTextColor(color)
+}
+
+// settingsInput returns an input field of the settings form by label
+func (tui *TUI) settingsInput(label string) *tview.InputField {
+	return tui.settingsForm.GetFormItemByLabel(label).(*tview.InputField)
+}
+
+// settingsText returns the trimmed text of a settings form field
+func (tui *TUI) settingsText(label string) string {
+	return strings.TrimSpace(tui.settingsInput(label).GetText())
+}
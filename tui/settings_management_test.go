@@ -0,0 +1,186 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"testing"
+
+	"tui_proxy_client/parser"
+
+	"github.com/rivo/tview"
+)
+
+func TestApplySettings(t *testing.T) {
+	tests := []struct {
+		name        string
+		inbound     parser.Inbound
+		expectError bool
+		wantAddress string
+	}{
+		{
+			name:        "defaults",
+			inbound:     parser.Inbound{},
+			wantAddress: "127.0.0.1:1080",
+		},
+		{
+			name:        "custom port and listen",
+			inbound:     parser.Inbound{Listen: "0.0.0.0", SocksPort: 7890, HTTPPort: 7891},
+			wantAddress: "0.0.0.0:7890",
+		},
+		{
+			name:        "invalid listen address",
+			inbound:     parser.Inbound{Listen: "localhost"},
+			expectError: true,
+			wantAddress: "127.0.0.1:1080",
+		},
+		{
+			name:        "username without password",
+			inbound:     parser.Inbound{Username: "user"},
+			expectError: true,
+			wantAddress: "127.0.0.1:1080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := newTestTUI(t)
+
+			err := tui.applySettings(Settings{Inbound: tt.inbound})
+			if (err != nil) != tt.expectError {
+				t.Fatalf("applySettings() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got := tui.proxyAddress(); got != tt.wantAddress {
+				t.Errorf("proxyAddress() = %q, want %q", got, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestApplySettings_Persists(t *testing.T) {
+	tui := newTestTUI(t)
+
+	if err := tui.applySettings(Settings{Inbound: parser.Inbound{SocksPort: 2080, Username: "user", Password: "pass"}}); err != nil {
+		t.Fatalf("applySettings() unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile("configs.json")
+	if err != nil {
+		t.Fatalf("configs.json not written: %v", err)
+	}
+	var stored ConfigStorage
+	if err := json.Unmarshal(data, &stored); err != nil {
+		t.Fatalf("configs.json is not valid JSON: %v", err)
+	}
+	if stored.Settings.Inbound.SocksPort != 2080 || stored.Settings.Inbound.Username != "user" {
+		t.Errorf("stored settings = %+v, want socks port 2080 and user", stored.Settings.Inbound)
+	}
+}
+
+func TestSaveSettingsFromForm(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.showSettings()
+
+	tui.settingsInput(settingsSocksPort).SetText("7890")
+	tui.settingsInput(settingsHTTPPort).SetText("7890")
+	tui.saveSettingsFromForm()
+
+	if !strings.HasPrefix(tui.settingsStatus.GetText(true), "Error:") {
+		t.Errorf("saveSettingsFromForm() with equal ports should fail, status %q", tui.settingsStatus.GetText(true))
+	}
+	if tui.proxyPort() != parser.DefaultSocksPort {
+		t.Errorf("proxyPort() = %d after failed save, want %d", tui.proxyPort(), parser.DefaultSocksPort)
+	}
+
+	tui.settingsInput(settingsHTTPPort).SetText("")
+	tui.saveSettingsFromForm()
+
+	if tui.proxyPort() != 7890 {
+		t.Errorf("proxyPort() = %d, want 7890", tui.proxyPort())
+	}
+	if !strings.Contains(tui.settingsStatus.GetText(true), "127.0.0.1:7890") {
+		t.Errorf("status %q should mention the new proxy address", tui.settingsStatus.GetText(true))
+	}
+}
+
+func TestSaveSettingsFromForm_WhileConnected(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	running := listener.Addr().(*net.TCPAddr).Port
+
+	tui := newTestTUI(t)
+	tui.isConnected, tui.clientType = true, "xray"
+	tui.clientInbound = parser.Inbound{Listen: "127.0.0.1", SocksPort: running}
+	tui.showSettings()
+
+	tui.settingsInput(settingsSocksPort).SetText("7890")
+	tui.saveSettingsFromForm()
+
+	if tui.configs.Settings.Inbound.SocksPort != 7890 {
+		t.Errorf("saved SOCKS port = %d, want 7890", tui.configs.Settings.Inbound.SocksPort)
+	}
+	status := tui.settingsStatus.GetText(true)
+	if !strings.Contains(status, "127.0.0.1:7890 at the next connect") || !strings.Contains(status, fmt.Sprintf("127.0.0.1:%d", running)) {
+		t.Errorf("status %q should say the new port applies at the next connect and name the running one", status)
+	}
+
+	tui.isConnected = true // no supervised client, so the status check above disconnected
+	if tui.proxyPort() != running || !tui.isProxyPortInUse() {
+		t.Errorf("proxyPort() = %d, want the running client's port %d in use", tui.proxyPort(), running)
+	}
+}
+
+func TestRenderConfig_UsesSettings(t *testing.T) {
+	tui := newTestTUI(t)
+	if err := tui.applySettings(Settings{Inbound: parser.Inbound{SocksPort: 7890}}); err != nil {
+		t.Fatalf("applySettings() unexpected error: %v", err)
+	}
+
+	config, err := tui.parseForSingBox(Config{Protocol: "vless", Link: testVLESSLink})
+	if err != nil {
+		t.Fatalf("parseForSingBox() unexpected error: %v", err)
+	}
+
+	inbounds := config.(map[string]any)["inbounds"].([]map[string]any)
+	if port := inbounds[0]["listen_port"]; port != 7890 {
+		t.Errorf("sing-box inbound listen_port = %v, want 7890", port)
+	}
+}
+
+func TestSaveSettingsFromForm_DNS(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.showSettings()
+
+	if got := tui.settingsText(settingsRemoteDNS); got != parser.DefaultRemoteDNS {
+		t.Errorf("remote DNS field = %q, want the default %q", got, parser.DefaultRemoteDNS)
+	}
+
+	tui.settingsInput(settingsRemoteDNS).SetText("ftp://1.1.1.1")
+	tui.saveSettingsFromForm()
+	if !strings.HasPrefix(tui.settingsStatus.GetText(true), "Error:") {
+		t.Errorf("saveSettingsFromForm() with an invalid DNS server should fail, status %q", tui.settingsStatus.GetText(true))
+	}
+
+	tui.settingsInput(settingsRemoteDNS).SetText("tls://1.1.1.1")
+	tui.settingsForm.GetFormItemByLabel(settingsStrategy).(*tview.DropDown).SetCurrentOption(1)
+	tui.saveSettingsFromForm()
+
+	dns := tui.configs.Settings.DNS
+	if dns.Remote != "tls://1.1.1.1" || dns.Strategy != parser.DNSStrategies[0] {
+		t.Errorf("stored DNS = %+v, want tls://1.1.1.1 and %s", dns, parser.DNSStrategies[0])
+	}
+
+	config, err := tui.parseForSingBox(Config{Protocol: "vless", Link: testVLESSLink})
+	if err != nil {
+		t.Fatalf("parseForSingBox() unexpected error: %v", err)
+	}
+	servers := config.(map[string]any)["dns"].(map[string]any)["servers"].([]map[string]any)
+	if servers[0]["address"] != "tls://1.1.1.1" {
+		t.Errorf("sing-box remote DNS = %v, want tls://1.1.1.1", servers[0]["address"])
+	}
+}
@@ -0,0 +1,239 @@
+package tui
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"tui_proxy_client/parser"
+)
+
+const (
+	statsOff        = "off" // Settings.StatsAPI value disabling traffic statistics
+	trafficInterval = 2 * time.Second
+)
+
+// statsAPI returns the API the client is rendered with and polled on
+func (s Settings) statsAPI() parser.API {
+	switch s.StatsAPI {
+	case statsOff:
+		return parser.API{}
+	case "":
+		return parser.API{Listen: parser.DefaultAPIListen}
+	default:
+		return parser.API{Listen: s.StatsAPI}
+	}
+}
+
+// controlAPI returns the API clients are rendered with: the stats API setting
+// protected by this session's secret
+func (tui *TUI) controlAPI() parser.API {
+	api := tui.configs.Settings.statsAPI()
+	if api.Listen != "" {
+		api.Secret = tui.apiSecret
+	}
+	return api
+}
+
+// trafficCounters are the bytes a core has moved since it started
+type trafficCounters struct {
+	Up   int64
+	Down int64
+}
+
+// trafficSource queries the running core for its traffic counters
+type trafficSource interface {
+	Query(ctx context.Context) (trafficCounters, error)
+}
+
+// v2rayTraffic queries the StatsService of V2Ray and Xray through the core's
+// own api command, which speaks its gRPC protocol
+type v2rayTraffic struct {
+	runner  commandRunner
+	command []string
+}
+
+// Query runs the api command, killed when ctx is done, and sums its inbound counters
+func (v v2rayTraffic) Query(ctx context.Context) (trafficCounters, error) {
+	output, err := v.runner.RunContext(ctx, v.command[0], v.command[1:]...)
+	if err != nil {
+		return trafficCounters{}, err
+	}
+	return parseV2RayStats(output)
+}
+
+// parseV2RayStats sums the uplink and downlink counters of every inbound but
+// the API itself. Values are numbers, or strings in protobuf JSON, which also
+// leaves out zero values.
+func parseV2RayStats(output string) (trafficCounters, error) {
+	var resp struct {
+		Stat []struct {
+			Name  string          `json:"name"`
+			Value json.RawMessage `json:"value"`
+		} `json:"stat"`
+	}
+	if err := json.Unmarshal([]byte(output), &resp); err != nil {
+		return trafficCounters{}, fmt.Errorf("stats API: %w", err)
+	}
+
+	var counters trafficCounters
+	for _, stat := range resp.Stat {
+		// inbound>>>socks-in>>>traffic>>>uplink
+		parts := strings.Split(stat.Name, ">>>")
+		if len(parts) != 4 || parts[0] != "inbound" || parts[1] == "api" || parts[2] != "traffic" {
+			continue
+		}
+		var value int64
+		if text := strings.Trim(string(stat.Value), `"`); text != "" {
+			var err error
+			if value, err = strconv.ParseInt(text, 10, 64); err != nil {
+				return trafficCounters{}, fmt.Errorf("stats API: invalid value for %s: %s", stat.Name, stat.Value)
+			}
+		}
+		switch parts[3] {
+		case "uplink":
+			counters.Up += value
+		case "downlink":
+			counters.Down += value
+		}
+	}
+	return counters, nil
+}
+
+// trafficSourceFor returns how to query the statistics of clientType served on api
+func trafficSourceFor(clientType string, api parser.API, runner commandRunner) (trafficSource, bool) {
+	clash, err := newClashAPI(api.Listen, api.Secret)
+	if err != nil {
+		return nil, false
+	}
+	address := strings.TrimPrefix(clash.baseURL, "http://")
+
+	switch clientType {
+	case "singbox", clientSingBoxTUN, "mihomo":
+		return clash, true
+	case "xray":
+		return v2rayTraffic{runner: runner, command: []string{"xray", "api", "statsquery", "--server=" + address}}, true
+	case "v2ray":
+		return v2rayTraffic{runner: runner, command: []string{"v2ray", "api", "stats", "--server=" + address, "-json"}}, true
+	default:
+		return nil, false
+	}
+}
+
+// trafficMeter turns successive counter samples into throughput and session totals
+type trafficMeter struct {
+	last     trafficCounters
+	offset   trafficCounters // totals of earlier runs of a restarted core
+	lastAt   time.Time
+	upRate   float64 // bytes per second
+	downRate float64
+	sampled  bool
+}
+
+// add records a sample taken at the given time
+func (m *trafficMeter) add(c trafficCounters, at time.Time) {
+	if m.sampled {
+		if c.Up < m.last.Up || c.Down < m.last.Down {
+			// The core was restarted and its counters started over
+			m.offset.Up += m.last.Up
+			m.offset.Down += m.last.Down
+			m.last = trafficCounters{}
+		}
+		if seconds := at.Sub(m.lastAt).Seconds(); seconds > 0 {
+			m.upRate = float64(c.Up-m.last.Up) / seconds
+			m.downRate = float64(c.Down-m.last.Down) / seconds
+		}
+	}
+	m.last, m.lastAt, m.sampled = c, at, true
+}
+
+// totals returns the bytes moved since connecting
+func (m *trafficMeter) totals() trafficCounters {
+	return trafficCounters{Up: m.offset.Up + m.last.Up, Down: m.offset.Down + m.last.Down}
+}
+
+// String formats throughput and session totals, empty before the first sample
+func (m *trafficMeter) String() string {
+	if m == nil || !m.sampled {
+		return ""
+	}
+	totals := m.totals()
+	return fmt.Sprintf("↑ %s/s ↓ %s/s (session ↑ %s ↓ %s)",
+		formatBytes(int64(m.upRate)), formatBytes(int64(m.downRate)), formatBytes(totals.Up), formatBytes(totals.Down))
+}
+
+// formatBytes formats a byte count with a binary unit
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for rest := n / unit; rest >= unit; rest /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// startTrafficPolling polls the statistics API of the client just started.
+// Must run on the UI goroutine.
+func (tui *TUI) startTrafficPolling(clientType string) {
+	tui.stopTrafficPolling()
+
+	api := tui.controlAPI()
+	if api.Listen == "" {
+		return
+	}
+	source, ok := trafficSourceFor(clientType, api, tui.runner)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	meter := &trafficMeter{}
+	tui.traffic, tui.trafficCancel = meter, cancel
+	go tui.pollTraffic(ctx, source, meter, trafficInterval)
+}
+
+// stopTrafficPolling ends polling and clears the statistics. Must run on the UI goroutine.
+func (tui *TUI) stopTrafficPolling() {
+	if tui.trafficCancel != nil {
+		tui.trafficCancel()
+	}
+	tui.traffic, tui.trafficCancel = nil, nil
+}
+
+// pollTraffic samples source into meter every interval until ctx is cancelled
+func (tui *TUI) pollTraffic(ctx context.Context, source trafficSource, meter *trafficMeter, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		queryCtx, cancel := context.WithTimeout(ctx, interval)
+		counters, err := source.Query(queryCtx)
+		cancel()
+		if err != nil {
+			// The core is still starting, restarting or was built without the API
+			continue
+		}
+
+		at := time.Now()
+		tui.app.QueueUpdateDraw(func() {
+			if tui.traffic != meter {
+				return // stopped or replaced by a newer connection
+			}
+			meter.add(counters, at)
+			tui.updateConnectionStatus()
+		})
+	}
+}
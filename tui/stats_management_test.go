@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tui_proxy_client/parser"
+)
+
+func TestParseV2RayStats(t *testing.T) {
+	tests := []struct {
+		name        string
+		output      string
+		want        trafficCounters
+		expectError bool
+	}{
+		{
+			name:   "numbers",
+			output: `{"stat":[{"name":"inbound>>>socks-in>>>traffic>>>uplink","value":100},{"name":"inbound>>>socks-in>>>traffic>>>downlink","value":2000}]}`,
+			want:   trafficCounters{Up: 100, Down: 2000},
+		},
+		{
+			name:   "protobuf JSON strings and omitted zeros",
+			output: `{"stat":[{"name":"inbound>>>socks-in>>>traffic>>>uplink","value":"100"},{"name":"inbound>>>http-in>>>traffic>>>uplink","value":"50"},{"name":"inbound>>>http-in>>>traffic>>>downlink"}]}`,
+			want:   trafficCounters{Up: 150},
+		},
+		{
+			name:   "API inbound and outbounds ignored",
+			output: `{"stat":[{"name":"inbound>>>api>>>traffic>>>uplink","value":7},{"name":"outbound>>>proxy>>>traffic>>>downlink","value":9},{"name":"inbound>>>socks-in>>>traffic>>>downlink","value":3}]}`,
+			want:   trafficCounters{Down: 3},
+		},
+		{name: "no stats yet", output: `{}`},
+		{name: "not JSON", output: "failed to dial", expectError: true},
+		{name: "invalid value", output: `{"stat":[{"name":"inbound>>>socks-in>>>traffic>>>uplink","value":"many"}]}`, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseV2RayStats(tt.output)
+			if (err != nil) != tt.expectError {
+				t.Fatalf("parseV2RayStats() error = %v, expectError %v", err, tt.expectError)
+			}
+			if got != tt.want {
+				t.Errorf("parseV2RayStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrafficSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/connections" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"downloadTotal":4096,"uploadTotal":512,"connections":[]}`))
+	}))
+	defer server.Close()
+
+	source, ok := trafficSourceFor("mihomo", parser.API{Listen: strings.TrimPrefix(server.URL, "http://")}, nil)
+	if !ok {
+		t.Fatal("trafficSourceFor(mihomo) found no source")
+	}
+	counters, err := source.Query(context.Background())
+	if err != nil {
+		t.Fatalf("Query() unexpected error: %v", err)
+	}
+	if counters != (trafficCounters{Up: 512, Down: 4096}) {
+		t.Errorf("Query() = %+v", counters)
+	}
+
+	runner := newFakeRunner()
+	runner.outputs["xray api statsquery --server=127.0.0.1:9090"] = `{"stat":[{"name":"inbound>>>socks-in>>>traffic>>>uplink","value":"10"}]}`
+	source, ok = trafficSourceFor("xray", parser.API{Listen: "0.0.0.0:9090"}, runner)
+	if !ok {
+		t.Fatal("trafficSourceFor(xray) found no source")
+	}
+	if counters, err := source.Query(context.Background()); err != nil || counters.Up != 10 {
+		t.Errorf("Query() = %+v, %v; want 10 bytes up through the loopback address", counters, err)
+	}
+
+	if _, ok := trafficSourceFor("unknown", parser.API{Listen: "127.0.0.1:9090"}, runner); ok {
+		t.Error("trafficSourceFor() should not poll an unknown client")
+	}
+}
+
+func TestTrafficMeter(t *testing.T) {
+	start := time.Now()
+	var meter trafficMeter
+
+	if meter.String() != "" {
+		t.Errorf("String() = %q before the first sample, want empty", meter.String())
+	}
+
+	meter.add(trafficCounters{Up: 1024, Down: 2048}, start)
+	meter.add(trafficCounters{Up: 3072, Down: 2048 + 4<<20}, start.Add(2*time.Second))
+	if meter.upRate != 1024 || meter.downRate != 2<<20 {
+		t.Errorf("rates = %v / %v, want 1024 and 2 MiB per second", meter.upRate, meter.downRate)
+	}
+	if got := meter.String(); got != "↑ 1.0 KB/s ↓ 2.0 MB/s (session ↑ 3.0 KB ↓ 4.0 MB)" {
+		t.Errorf("String() = %q", got)
+	}
+
+	// A restarted core counts from zero again, the session totals keep growing
+	meter.add(trafficCounters{Up: 1024, Down: 0}, start.Add(3*time.Second))
+	if totals := meter.totals(); totals.Up != 4096 || totals.Down != 2048+4<<20 {
+		t.Errorf("totals after restart = %+v", totals)
+	}
+	if meter.upRate != 1024 || meter.downRate != 0 {
+		t.Errorf("rates after restart = %v / %v", meter.upRate, meter.downRate)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{n: 0, want: "0 B"},
+		{n: 1023, want: "1023 B"},
+		{n: 1536, want: "1.5 KB"},
+		{n: 5 << 30, want: "5.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+// countingSource returns growing counters and fails its first query
+type countingSource struct {
+	queries atomic.Int64
+}
+
+func (s *countingSource) Query(ctx context.Context) (trafficCounters, error) {
+	n := s.queries.Add(1)
+	if n == 1 {
+		return trafficCounters{}, context.DeadlineExceeded
+	}
+	return trafficCounters{Up: n * 100, Down: n * 1000}, nil
+}
+
+func TestPollTraffic(t *testing.T) {
+	tui := newTestTUI(t)
+	runTestApp(t, tui)
+
+	source := &countingSource{}
+	ctx, cancel := context.WithCancel(context.Background())
+	meter := &trafficMeter{}
+	tui.app.QueueUpdate(func() {
+		tui.traffic, tui.trafficCancel = meter, cancel
+	})
+	go tui.pollTraffic(ctx, source, meter, 10*time.Millisecond)
+
+	waitFor(t, "two traffic samples", func() bool {
+		var rate float64
+		tui.app.QueueUpdate(func() { rate = meter.downRate })
+		return rate > 0
+	})
+
+	tui.app.QueueUpdate(tui.stopTrafficPolling)
+	stopped := source.queries.Load()
+	time.Sleep(50 * time.Millisecond)
+	if source.queries.Load() > stopped+1 {
+		t.Error("pollTraffic() kept polling after stopTrafficPolling()")
+	}
+	var traffic *trafficMeter
+	tui.app.QueueUpdate(func() { traffic = tui.traffic })
+	if traffic != nil {
+		t.Error("stopTrafficPolling() should clear the meter")
+	}
+}
+
+func TestApplySettings_StatsAPI(t *testing.T) {
+	tests := []struct {
+		name        string
+		settings    Settings
+		expectError bool
+	}{
+		{name: "default", settings: Settings{}},
+		{name: "off", settings: Settings{StatsAPI: statsOff}},
+		{name: "custom port", settings: Settings{StatsAPI: "127.0.0.1:19090"}},
+		{name: "not an address", settings: Settings{StatsAPI: "9090"}, expectError: true},
+		{name: "default clashes with SOCKS port", settings: Settings{Inbound: parser.Inbound{SocksPort: 9090}}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tui := newTestTUI(t)
+			if err := tui.applySettings(tt.settings); (err != nil) != tt.expectError {
+				t.Errorf("applySettings() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestV2RayTraffic_QueryCancelled(t *testing.T) {
+	runner := newFakeRunner()
+	source := v2rayTraffic{runner: runner, command: []string{"xray", "api", "statsquery"}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := source.Query(ctx); err == nil {
+		t.Error("Query() with a cancelled context should fail")
+	}
+	if len(runner.calls) != 0 {
+		t.Errorf("Query() ran %v with a cancelled context", runner.calls)
+	}
+
+	// A core that never answers is killed once the query times out
+	t.Setenv("GO_WANT_HELPER_PROCESS", "sleep")
+	source = v2rayTraffic{runner: execRunner{}, command: []string{os.Args[0], "-test.run=^TestHelperProcess$"}}
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := source.Query(ctx); err == nil {
+		t.Error("Query() of a hanging core should fail")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Query() returned after %v, want it to stop at the deadline", elapsed)
+	}
+}
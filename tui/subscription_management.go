@@ -0,0 +1,455 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const (
+	defaultSubscriptionUserAgent = "tui_proxy_client"
+	maxSubscriptionSize          = 10 << 20
+)
+
+// subscriptionClient is the HTTP client used to download subscriptions
+var subscriptionClient = &http.Client{Timeout: 30 * time.Second}
+
+// fetchSubscription downloads a subscription and returns the links it contains
+func fetchSubscription(client *http.Client, sub Subscription) ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet, sub.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscription URL: %w", err)
+	}
+
+	userAgent := sub.UserAgent
+	if userAgent == "" {
+		userAgent = defaultSubscriptionUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch failed: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSubscriptionSize))
+	if err != nil {
+		return nil, fmt.Errorf("read failed: %w", err)
+	}
+
+	return parser.SplitLinks(string(body))
+}
+
+// applySubscriptionLinks upserts the configs of a subscription from freshly fetched links.
+// Links that are no longer served are removed; links that fail to parse are skipped.
+func (tui *TUI) applySubscriptionLinks(subID string, links []string) (added, updated, removed, failed int) {
+	subIndex := tui.findSubscription(subID)
+	if subIndex < 0 {
+		return
+	}
+	sub := tui.configs.Subscriptions[subIndex]
+
+	existing := make(map[string]int)
+	for i, config := range tui.configs.Configurations {
+		if config.SubscriptionID == subID {
+			existing[config.Link] = i
+		}
+	}
+
+	served := make(map[string]bool)
+	now := time.Now().Format(time.RFC3339)
+	for i, link := range links {
+		if served[link] {
+			continue
+		}
+		// Still served, so a saved configuration keeps its ID even when the
+		// link does not parse this time
+		served[link] = true
+
+		node, err := parser.Parse(link)
+		if err != nil {
+			failed++
+			continue
+		}
+
+		name := node.Remark
+		if name == "" {
+			name = fmt.Sprintf("%s %d", sub.Name, i+1)
+		}
+
+		if index, ok := existing[link]; ok {
+			tui.configs.Configurations[index].Name = name
+			tui.configs.Configurations[index].Protocol = node.Protocol
+			updated++
+			continue
+		}
+
+		tui.configs.Configurations = append(tui.configs.Configurations, Config{
+			ID:             tui.nextConfigID(),
+			Name:           name,
+			Protocol:       node.Protocol,
+			Link:           link,
+			CreatedAt:      now,
+			LastUsed:       now,
+			SubscriptionID: subID,
+		})
+		added++
+	}
+
+	kept := tui.configs.Configurations[:0]
+	for _, config := range tui.configs.Configurations {
+		if config.SubscriptionID == subID && !served[config.Link] {
+			removed++
+			continue
+		}
+		kept = append(kept, config)
+	}
+	tui.configs.Configurations = kept
+
+	return
+}
+
+// addSubscription validates and stores a new subscription
+func (tui *TUI) addSubscription(name, rawURL, userAgent string, interval int) (Subscription, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Subscription{}, fmt.Errorf("subscription URL must be an http(s) URL")
+	}
+	if interval < 0 {
+		return Subscription{}, fmt.Errorf("update interval cannot be negative")
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = u.Host
+	}
+
+	sub := Subscription{
+		ID:             tui.nextSubscriptionID(),
+		Name:           name,
+		URL:            rawURL,
+		UserAgent:      strings.TrimSpace(userAgent),
+		UpdateInterval: interval,
+	}
+	tui.configs.Subscriptions = append(tui.configs.Subscriptions, sub)
+
+	return sub, tui.saveConfigsToFile()
+}
+
+// deleteSubscription removes a subscription together with its configs
+func (tui *TUI) deleteSubscription(subID string) (Subscription, error) {
+	subIndex := tui.findSubscription(subID)
+	if subIndex < 0 {
+		return Subscription{}, fmt.Errorf("subscription not found")
+	}
+	sub := tui.configs.Subscriptions[subIndex]
+
+	tui.configs.Subscriptions = append(tui.configs.Subscriptions[:subIndex], tui.configs.Subscriptions[subIndex+1:]...)
+
+	kept := tui.configs.Configurations[:0]
+	for _, config := range tui.configs.Configurations {
+		if config.SubscriptionID != subID {
+			kept = append(kept, config)
+		}
+	}
+	tui.configs.Configurations = kept
+
+	return sub, tui.saveConfigsToFile()
+}
+
+// refreshSubscription fetches a subscription in the background and applies the result on the UI thread
+func (tui *TUI) refreshSubscription(sub Subscription) {
+	tui.setSubscriptionStatus(fmt.Sprintf("Fetching subscription '%s'...", sub.Name), tcell.ColorBlue)
+
+	go func() {
+		links, fetchErr := fetchSubscription(subscriptionClient, sub)
+
+		tui.app.QueueUpdateDraw(func() {
+			tui.finishSubscriptionRefresh(sub.ID, links, fetchErr)
+		})
+	}()
+}
+
+// finishSubscriptionRefresh records the fetch result and upserts the configs
+func (tui *TUI) finishSubscriptionRefresh(subID string, links []string, fetchErr error) {
+	subIndex := tui.findSubscription(subID)
+	if subIndex < 0 {
+		return
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	sub := &tui.configs.Subscriptions[subIndex]
+	sub.LastAttempt = now
+
+	if fetchErr != nil {
+		sub.LastError = fetchErr.Error()
+		tui.saveConfigsToFile()
+		tui.refreshSubscriptionList()
+		tui.setSubscriptionStatus(fmt.Sprintf("Error refreshing '%s': %v", sub.Name, fetchErr), tcell.ColorRed)
+		return
+	}
+
+	sub.LastFetched = now
+	sub.LastError = ""
+	name := sub.Name
+
+	added, updated, removed, failed := tui.applySubscriptionLinks(subID, links)
+	if err := tui.saveConfigsToFile(); err != nil {
+		tui.setSubscriptionStatus(fmt.Sprintf("Error saving subscription '%s': %v", name, err), tcell.ColorRed)
+		return
+	}
+
+	tui.refreshConfigList()
+	tui.refreshSubscriptionList()
+	tui.setSubscriptionStatus(fmt.Sprintf("Subscription '%s' refreshed: %d added, %d updated, %d removed, %d failed to parse",
+		name, added, updated, removed, failed), tcell.ColorGreen)
+}
+
+// refreshDueSubscriptions refreshes every subscription whose update interval has elapsed
+func (tui *TUI) refreshDueSubscriptions() {
+	for _, sub := range tui.configs.Subscriptions {
+		if subscriptionDue(sub, time.Now()) {
+			tui.refreshSubscription(sub)
+		}
+	}
+}
+
+// subscriptionDue reports whether a subscription should be refreshed at now
+func subscriptionDue(sub Subscription, now time.Time) bool {
+	if sub.UpdateInterval <= 0 {
+		return false
+	}
+
+	last, err := time.Parse(time.RFC3339, sub.LastAttempt)
+	if err != nil {
+		return true
+	}
+
+	return now.Sub(last) >= time.Duration(sub.UpdateInterval)*time.Minute
+}
+
+// periodicSubscriptionRefresh checks every minute for subscriptions due for refresh
+func (tui *TUI) periodicSubscriptionRefresh() {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if tui.app == nil {
+			continue
+		}
+
+		tui.app.QueueUpdateDraw(func() {
+			tui.refreshDueSubscriptions()
+		})
+	}
+}
+
+// findSubscription returns the index of a subscription by ID, or -1
+func (tui *TUI) findSubscription(subID string) int {
+	for i, sub := range tui.configs.Subscriptions {
+		if sub.ID == subID {
+			return i
+		}
+	}
+	return -1
+}
+
+// subscriptionName returns the display name of a subscription by ID
+func (tui *TUI) subscriptionName(subID string) string {
+	if i := tui.findSubscription(subID); i >= 0 {
+		return tui.configs.Subscriptions[i].Name
+	}
+	return ""
+}
+
+// nextConfigID returns an ID not used by any saved configuration
+func (tui *TUI) nextConfigID() string {
+	maxID := len(tui.configs.Configurations)
+	for _, config := range tui.configs.Configurations {
+		if id, err := strconv.Atoi(config.ID); err == nil && id > maxID {
+			maxID = id
+		}
+	}
+	return strconv.Itoa(maxID + 1)
+}
+
+// nextSubscriptionID returns an ID not used by any subscription
+func (tui *TUI) nextSubscriptionID() string {
+	maxID := 0
+	for _, sub := range tui.configs.Subscriptions {
+		if id, err := strconv.Atoi(strings.TrimPrefix(sub.ID, "sub-")); err == nil && id > maxID {
+			maxID = id
+		}
+	}
+	return fmt.Sprintf("sub-%d", maxID+1)
+}
+
+// showSubscriptions switches to the subscription management screen
+func (tui *TUI) showSubscriptions() {
+	tui.refreshSubscriptionList()
+	tui.app.SetRoot(tui.subscriptionView, true)
+	tui.app.SetFocus(tui.subscriptionForm)
+}
+
+// createSubscriptionView builds the subscription management screen
+func (tui *TUI) createSubscriptionView() *tview.Flex {
+	tui.subscriptionList = tview.NewList()
+	tui.subscriptionList.SetBorder(true)
+	tui.subscriptionList.SetTitle(" Subscriptions ")
+
+	tui.subscriptionStatus = tview.NewTextView()
+	tui.subscriptionStatus.SetText("Add a subscription URL to import its configurations")
+	tui.subscriptionStatus.SetTextAlign(tview.AlignCenter)
+	tui.subscriptionStatus.SetBorder(true)
+	tui.subscriptionStatus.SetTitle(" Status ")
+
+	tui.subscriptionForm = tview.NewForm().
+		AddInputField("Name", "", 30, nil, nil).
+		AddInputField("URL", "", 60, nil, nil).
+		AddInputField("Update interval (minutes, 0 = manual)", "720", 6, tview.InputFieldInteger, nil).
+		AddInputField("User-Agent", "", 30, nil, nil)
+
+	tui.subscriptionForm.
+		AddButton("Add", func() {
+			tui.addSubscriptionFromForm()
+		}).
+		AddButton("Refresh", func() {
+			if sub, ok := tui.selectedSubscription(); ok {
+				tui.refreshSubscription(sub)
+			}
+		}).
+		AddButton("Refresh All", func() {
+			for _, sub := range tui.configs.Subscriptions {
+				tui.refreshSubscription(sub)
+			}
+		}).
+		AddButton("Delete", func() {
+			tui.deleteSelectedSubscription()
+		}).
+		AddButton("Back", func() {
+			tui.app.SetRoot(tui.mainFlex, true)
+		})
+	tui.subscriptionForm.SetBorder(true)
+	tui.subscriptionForm.SetTitle(" New Subscription ")
+
+	content := tview.NewFlex().SetDirection(tview.FlexColumn).
+		AddItem(tui.subscriptionList, 0, 1, false).
+		AddItem(tui.subscriptionForm, 0, 1, true)
+
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(content, 0, 1, true).
+		AddItem(tui.subscriptionStatus, 3, 0, false)
+}
+
+// addSubscriptionFromForm reads the form, stores the subscription and fetches it
+func (tui *TUI) addSubscriptionFromForm() {
+	name := tui.formText("Name")
+	rawURL := tui.formText("URL")
+	userAgent := tui.formText("User-Agent")
+
+	interval := 0
+	if text := tui.formText("Update interval (minutes, 0 = manual)"); text != "" {
+		var err error
+		if interval, err = strconv.Atoi(text); err != nil {
+			tui.setSubscriptionStatus("Error: Update interval must be a number", tcell.ColorRed)
+			return
+		}
+	}
+
+	sub, err := tui.addSubscription(name, rawURL, userAgent, interval)
+	if err != nil {
+		tui.setSubscriptionStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.formInput("Name").SetText("")
+	tui.formInput("URL").SetText("")
+	tui.refreshSubscriptionList()
+	tui.refreshSubscription(sub)
+}
+
+// deleteSelectedSubscription removes the subscription highlighted in the list
+func (tui *TUI) deleteSelectedSubscription() {
+	sub, ok := tui.selectedSubscription()
+	if !ok {
+		return
+	}
+
+	if _, err := tui.deleteSubscription(sub.ID); err != nil {
+		tui.setSubscriptionStatus(fmt.Sprintf("Error deleting subscription: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.refreshConfigList()
+	tui.refreshSubscriptionList()
+	tui.setSubscriptionStatus(fmt.Sprintf("Subscription '%s' and its configurations deleted", sub.Name), tcell.ColorGreen)
+}
+
+// selectedSubscription returns the subscription highlighted in the list
+func (tui *TUI) selectedSubscription() (Subscription, bool) {
+	index := tui.subscriptionList.GetCurrentItem()
+	if index < 0 || index >= len(tui.configs.Subscriptions) {
+		tui.setSubscriptionStatus("Please select a subscription first", tcell.ColorYellow)
+		return Subscription{}, false
+	}
+	return tui.configs.Subscriptions[index], true
+}
+
+// refreshSubscriptionList redraws the subscription list
+func (tui *TUI) refreshSubscriptionList() {
+	tui.subscriptionList.Clear()
+	if len(tui.configs.Subscriptions) == 0 {
+		tui.subscriptionList.AddItem("No subscriptions yet", "Fill in the form and press Add", 0, nil)
+		return
+	}
+
+	for _, sub := range tui.configs.Subscriptions {
+		count := 0
+		for _, config := range tui.configs.Configurations {
+			if config.SubscriptionID == sub.ID {
+				count++
+			}
+		}
+
+		lastFetched := "never"
+		if len(sub.LastFetched) >= 16 {
+			lastFetched = strings.Replace(sub.LastFetched[:16], "T", " ", 1)
+		}
+		secondaryText := fmt.Sprintf("%d config(s) | Last fetched: %s", count, lastFetched)
+		if sub.LastError != "" {
+			secondaryText += " | Error: " + sub.LastError
+		}
+
+		tui.subscriptionList.AddItem(sub.Name, secondaryText, 0, nil)
+	}
+}
+
+// setSubscriptionStatus shows a message on the subscription screen
+func (tui *TUI) setSubscriptionStatus(message string, color tcell.Color) {
+	tui.subscriptionStatus.SetText(message).SetTextColor(color)
+}
+
+// formInput returns an input field of the subscription form by label
+func (tui *TUI) formInput(label string) *tview.InputField {
+	return tui.subscriptionForm.GetFormItemByLabel(label).(*tview.InputField)
+}
+
+// formText returns the trimmed text of a subscription form field
+func (tui *TUI) formText(label string) string {
+	return strings.TrimSpace(tui.formInput(label).GetText())
+}
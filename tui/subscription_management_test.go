@@ -0,0 +1,230 @@
+package tui
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testVMessLink = "vmess://eyJ2IjoiMiIsInBzIjoiVGVzdCIsImFkZCI6ImV4YW1wbGUuY29tIiwicG9ydCI6IjQ0MyIsImlkIjoiMTExMTExMTEtMTExMS0xMTExLTExMTEtMTExMTExMTExMTExIiwiYWlkIjoiMCIsIm5ldCI6IndzIiwidHlwZSI6IiIsImhvc3QiOiJleGFtcGxlLmNvbSIsInBhdGgiOiIvd3MiLCJ0bHMiOiJ0bHMiLCJzbmkiOiJleGFtcGxlLmNvbSIsImZwIjoiY2hyb21lIiwic2N5IjoiYXV0byJ9"
+	testSSLink    = "ss://YWVzLTI1Ni1nY206cGFzc3dvcmQ=@example.com:8388#SS%20Node"
+	testVLESSLink = "vless://12345678-1234-1234-1234-123456789012@example.com:443?type=tcp#VLESS%20Node"
+)
+
+// newTestTUI creates a TUI with empty storage working in a temporary directory
+func newTestTUI(t *testing.T) *TUI {
+	t.Chdir(t.TempDir())
+	tui := NewTUI()
+	tui.configs = ConfigStorage{}
+	return tui
+}
+
+func TestFetchSubscription(t *testing.T) {
+	var userAgent string
+	body := base64.StdEncoding.EncodeToString([]byte(strings.Join([]string{testVMessLink, testSSLink, testVLESSLink}, "\n")))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.Header.Get("User-Agent")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	links, err := fetchSubscription(server.Client(), Subscription{URL: server.URL, UserAgent: "clash-verge/1.0"})
+	if err != nil {
+		t.Fatalf("fetchSubscription() unexpected error: %v", err)
+	}
+
+	if len(links) != 3 {
+		t.Errorf("fetchSubscription() returned %d links, want 3", len(links))
+	}
+	if userAgent != "clash-verge/1.0" {
+		t.Errorf("fetchSubscription() User-Agent = %q, want clash-verge/1.0", userAgent)
+	}
+
+	// Default user agent is sent when none is configured
+	if _, err := fetchSubscription(server.Client(), Subscription{URL: server.URL}); err != nil {
+		t.Fatalf("fetchSubscription() unexpected error: %v", err)
+	}
+	if userAgent != defaultSubscriptionUserAgent {
+		t.Errorf("fetchSubscription() User-Agent = %q, want %s", userAgent, defaultSubscriptionUserAgent)
+	}
+}
+
+func TestFetchSubscription_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/empty" {
+			return
+		}
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	if _, err := fetchSubscription(server.Client(), Subscription{URL: server.URL}); err == nil {
+		t.Error("fetchSubscription() expected error for HTTP 500")
+	}
+	if _, err := fetchSubscription(server.Client(), Subscription{URL: server.URL + "/empty"}); err == nil {
+		t.Error("fetchSubscription() expected error for empty body")
+	}
+}
+
+func TestTUI_ApplySubscriptionLinks(t *testing.T) {
+	tui := newTestTUI(t)
+	tui.configs.Configurations = []Config{{ID: "1", Name: "Manual", Protocol: "vmess", Link: testVMessLink}}
+
+	sub, err := tui.addSubscription("Provider", "https://example.com/sub", "", 60)
+	if err != nil {
+		t.Fatalf("addSubscription() unexpected error: %v", err)
+	}
+
+	added, updated, removed, failed := tui.applySubscriptionLinks(sub.ID, []string{testVMessLink, testSSLink, "garbage://link", testSSLink})
+	if added != 2 || updated != 0 || removed != 0 || failed != 1 {
+		t.Errorf("applySubscriptionLinks() = %d/%d/%d/%d, want 2/0/0/1", added, updated, removed, failed)
+	}
+	if len(tui.configs.Configurations) != 3 {
+		t.Fatalf("expected 3 configurations, got %d", len(tui.configs.Configurations))
+	}
+
+	ssConfig := tui.configs.Configurations[2]
+	if ssConfig.Name != "SS Node" || ssConfig.Protocol != "shadowsocks" || ssConfig.SubscriptionID != sub.ID {
+		t.Errorf("applySubscriptionLinks() added %+v, want SS Node (shadowsocks) in %s", ssConfig, sub.ID)
+	}
+	if ssConfig.ID == tui.configs.Configurations[1].ID {
+		t.Error("applySubscriptionLinks() should assign unique IDs")
+	}
+
+	// Second fetch: SS disappears, VLESS is new, VMess stays
+	added, updated, removed, failed = tui.applySubscriptionLinks(sub.ID, []string{testVMessLink, testVLESSLink})
+	if added != 1 || updated != 1 || removed != 1 || failed != 0 {
+		t.Errorf("applySubscriptionLinks() = %d/%d/%d/%d, want 1/1/1/0", added, updated, removed, failed)
+	}
+
+	var manual, fromSub int
+	for _, config := range tui.configs.Configurations {
+		if config.SubscriptionID == sub.ID {
+			fromSub++
+		} else {
+			manual++
+		}
+	}
+	if manual != 1 || fromSub != 2 {
+		t.Errorf("expected 1 manual and 2 subscription configs, got %d and %d", manual, fromSub)
+	}
+}
+
+func TestTUI_ApplySubscriptionLinks_KeepsUnparsableServedLinks(t *testing.T) {
+	tui := newTestTUI(t)
+	sub, err := tui.addSubscription("Provider", "https://example.com/sub", "", 60)
+	if err != nil {
+		t.Fatalf("addSubscription() unexpected error: %v", err)
+	}
+	// Saved by an earlier refresh, the link no longer parses
+	stale := "vless://not-a-uuid@example.com:443#Old"
+	tui.configs.Configurations = []Config{{ID: "7", Name: "Old", Protocol: "vless", Link: stale, SubscriptionID: sub.ID}}
+
+	added, updated, removed, failed := tui.applySubscriptionLinks(sub.ID, []string{stale, testSSLink})
+	if added != 1 || updated != 0 || removed != 0 || failed != 1 {
+		t.Errorf("applySubscriptionLinks() = %d/%d/%d/%d, want 1/0/0/1", added, updated, removed, failed)
+	}
+	if tui.findConfig("7") < 0 {
+		t.Error("applySubscriptionLinks() removed a configuration the server still serves")
+	}
+
+	if _, _, removed, _ = tui.applySubscriptionLinks(sub.ID, []string{testSSLink}); removed != 1 || tui.findConfig("7") >= 0 {
+		t.Errorf("applySubscriptionLinks() removed %d, want the configuration no longer served removed", removed)
+	}
+}
+
+func TestTUI_AddSubscription_InvalidInput(t *testing.T) {
+	tui := newTestTUI(t)
+
+	for _, rawURL := range []string{"", "ftp://example.com/sub", "not a url", "https://"} {
+		if _, err := tui.addSubscription("Bad", rawURL, "", 0); err == nil {
+			t.Errorf("addSubscription(%q) expected error but got none", rawURL)
+		}
+	}
+	if _, err := tui.addSubscription("Bad", "https://example.com", "", -5); err == nil {
+		t.Error("addSubscription() expected error for negative interval")
+	}
+	if len(tui.configs.Subscriptions) != 0 {
+		t.Errorf("addSubscription() stored %d invalid subscriptions", len(tui.configs.Subscriptions))
+	}
+
+	sub, err := tui.addSubscription("", "https://sub.example.com/path", "", 0)
+	if err != nil {
+		t.Fatalf("addSubscription() unexpected error: %v", err)
+	}
+	if sub.Name != "sub.example.com" {
+		t.Errorf("addSubscription() default name = %q, want sub.example.com", sub.Name)
+	}
+}
+
+func TestTUI_DeleteSubscription(t *testing.T) {
+	tui := newTestTUI(t)
+
+	sub, _ := tui.addSubscription("Provider", "https://example.com/sub", "", 0)
+	tui.applySubscriptionLinks(sub.ID, []string{testVMessLink, testSSLink})
+	tui.configs.Configurations = append(tui.configs.Configurations, Config{ID: "99", Name: "Manual", Protocol: "vless", Link: testVLESSLink})
+
+	if _, err := tui.deleteSubscription(sub.ID); err != nil {
+		t.Fatalf("deleteSubscription() unexpected error: %v", err)
+	}
+
+	if len(tui.configs.Subscriptions) != 0 {
+		t.Error("deleteSubscription() should remove the subscription")
+	}
+	if len(tui.configs.Configurations) != 1 || tui.configs.Configurations[0].Name != "Manual" {
+		t.Errorf("deleteSubscription() should keep only manual configs, got %+v", tui.configs.Configurations)
+	}
+
+	if _, err := tui.deleteSubscription(sub.ID); err == nil {
+		t.Error("deleteSubscription() expected error for unknown subscription")
+	}
+}
+
+func TestTUI_FinishSubscriptionRefresh(t *testing.T) {
+	tui := newTestTUI(t)
+	sub, _ := tui.addSubscription("Provider", "https://example.com/sub", "", 60)
+
+	tui.finishSubscriptionRefresh(sub.ID, nil, errors.New("connection refused"))
+	stored := tui.configs.Subscriptions[0]
+	if stored.LastError == "" || stored.LastFetched != "" || stored.LastAttempt == "" {
+		t.Errorf("finishSubscriptionRefresh() error case stored %+v", stored)
+	}
+
+	tui.finishSubscriptionRefresh(sub.ID, []string{testVMessLink}, nil)
+	stored = tui.configs.Subscriptions[0]
+	if stored.LastError != "" || stored.LastFetched == "" {
+		t.Errorf("finishSubscriptionRefresh() success case stored %+v", stored)
+	}
+	if len(tui.configs.Configurations) != 1 {
+		t.Errorf("finishSubscriptionRefresh() should add 1 config, got %d", len(tui.configs.Configurations))
+	}
+}
+
+func TestSubscriptionDue(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name     string
+		sub      Subscription
+		expected bool
+	}{
+		{"manual only", Subscription{UpdateInterval: 0}, false},
+		{"never fetched", Subscription{UpdateInterval: 60}, true},
+		{"recently fetched", Subscription{UpdateInterval: 60, LastAttempt: now.Add(-10 * time.Minute).Format(time.RFC3339)}, false},
+		{"interval elapsed", Subscription{UpdateInterval: 60, LastAttempt: now.Add(-2 * time.Hour).Format(time.RFC3339)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := subscriptionDue(tt.sub, now); result != tt.expected {
+				t.Errorf("subscriptionDue() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,387 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// System proxy modes stored in Settings.SystemProxy
+const (
+	systemProxyOff   = ""
+	systemProxyAuto  = "auto"
+	systemProxyGNOME = "gnome"
+	systemProxyKDE   = "kde"
+	systemProxyEnv   = "env"
+)
+
+// systemProxyModes are the settings dropdown entries; "off" is stored as empty
+var systemProxyModes = []string{"off", systemProxyAuto, systemProxyGNOME, systemProxyKDE, systemProxyEnv}
+
+// defaultProxyEnvFile is written in env mode when no path is configured
+const defaultProxyEnvFile = "proxy.env"
+
+// commandRunner runs external commands, replaced by a fake in tests
+type commandRunner interface {
+	Run(name string, args ...string) (string, error)
+	RunContext(ctx context.Context, name string, args ...string) (string, error)
+	LookPath(name string) (string, error)
+}
+
+// execRunner runs commands with a timeout
+type execRunner struct{}
+
+// Run executes a command and returns its trimmed stdout
+func (r execRunner) Run(name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return r.RunContext(ctx, name, args...)
+}
+
+// RunContext executes a command, killed once ctx is done, and returns its trimmed stdout
+func (execRunner) RunContext(ctx context.Context, name string, args ...string) (string, error) {
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	return strings.TrimSpace(string(output)), err
+}
+
+// LookPath resolves a command in PATH
+func (execRunner) LookPath(name string) (string, error) {
+	return exec.LookPath(name)
+}
+
+// proxyEndpoint is the local proxy the desktop or shell is pointed at
+type proxyEndpoint struct {
+	Host      string
+	SocksPort int
+	HTTPPort  int // 0 when the client serves no HTTP proxy
+	Username  string
+	Password  string
+}
+
+// systemProxy points the desktop or shell at the local proxy
+type systemProxy interface {
+	Name() string
+	// Apply sets the proxy and returns a function restoring the previous state
+	Apply(endpoint proxyEndpoint) (restore func() error, err error)
+}
+
+// systemProxyState remembers how to undo the applied system proxy
+type systemProxyState struct {
+	mu      sync.Mutex
+	name    string
+	restore func() error
+}
+
+// newSystemProxy returns the system proxy for a settings mode, nil when off
+func newSystemProxy(mode, envFile string, runner commandRunner, desktop string) systemProxy {
+	if mode == systemProxyAuto {
+		switch desktop := strings.ToUpper(desktop); {
+		case strings.Contains(desktop, "KDE"):
+			mode = systemProxyKDE
+		case strings.Contains(desktop, "GNOME"), strings.Contains(desktop, "UNITY"),
+			strings.Contains(desktop, "CINNAMON"), strings.Contains(desktop, "BUDGIE"):
+			mode = systemProxyGNOME
+		default:
+			mode = systemProxyEnv
+		}
+	}
+
+	switch mode {
+	case systemProxyGNOME:
+		return gnomeProxy{runner: runner}
+	case systemProxyKDE:
+		return kdeProxy{runner: runner}
+	case systemProxyEnv:
+		if envFile == "" {
+			envFile = defaultProxyEnvFile
+		}
+		return envFileProxy{path: envFile}
+	default:
+		return nil
+	}
+}
+
+// proxyEndpointFor returns the local proxy a client type serves with the current settings
+func (tui *TUI) proxyEndpointFor(clientType string) proxyEndpoint {
+	in := tui.configs.Settings.Inbound.WithDefaults()
+
+	host := in.Listen
+	if ip := net.ParseIP(host); ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+
+	endpoint := proxyEndpoint{
+		Host:      host,
+		SocksPort: in.SocksPort,
+		HTTPPort:  in.HTTPPort,
+		Username:  in.Username,
+		Password:  in.Password,
+	}
+	if endpoint.HTTPPort == 0 && (clientType == "mihomo" || (in.Mixed && strings.HasPrefix(clientType, "singbox"))) {
+		endpoint.HTTPPort = in.SocksPort
+	}
+	return endpoint
+}
+
+// enableSystemProxy applies the configured system proxy for a started client.
+// It returns a short note for the status line, empty when the feature is off.
+func (tui *TUI) enableSystemProxy(clientType string) string {
+	proxy := newSystemProxy(tui.configs.Settings.SystemProxy, tui.configs.Settings.ProxyEnvFile, tui.runner, os.Getenv("XDG_CURRENT_DESKTOP"))
+	if proxy == nil {
+		return ""
+	}
+
+	// A previous connection may still hold the original values
+	tui.restoreSystemProxy()
+
+	restore, err := proxy.Apply(tui.proxyEndpointFor(clientType))
+	if err != nil {
+		return fmt.Sprintf(" (system proxy not set: %v)", err)
+	}
+
+	tui.systemProxy.mu.Lock()
+	tui.systemProxy.name = proxy.Name()
+	tui.systemProxy.restore = restore
+	tui.systemProxy.mu.Unlock()
+
+	return fmt.Sprintf(" - system proxy set (%s)", proxy.Name())
+}
+
+// restoreSystemProxy puts back the values replaced by enableSystemProxy
+func (tui *TUI) restoreSystemProxy() error {
+	tui.systemProxy.mu.Lock()
+	restore := tui.systemProxy.restore
+	name := tui.systemProxy.name
+	tui.systemProxy.restore = nil
+	tui.systemProxy.name = ""
+	tui.systemProxy.mu.Unlock()
+
+	if restore == nil {
+		return nil
+	}
+	if err := restore(); err != nil {
+		return fmt.Errorf("restoring %s proxy: %w", name, err)
+	}
+	return nil
+}
+
+// gnomeProxy sets org.gnome.system.proxy through gsettings
+type gnomeProxy struct {
+	runner commandRunner
+}
+
+// gnomeProxyKeys are the schema/key pairs saved before and restored after a connection
+var gnomeProxyKeys = [][2]string{
+	{"org.gnome.system.proxy", "mode"},
+	{"org.gnome.system.proxy.socks", "host"},
+	{"org.gnome.system.proxy.socks", "port"},
+	{"org.gnome.system.proxy.http", "host"},
+	{"org.gnome.system.proxy.http", "port"},
+	{"org.gnome.system.proxy.https", "host"},
+	{"org.gnome.system.proxy.https", "port"},
+}
+
+// Name identifies the desktop in status messages
+func (gnomeProxy) Name() string {
+	return "GNOME"
+}
+
+// Apply saves the current gsettings values and switches the proxy mode to manual
+func (p gnomeProxy) Apply(endpoint proxyEndpoint) (func() error, error) {
+	saved := make(map[[2]string]string, len(gnomeProxyKeys))
+	for _, key := range gnomeProxyKeys {
+		value, err := p.runner.Run("gsettings", "get", key[0], key[1])
+		if err != nil {
+			return nil, fmt.Errorf("gsettings get %s %s: %w", key[0], key[1], err)
+		}
+		saved[key] = value
+	}
+
+	restore := func() error {
+		var firstErr error
+		for _, key := range gnomeProxyKeys {
+			if _, err := p.runner.Run("gsettings", "set", key[0], key[1], saved[key]); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	values := [][3]string{
+		{"org.gnome.system.proxy.socks", "host", endpoint.Host},
+		{"org.gnome.system.proxy.socks", "port", strconv.Itoa(endpoint.SocksPort)},
+	}
+	if endpoint.HTTPPort > 0 {
+		values = append(values,
+			[3]string{"org.gnome.system.proxy.http", "host", endpoint.Host},
+			[3]string{"org.gnome.system.proxy.http", "port", strconv.Itoa(endpoint.HTTPPort)},
+			[3]string{"org.gnome.system.proxy.https", "host", endpoint.Host},
+			[3]string{"org.gnome.system.proxy.https", "port", strconv.Itoa(endpoint.HTTPPort)},
+		)
+	}
+	values = append(values, [3]string{"org.gnome.system.proxy", "mode", "manual"})
+
+	for _, v := range values {
+		if _, err := p.runner.Run("gsettings", "set", v[0], v[1], v[2]); err != nil {
+			restore()
+			return nil, fmt.Errorf("gsettings set %s %s: %w", v[0], v[1], err)
+		}
+	}
+	return restore, nil
+}
+
+// kdeProxy sets the KIO proxy in kioslaverc through kwriteconfig
+type kdeProxy struct {
+	runner commandRunner
+}
+
+// kdeProxyKeys are the kioslaverc keys saved before and restored after a connection
+var kdeProxyKeys = []string{"ProxyType", "socksProxy", "httpProxy", "httpsProxy"}
+
+// Name identifies the desktop in status messages
+func (kdeProxy) Name() string {
+	return "KDE"
+}
+
+// Apply saves the current kioslaverc values and sets a manual proxy
+func (p kdeProxy) Apply(endpoint proxyEndpoint) (func() error, error) {
+	write, read := "kwriteconfig6", "kreadconfig6"
+	if _, err := p.runner.LookPath(write); err != nil {
+		write, read = "kwriteconfig5", "kreadconfig5"
+		if _, err := p.runner.LookPath(write); err != nil {
+			return nil, fmt.Errorf("kwriteconfig5/6 not found")
+		}
+	}
+
+	args := func(key string, extra ...string) []string {
+		return append([]string{"--file", "kioslaverc", "--group", "Proxy Settings", "--key", key}, extra...)
+	}
+
+	saved := make(map[string]string, len(kdeProxyKeys))
+	for _, key := range kdeProxyKeys {
+		value, err := p.runner.Run(read, args(key)...)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", read, key, err)
+		}
+		saved[key] = value
+	}
+
+	restore := func() error {
+		var firstErr error
+		for _, key := range kdeProxyKeys {
+			var err error
+			if saved[key] == "" {
+				_, err = p.runner.Run(write, args(key, "--delete")...)
+			} else {
+				_, err = p.runner.Run(write, args(key, saved[key])...)
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		p.notify()
+		return firstErr
+	}
+
+	values := [][2]string{
+		{"socksProxy", fmt.Sprintf("socks://%s %d", endpoint.Host, endpoint.SocksPort)},
+	}
+	if endpoint.HTTPPort > 0 {
+		values = append(values,
+			[2]string{"httpProxy", fmt.Sprintf("http://%s %d", endpoint.Host, endpoint.HTTPPort)},
+			[2]string{"httpsProxy", fmt.Sprintf("http://%s %d", endpoint.Host, endpoint.HTTPPort)},
+		)
+	}
+	values = append(values, [2]string{"ProxyType", "1"}) // 1 = manual
+
+	for _, v := range values {
+		if _, err := p.runner.Run(write, args(v[0], v[1])...); err != nil {
+			restore()
+			return nil, fmt.Errorf("%s %s: %w", write, v[0], err)
+		}
+	}
+	p.notify()
+	return restore, nil
+}
+
+// notify tells running KDE applications to reload the proxy settings
+func (p kdeProxy) notify() {
+	p.runner.Run("dbus-send", "--type=signal", "/KIO/Scheduler",
+		"org.kde.KIO.Scheduler.reparseSlaveConfiguration", "string:")
+}
+
+// envFileProxy writes proxy environment variables to a file users can source
+type envFileProxy struct {
+	path string
+}
+
+// Name identifies the env file in status messages
+func (p envFileProxy) Name() string {
+	return "source " + p.path
+}
+
+// Apply writes the env file and returns a function restoring the previous content
+func (p envFileProxy) Apply(endpoint proxyEndpoint) (func() error, error) {
+	previous, err := os.ReadFile(p.path)
+	existed := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if err := os.WriteFile(p.path, []byte(proxyEnvContent(endpoint)), 0600); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		if existed {
+			return os.WriteFile(p.path, previous, 0600)
+		}
+		if err := os.Remove(p.path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}, nil
+}
+
+// proxyEnvContent renders the export lines of the env file
+func proxyEnvContent(endpoint proxyEndpoint) string {
+	proxyURL := func(scheme string, port int) string {
+		u := url.URL{Scheme: scheme, Host: net.JoinHostPort(endpoint.Host, strconv.Itoa(port))}
+		if endpoint.Username != "" && endpoint.Password != "" {
+			u.User = url.UserPassword(endpoint.Username, endpoint.Password)
+		}
+		return u.String()
+	}
+
+	socks := proxyURL("socks5", endpoint.SocksPort)
+	http := socks
+	if endpoint.HTTPPort > 0 {
+		http = proxyURL("http", endpoint.HTTPPort)
+	}
+
+	var b strings.Builder
+	b.WriteString("# Written by tui_proxy_client, removed on disconnect\n")
+	for _, v := range [][2]string{
+		{"http_proxy", http},
+		{"https_proxy", http},
+		{"all_proxy", socks},
+		{"no_proxy", "localhost,127.0.0.1,::1"},
+	} {
+		fmt.Fprintf(&b, "export %s=%s\n", v[0], shellQuote(v[1]))
+		fmt.Fprintf(&b, "export %s=%s\n", strings.ToUpper(v[0]), shellQuote(v[1]))
+	}
+	return b.String()
+}
+
+// shellQuote single-quotes s for a POSIX shell, so sourcing the file runs nothing
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
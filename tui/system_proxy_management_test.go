@@ -0,0 +1,303 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tui_proxy_client/parser"
+)
+
+// fakeRunner records commands and serves canned output keyed by the joined command line
+type fakeRunner struct {
+	calls   []string
+	outputs map[string]string
+	fail    map[string]bool
+	paths   map[string]bool
+}
+
+func newFakeRunner() *fakeRunner {
+	return &fakeRunner{outputs: map[string]string{}, fail: map[string]bool{}, paths: map[string]bool{}}
+}
+
+func (f *fakeRunner) Run(name string, args ...string) (string, error) {
+	line := strings.Join(append([]string{name}, args...), " ")
+	f.calls = append(f.calls, line)
+	if f.fail[line] {
+		return "", fmt.Errorf("%s failed", name)
+	}
+	return f.outputs[line], nil
+}
+
+func (f *fakeRunner) RunContext(ctx context.Context, name string, args ...string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return f.Run(name, args...)
+}
+
+func (f *fakeRunner) LookPath(name string) (string, error) {
+	if f.paths[name] {
+		return "/usr/bin/" + name, nil
+	}
+	return "", fmt.Errorf("%s not found", name)
+}
+
+// callsWith returns the recorded calls starting with prefix
+func (f *fakeRunner) callsWith(prefix string) []string {
+	var calls []string
+	for _, call := range f.calls {
+		if strings.HasPrefix(call, prefix) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+func TestNewSystemProxy(t *testing.T) {
+	runner := newFakeRunner()
+
+	tests := []struct {
+		mode    string
+		desktop string
+		want    string
+	}{
+		{systemProxyOff, "GNOME", ""},
+		{systemProxyAuto, "ubuntu:GNOME", "GNOME"},
+		{systemProxyAuto, "X-Cinnamon", "GNOME"},
+		{systemProxyAuto, "KDE", "KDE"},
+		{systemProxyAuto, "", "source " + defaultProxyEnvFile},
+		{systemProxyKDE, "GNOME", "KDE"},
+		{systemProxyEnv, "KDE", "source " + defaultProxyEnvFile},
+	}
+
+	for _, tt := range tests {
+		proxy := newSystemProxy(tt.mode, "", runner, tt.desktop)
+		got := ""
+		if proxy != nil {
+			got = proxy.Name()
+		}
+		if got != tt.want {
+			t.Errorf("newSystemProxy(%q, desktop %q) = %q, want %q", tt.mode, tt.desktop, got, tt.want)
+		}
+	}
+}
+
+func TestGnomeProxy(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["gsettings get org.gnome.system.proxy mode"] = "'none'"
+	runner.outputs["gsettings get org.gnome.system.proxy.socks port"] = "0"
+
+	restore, err := gnomeProxy{runner: runner}.Apply(proxyEndpoint{Host: "127.0.0.1", SocksPort: 1080, HTTPPort: 8080})
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+
+	sets := runner.callsWith("gsettings set")
+	want := []string{
+		"gsettings set org.gnome.system.proxy.socks host 127.0.0.1",
+		"gsettings set org.gnome.system.proxy.socks port 1080",
+		"gsettings set org.gnome.system.proxy.http host 127.0.0.1",
+		"gsettings set org.gnome.system.proxy.http port 8080",
+		"gsettings set org.gnome.system.proxy.https host 127.0.0.1",
+		"gsettings set org.gnome.system.proxy.https port 8080",
+		"gsettings set org.gnome.system.proxy mode manual",
+	}
+	if strings.Join(sets, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Apply() ran:\n%s\nwant:\n%s", strings.Join(sets, "\n"), strings.Join(want, "\n"))
+	}
+
+	runner.calls = nil
+	if err := restore(); err != nil {
+		t.Fatalf("restore() unexpected error: %v", err)
+	}
+	restored := strings.Join(runner.calls, "\n")
+	for _, want := range []string{
+		"gsettings set org.gnome.system.proxy mode 'none'",
+		"gsettings set org.gnome.system.proxy.socks port 0",
+	} {
+		if !strings.Contains(restored, want) {
+			t.Errorf("restore() did not run %q:\n%s", want, restored)
+		}
+	}
+}
+
+func TestGnomeProxy_FailureRestores(t *testing.T) {
+	runner := newFakeRunner()
+	runner.outputs["gsettings get org.gnome.system.proxy mode"] = "'auto'"
+	runner.fail["gsettings set org.gnome.system.proxy mode manual"] = true
+
+	if _, err := (gnomeProxy{runner: runner}).Apply(proxyEndpoint{Host: "127.0.0.1", SocksPort: 1080}); err == nil {
+		t.Fatal("Apply() should fail when gsettings fails")
+	}
+	if len(runner.callsWith("gsettings set org.gnome.system.proxy mode 'auto'")) != 1 {
+		t.Errorf("failed Apply() should restore the previous mode, calls:\n%s", strings.Join(runner.calls, "\n"))
+	}
+}
+
+func TestKDEProxy(t *testing.T) {
+	runner := newFakeRunner()
+	runner.paths["kwriteconfig5"] = true
+	runner.outputs["kreadconfig5 --file kioslaverc --group Proxy Settings --key ProxyType"] = "0"
+
+	restore, err := kdeProxy{runner: runner}.Apply(proxyEndpoint{Host: "127.0.0.1", SocksPort: 1080})
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+
+	writes := strings.Join(runner.callsWith("kwriteconfig5"), "\n")
+	for _, want := range []string{
+		"--key socksProxy socks://127.0.0.1 1080",
+		"--key ProxyType 1",
+	} {
+		if !strings.Contains(writes, want) {
+			t.Errorf("Apply() writes missing %q:\n%s", want, writes)
+		}
+	}
+	if strings.Contains(writes, "httpProxy") {
+		t.Errorf("Apply() without an HTTP port should not set httpProxy:\n%s", writes)
+	}
+
+	runner.calls = nil
+	if err := restore(); err != nil {
+		t.Fatalf("restore() unexpected error: %v", err)
+	}
+	restored := strings.Join(runner.calls, "\n")
+	if !strings.Contains(restored, "--key ProxyType 0") || !strings.Contains(restored, "--key socksProxy --delete") {
+		t.Errorf("restore() calls:\n%s", restored)
+	}
+
+	// Neither kwriteconfig is installed
+	if _, err := (kdeProxy{runner: newFakeRunner()}).Apply(proxyEndpoint{}); err == nil {
+		t.Error("Apply() should fail without kwriteconfig")
+	}
+}
+
+func TestEnvFileProxy(t *testing.T) {
+	t.Chdir(t.TempDir())
+	endpoint := proxyEndpoint{Host: "127.0.0.1", SocksPort: 1080, HTTPPort: 8080, Username: "u", Password: "p"}
+
+	// A file that did not exist is removed on restore
+	restore, err := envFileProxy{path: "proxy.env"}.Apply(endpoint)
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	data, _ := os.ReadFile("proxy.env")
+	for _, want := range []string{
+		"export http_proxy='http://u:p@127.0.0.1:8080'",
+		"export HTTPS_PROXY='http://u:p@127.0.0.1:8080'",
+		"export all_proxy='socks5://u:p@127.0.0.1:1080'",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("env file missing %q:\n%s", want, data)
+		}
+	}
+	if err := restore(); err != nil {
+		t.Fatalf("restore() unexpected error: %v", err)
+	}
+	if _, err := os.Stat("proxy.env"); !os.IsNotExist(err) {
+		t.Error("restore() should remove an env file it created")
+	}
+
+	// Previous content comes back
+	os.WriteFile("proxy.env", []byte("export FOO=bar\n"), 0600)
+	restore, err = envFileProxy{path: "proxy.env"}.Apply(proxyEndpoint{Host: "127.0.0.1", SocksPort: 1080})
+	if err != nil {
+		t.Fatalf("Apply() unexpected error: %v", err)
+	}
+	data, _ = os.ReadFile("proxy.env")
+	if !strings.Contains(string(data), "export http_proxy='socks5://127.0.0.1:1080'") {
+		t.Errorf("without an HTTP port http_proxy should use SOCKS:\n%s", data)
+	}
+	restore()
+	if data, _ := os.ReadFile("proxy.env"); string(data) != "export FOO=bar\n" {
+		t.Errorf("restore() content = %q, want the previous file", data)
+	}
+}
+
+func TestProxyEnvContent_HostileCredentials(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("no POSIX shell to source the env file with")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	password := "p@ss:w'rd $(touch pwned); `touch pwned` ; x"
+	endpoint := proxyEndpoint{Host: "127.0.0.1", SocksPort: 1080, HTTPPort: 8080, Username: "user name", Password: password}
+	if err := os.WriteFile("proxy.env", []byte(proxyEnvContent(endpoint)), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := exec.Command(sh, "-c", `. ./proxy.env && printf '%s\n%s' "$all_proxy" "$HTTP_PROXY"`).Output()
+	if err != nil {
+		t.Fatalf("sourcing the env file failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pwned")); err == nil {
+		t.Fatal("sourcing the env file ran a command from the password")
+	}
+
+	for i, raw := range strings.Split(string(out), "\n") {
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("proxy URL %d = %q does not parse: %v", i, raw, err)
+		}
+		got, _ := u.User.Password()
+		if u.User.Username() != "user name" || got != password || u.Port() == "" {
+			t.Errorf("proxy URL %d = %q, want the credentials back and a port", i, raw)
+		}
+	}
+}
+
+func TestSystemProxy_ConnectDisconnect(t *testing.T) {
+	tui := newTestTUI(t)
+	runner := newFakeRunner()
+	tui.runner = runner
+	tui.configs.Settings = Settings{
+		Inbound:     parser.Inbound{Listen: "0.0.0.0", SocksPort: 7890, Mixed: true},
+		SystemProxy: systemProxyGNOME,
+	}
+
+	note := tui.enableSystemProxy("singbox")
+	if !strings.Contains(note, "GNOME") {
+		t.Errorf("enableSystemProxy() note = %q, want GNOME", note)
+	}
+	// Unspecified listen addresses are reached on loopback; mixed sing-box serves HTTP on the SOCKS port
+	if len(runner.callsWith("gsettings set org.gnome.system.proxy.http port 7890")) != 1 ||
+		len(runner.callsWith("gsettings set org.gnome.system.proxy.socks host 127.0.0.1")) != 1 {
+		t.Errorf("unexpected gsettings calls:\n%s", strings.Join(runner.calls, "\n"))
+	}
+
+	runner.calls = nil
+	tui.resetConnectionState()
+	if len(runner.callsWith("gsettings set org.gnome.system.proxy mode")) != 1 {
+		t.Errorf("disconnect should restore the proxy mode, calls:\n%s", strings.Join(runner.calls, "\n"))
+	}
+
+	// Restoring twice is a no-op
+	runner.calls = nil
+	if err := tui.restoreSystemProxy(); err != nil || len(runner.calls) != 0 {
+		t.Errorf("second restore ran %v, err %v", runner.calls, err)
+	}
+
+	// Off by default
+	tui.configs.Settings.SystemProxy = systemProxyOff
+	if note := tui.enableSystemProxy("v2ray"); note != "" || len(runner.calls) != 0 {
+		t.Errorf("enableSystemProxy() with the feature off = %q, calls %v", note, runner.calls)
+	}
+}
+
+func TestApplySettings_SystemProxyMode(t *testing.T) {
+	tui := newTestTUI(t)
+	if err := tui.applySettings(Settings{SystemProxy: "windows"}); err == nil {
+		t.Error("applySettings() should reject an unknown system proxy mode")
+	}
+	if err := tui.applySettings(Settings{SystemProxy: systemProxyEnv, ProxyEnvFile: "my.env"}); err != nil {
+		t.Errorf("applySettings() unexpected error: %v", err)
+	}
+}
@@ -7,7 +7,16 @@ import (
 // NewTUI creates a new TUI instance
 func NewTUI() *TUI {
 	tui := &TUI{
-		app: tview.NewApplication(),
+		app:           tview.NewApplication(),
+		runner:        execRunner{},
+		supervisor:    newSupervisor(),
+		restartPolicy: defaultRestartPolicy(),
+		latency:       make(map[string]latencyResult),
+		groupMarks:    make(map[string]bool),
+		apiSecret:     newAPISecret(),
+		logs:          newLogBuffer(logCapacity),
+
+		connectionsRefresh: make(chan struct{}, 1),
 	}
 
 	tui.app.EnableMouse(true)
@@ -17,11 +26,15 @@ func NewTUI() *TUI {
 	tui.loadDirectory(tui.currentPath)
 
 	go tui.periodicStatusCheck()
+	go tui.periodicSubscriptionRefresh()
 
 	return tui
 }
 
-// Run starts the TUI application
+// Run starts the TUI application; on exit it stops the client and restores the system proxy
 func (tui *TUI) Run() error {
-	return tui.app.Run()
+	err := tui.app.Run()
+	tui.supervisor.Stop()
+	tui.restoreSystemProxy()
+	return err
 }
@@ -131,6 +131,11 @@ func TestTUI_ParseProxyLink(t *testing.T) {
 			proxyLink:   "vless://12345678-1234-1234-1234-123456789012@example.com:443?encryption=none#Test%20Config",
 			expectError: false,
 		},
+		{
+			name:        "valid trojan link",
+			proxyLink:   "trojan://password@example.com:443?sni=example.com#Test%20Config",
+			expectError: false,
+		},
 		{
 			name:        "empty link",
 			proxyLink:   "",
@@ -213,17 +218,17 @@ func TestTUI_CopyToClipboard(t *testing.T) {
 	// We can't easily test the actual clipboard functionality in unit tests
 }
 
-func TestTUI_IsPort1080InUse(t *testing.T) {
+func TestTUI_IsProxyPortInUse(t *testing.T) {
 	tui := NewTUI()
 
 	// Test port check functionality
 	// Note: This depends on the actual system state
 	// We'll just verify the function returns a boolean
-	result := tui.isPort1080InUse()
+	result := tui.isProxyPortInUse()
 
 	// The function should return a boolean value
 	if result != true && result != false {
-		t.Error("isPort1080InUse() should return a boolean value")
+		t.Error("isProxyPortInUse() should return a boolean value")
 	}
 }
 
@@ -293,3 +298,39 @@ func TestTUI_LoadDirectory(t *testing.T) {
 	// The function should not panic
 	// We can't easily test the file listing without a full UI environment
 }
+
+func TestTUI_MainScreenShortcuts(t *testing.T) {
+	tui := newTestTUI(t)
+	capture := tui.app.GetInputCapture()
+	ctrlE := tcell.NewEventKey(tcell.KeyCtrlE, 0, tcell.ModCtrl)
+
+	tui.app.SetFocus(tui.vmessInput)
+	if capture(ctrlE) != ctrlE {
+		t.Error("Ctrl+E should reach the link input")
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	tui.configs.Configurations = []Config{
+		{ID: "1", Name: "VLESS Node", Protocol: "vless", Link: testVLESSLink, CreatedAt: now, LastUsed: now},
+	}
+	tui.refreshConfigList()
+	tui.app.SetFocus(tui.configList)
+	if capture(ctrlE) != nil || !tui.routingForm.HasFocus() {
+		t.Fatal("Ctrl+E should open routing from the configuration list")
+	}
+
+	// Editing keys in a routing input field must not open screens
+	tui.app.SetFocus(tui.routingInput(routingDirectDomains))
+	if capture(ctrlE) != ctrlE {
+		t.Error("Ctrl+E should reach the routing input field")
+	}
+
+	// Off the main screen the shortcuts do nothing either
+	tui.app.SetFocus(tui.routingForm.GetButton(0))
+	for _, key := range []tcell.Key{tcell.KeyCtrlU, tcell.KeyCtrlK, tcell.KeyCtrlE, tcell.KeyCtrlW} {
+		event := tcell.NewEventKey(key, 0, tcell.ModCtrl)
+		if capture(event) != event {
+			t.Errorf("%s should not fire on the routing screen", event.Name())
+		}
+	}
+}
@@ -0,0 +1,26 @@
+//go:build linux
+
+package tui
+
+import (
+	"encoding/binary"
+	"syscall"
+)
+
+// capNetAdmin is the bit of CAP_NET_ADMIN in the capability sets
+const capNetAdmin = 12
+
+// fileHasNetAdmin reports whether an executable carries CAP_NET_ADMIN as a
+// permitted file capability (set with setcap). Files without the
+// security.capability attribute have none.
+func fileHasNetAdmin(path string) bool {
+	// struct vfs_cap_data: magic_etc, then permitted/inheritable pairs
+	buf := make([]byte, 24)
+	n, err := syscall.Getxattr(path, "security.capability", buf)
+	if err != nil || n < 8 {
+		return false
+	}
+
+	permitted := binary.LittleEndian.Uint32(buf[4:8])
+	return permitted&(1<<capNetAdmin) != 0
+}
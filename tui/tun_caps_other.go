@@ -0,0 +1,8 @@
+//go:build !linux
+
+package tui
+
+// fileHasNetAdmin is Linux only; elsewhere TUN mode needs root
+func fileHasNetAdmin(path string) bool {
+	return false
+}
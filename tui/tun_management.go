@@ -0,0 +1,89 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+
+	"tui_proxy_client/parser"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// clientSingBoxTUN is the client type of sing-box connections running the TUN inbound
+const clientSingBoxTUN = "singbox-tun"
+
+// tunEnvironment is what the TUN privilege check looks at
+type tunEnvironment struct {
+	goos      string
+	euid      int
+	tunDevice string // TUN clone device, Linux only
+	binary    string // resolved sing-box path, empty when not installed
+	netAdmin  func(path string) bool
+}
+
+// currentTUNEnvironment describes the running process and the installed sing-box
+func currentTUNEnvironment() tunEnvironment {
+	env := tunEnvironment{
+		goos:     runtime.GOOS,
+		euid:     os.Geteuid(),
+		netAdmin: fileHasNetAdmin,
+	}
+	if env.goos == "linux" {
+		env.tunDevice = "/dev/net/tun"
+	}
+	env.binary, _ = exec.LookPath("sing-box")
+	return env
+}
+
+// checkTUNPrivileges reports why sing-box could not create a TUN device, nil when it can
+func checkTUNPrivileges(env tunEnvironment) error {
+	if env.binary == "" {
+		return fmt.Errorf("sing-box is not installed or not in PATH")
+	}
+
+	switch env.goos {
+	case "windows":
+		// wintun asks for Administrator rights itself
+		return nil
+	case "linux":
+		if _, err := os.Stat(env.tunDevice); err != nil {
+			return fmt.Errorf("TUN device %s is not available (load the tun kernel module, or pass it to the container)", env.tunDevice)
+		}
+		if env.euid == 0 || env.netAdmin(env.binary) {
+			return nil
+		}
+		return fmt.Errorf("TUN mode needs root or CAP_NET_ADMIN; run as root or grant it with: sudo setcap cap_net_admin,cap_net_bind_service+ep %s", env.binary)
+	default:
+		if env.euid == 0 {
+			return nil
+		}
+		return fmt.Errorf("TUN mode needs root on %s; restart the client with sudo", env.goos)
+	}
+}
+
+// connectSingBoxTUN checks privileges and starts sing-box with the TUN inbound
+func (tui *TUI) connectSingBoxTUN() {
+	if err := checkTUNPrivileges(currentTUNEnvironment()); err != nil {
+		tui.updateStatus(fmt.Sprintf("Error: %v", err), tcell.ColorRed)
+		return
+	}
+
+	tui.connectClient(clientSingBoxTUN, tui.parseForSingBoxTUN, "config.json", []string{"sing-box", "run", "-c", "config.json"})
+}
+
+// parseForSingBoxTUN renders a configuration for sing-box with the TUN inbound enabled
+func (tui *TUI) parseForSingBoxTUN(config Config) (interface{}, error) {
+	opts := tui.renderOptions(config)
+	opts.TUN.Enabled = true
+	return tui.renderConfigWithOptions(config, parser.TargetSingBox, opts)
+}
+
+// tunSettings returns the stored TUN settings, or the defaults when never saved
+func (tui *TUI) tunSettings() parser.TUN {
+	if tui.configs.Settings.TUN == nil {
+		return parser.DefaultTUN()
+	}
+	return tui.configs.Settings.TUN.WithDefaults()
+}
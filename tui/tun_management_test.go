@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"tui_proxy_client/parser"
+
+	"github.com/rivo/tview"
+)
+
+func TestCheckTUNPrivileges(t *testing.T) {
+	device := filepath.Join(t.TempDir(), "tun")
+	if err := os.WriteFile(device, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	noCaps := func(string) bool { return false }
+	netAdmin := func(string) bool { return true }
+
+	tests := []struct {
+		name    string
+		env     tunEnvironment
+		wantErr string
+	}{
+		{
+			name: "linux root",
+			env:  tunEnvironment{goos: "linux", euid: 0, tunDevice: device, binary: "/usr/bin/sing-box", netAdmin: noCaps},
+		},
+		{
+			name: "linux binary with CAP_NET_ADMIN",
+			env:  tunEnvironment{goos: "linux", euid: 1000, tunDevice: device, binary: "/usr/bin/sing-box", netAdmin: netAdmin},
+		},
+		{
+			name:    "linux unprivileged",
+			env:     tunEnvironment{goos: "linux", euid: 1000, tunDevice: device, binary: "/usr/bin/sing-box", netAdmin: noCaps},
+			wantErr: "setcap cap_net_admin",
+		},
+		{
+			name:    "linux without tun device",
+			env:     tunEnvironment{goos: "linux", euid: 0, tunDevice: filepath.Join(device, "missing"), binary: "/usr/bin/sing-box", netAdmin: noCaps},
+			wantErr: "not available",
+		},
+		{
+			name:    "sing-box missing",
+			env:     tunEnvironment{goos: "linux", euid: 0, tunDevice: device, netAdmin: noCaps},
+			wantErr: "not installed",
+		},
+		{
+			name:    "darwin unprivileged",
+			env:     tunEnvironment{goos: "darwin", euid: 501, binary: "/opt/homebrew/bin/sing-box", netAdmin: noCaps},
+			wantErr: "sudo",
+		},
+		{
+			name: "darwin root",
+			env:  tunEnvironment{goos: "darwin", euid: 0, binary: "/opt/homebrew/bin/sing-box", netAdmin: noCaps},
+		},
+		{
+			name: "windows",
+			env:  tunEnvironment{goos: "windows", euid: -1, binary: `C:\sing-box.exe`, netAdmin: noCaps},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkTUNPrivileges(tt.env)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkTUNPrivileges() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkTUNPrivileges() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseForSingBoxTUN(t *testing.T) {
+	tui := newTestTUI(t)
+	config := Config{Protocol: "vless", Link: testVLESSLink}
+
+	// Defaults until settings are saved
+	rendered, err := tui.parseForSingBoxTUN(config)
+	if err != nil {
+		t.Fatalf("parseForSingBoxTUN() unexpected error: %v", err)
+	}
+	tun := rendered.(map[string]any)["inbounds"].([]map[string]any)[0]
+	if tun["type"] != "tun" || tun["stack"] != parser.DefaultTUNStack || tun["auto_route"] != true {
+		t.Errorf("default tun inbound = %v", tun)
+	}
+
+	// The plain sing-box client never gets the TUN inbound
+	plain, err := tui.parseForSingBox(config)
+	if err != nil {
+		t.Fatalf("parseForSingBox() unexpected error: %v", err)
+	}
+	if inbound := plain.(map[string]any)["inbounds"].([]map[string]any)[0]; inbound["type"] == "tun" {
+		t.Error("parseForSingBox() rendered a tun inbound")
+	}
+
+	// Settings saved from the form are applied
+	tui.showSettings()
+	tui.settingsForm.GetFormItemByLabel(settingsTUNStack).(*tview.DropDown).SetCurrentOption(1)
+	tui.settingsForm.GetFormItemByLabel(settingsStrict).(*tview.Checkbox).SetChecked(true)
+	tui.settingsInput(settingsExcludes).SetText("10.0.0.0/8, 192.168.0.0/16")
+	tui.saveSettingsFromForm()
+
+	if tui.configs.Settings.TUN == nil {
+		t.Fatalf("TUN settings not saved, status %q", tui.settingsStatus.GetText(true))
+	}
+
+	rendered, err = tui.parseForSingBoxTUN(config)
+	if err != nil {
+		t.Fatalf("parseForSingBoxTUN() unexpected error: %v", err)
+	}
+	tun = rendered.(map[string]any)["inbounds"].([]map[string]any)[0]
+	if tun["stack"] != parser.TUNStacks[1] || tun["strict_route"] != true {
+		t.Errorf("tun inbound = %v, want %s stack and strict route", tun, parser.TUNStacks[1])
+	}
+	if excludes := tun["route_exclude_address"].([]string); len(excludes) != 2 {
+		t.Errorf("route_exclude_address = %v, want 2 routes", excludes)
+	}
+
+	// Invalid excluded routes are rejected
+	tui.settingsInput(settingsExcludes).SetText("10.0.0.1")
+	tui.saveSettingsFromForm()
+	if !strings.HasPrefix(tui.settingsStatus.GetText(true), "Error:") {
+		t.Errorf("status = %q, want an error for an invalid route", tui.settingsStatus.GetText(true))
+	}
+}
@@ -1,22 +1,68 @@
 package tui
 
 import (
+	"context"
+
+	"tui_proxy_client/parser"
+
 	"github.com/rivo/tview"
 )
 
 // Config represents a single configuration entry
 type Config struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Protocol  string `json:"protocol"`
-	Link      string `json:"link"`
-	CreatedAt string `json:"created_at"`
-	LastUsed  string `json:"last_used"`
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Protocol       string          `json:"protocol"`
+	Link           string          `json:"link"`
+	CreatedAt      string          `json:"created_at"`
+	LastUsed       string          `json:"last_used"`
+	SubscriptionID string          `json:"subscription_id,omitempty"`
+	Routing        *parser.Routing `json:"routing,omitempty"` // nil uses parser.DefaultRouting
+	Chain          []string        `json:"chain,omitempty"`   // hop configuration IDs, entry first, for protocol "chain"
+}
+
+// Subscription represents a remote endpoint serving a list of proxy links
+type Subscription struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	URL            string `json:"url"`
+	UserAgent      string `json:"user_agent,omitempty"`
+	UpdateInterval int    `json:"update_interval_minutes"` // 0 disables periodic refresh
+	LastFetched    string `json:"last_fetched,omitempty"`
+	LastAttempt    string `json:"last_attempt,omitempty"`
+	LastError      string `json:"last_error,omitempty"`
+}
+
+// ConfigGroup is a set of saved configurations connected as one group outbound,
+// so the core picks a server and fails over on its own
+type ConfigGroup struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Members []string     `json:"members"` // configuration IDs
+	Policy  parser.Group `json:"policy"`
+}
+
+// Settings holds the application wide client settings applied to every connection
+type Settings struct {
+	Inbound parser.Inbound `json:"inbound"`
+	DNS     parser.DNS     `json:"dns"`
+	TUN     *parser.TUN    `json:"tun,omitempty"` // nil uses parser.DefaultTUN
+
+	SystemProxy  string `json:"system_proxy,omitempty"`   // off (empty), auto, gnome, kde or env
+	ProxyEnvFile string `json:"proxy_env_file,omitempty"` // env mode target, default proxy.env
+
+	LatencyTest string `json:"latency_test,omitempty"` // tcp (empty) or core
+	LatencyURL  string `json:"latency_url,omitempty"`  // core mode request, default generate_204
+
+	StatsAPI string `json:"stats_api,omitempty"` // host:port polled for traffic statistics, default 127.0.0.1:9090, "off" disables
 }
 
 // ConfigStorage represents the configuration storage structure
 type ConfigStorage struct {
-	Configurations []Config `json:"configurations"`
+	Configurations []Config       `json:"configurations"`
+	Subscriptions  []Subscription `json:"subscriptions,omitempty"`
+	Groups         []ConfigGroup  `json:"groups,omitempty"`
+	Settings       Settings       `json:"settings"`
 	Metadata       struct {
 		Version      string `json:"version"`
 		TotalConfigs int    `json:"total_configs"`
@@ -42,7 +88,80 @@ type TUI struct {
 	isConnected      bool
 	clientType       string
 	connectedConfig  string
+	clientInbound    parser.Inbound // what the running client listens on, saved settings apply at the next connect
 	connectionStatus *tview.TextView
+
+	subscriptionView   *tview.Flex
+	subscriptionList   *tview.List
+	subscriptionForm   *tview.Form
+	subscriptionStatus *tview.TextView
+
+	settingsView   *tview.Flex
+	settingsForm   *tview.Form
+	settingsStatus *tview.TextView
+
+	groupView       *tview.Flex
+	groupList       *tview.List
+	groupMemberList *tview.List
+	groupForm       *tview.Form
+	groupStatus     *tview.TextView
+	groupMarks      map[string]bool // configuration IDs picked for a new group
+	chainView       *tview.Flex
+	chainHopList    *tview.List
+	chainForm       *tview.Form
+	chainStatus     *tview.TextView
+	chainPicks      []string // configuration IDs picked for a new chain, in order
+
+	routingView     *tview.Flex
+	routingForm     *tview.Form
+	routingStatus   *tview.TextView
+	routingConfigID string // ID of the configuration being edited
+
+	// runner executes desktop integration commands such as gsettings
+	runner      commandRunner
+	systemProxy systemProxyState
+
+	// supervisor owns the running client process
+	supervisor    *supervisor
+	restartPolicy restartPolicy
+	restarts      int // restarts since the client last ran stably
+
+	// latency holds the last latency test result per configuration link
+	latency        map[string]latencyResult
+	latencyRunning bool
+
+	// apiSecret protects the Clash API of the clients started in this session
+	apiSecret string
+
+	// traffic is the statistics of the connected client, nil while not polling
+	traffic       *trafficMeter
+	trafficCancel context.CancelFunc
+
+	// connections is the live connections screen of the connected client
+	connectionsView    *tview.Flex
+	connectionsTable   *tview.Table
+	connectionsStatus  *tview.TextView
+	connections        []clashConnection // table rows, newest first
+	connectionsAPI     clashAPI
+	connectionsCancel  context.CancelFunc // stops the refresh, nil while the screen is closed
+	connectionsRefresh chan struct{}      // requests an immediate refresh
+
+	// logs keeps the client output shown in the log viewer
+	logs       *logBuffer
+	logView    *tview.Flex
+	logText    *tview.TextView
+	logForm    *tview.Form
+	logStatus  *tview.TextView
+	logLevel   logLevel // lowest level shown
+	logSearch  string   // case-insensitive substring filter
+	logPaused  bool
+	logPending int // matching lines received while paused
+
+	// shortcutsOff hands every key to the focused text editor instead of the global shortcuts
+	shortcutsOff bool
+
+	// fileSelectHandler is called when a file is picked in the explorer, nil while exporting
+	fileSelectHandler func(path string)
 }
 
 // UIComponents holds references to UI elements for easier access
@@ -19,6 +19,13 @@ func (tui *TUI) setupUI() {
 	tui.buttons = tui.createButtons()
 	tui.fileDialog = tui.createFileDialog()
 	tui.fileExplorer = tui.createFileExplorer()
+	tui.subscriptionView = tui.createSubscriptionView()
+	tui.settingsView = tui.createSettingsView()
+	tui.routingView = tui.createRoutingView()
+	tui.groupView = tui.createGroupView()
+	tui.chainView = tui.createChainView()
+	tui.connectionsView = tui.createConnectionsView()
+	tui.logView = tui.createLogView()
 
 	configSection := tview.NewFlex().SetDirection(tview.FlexColumn).
 		AddItem(tui.configText, 0, 2, false).
@@ -57,16 +64,16 @@ func (tui *TUI) createConnectionStatus() *tview.TextView {
 func (tui *TUI) createVMessInput() *tview.InputField {
 	input := tview.NewInputField()
 	input.SetLabel("Proxy Link: ")
-	input.SetPlaceholder("Supported: vmess, vless and ss")
+	input.SetPlaceholder("Supported: vmess, vless, ss, trojan, hysteria2 and tuic")
 	input.SetFieldWidth(80)
 	input.SetBorder(true)
-	input.SetTitle(" Enter Proxy Configuration (VMess/SS/VLESS) ")
+	input.SetTitle(" Enter Proxy Configuration (VMess/SS/VLESS/Trojan/Hysteria2/TUIC) ")
 	return input
 }
 
 func (tui *TUI) createStatusText() *tview.TextView {
 	text := tview.NewTextView()
-	text.SetText("Ready to parse proxy configuration (VMess/SS/VLESS)")
+	text.SetText("Ready to parse proxy configuration (VMess/SS/VLESS/Trojan/Hysteria2/TUIC)")
 	text.SetTextAlign(tview.AlignCenter)
 	text.SetTextColor(tcell.ColorGreen)
 	text.SetBorder(true)
@@ -124,6 +131,16 @@ func (tui *TUI) createButtons() *tview.Flex {
 			tui.addConfig()
 		})
 
+	bulkImportBtn := tview.NewButton("Bulk Import\n(Ctrl+B)").
+		SetSelectedFunc(func() {
+			tui.showBulkImport()
+		})
+
+	importFileBtn := tview.NewButton("Import File\n(Ctrl+O)").
+		SetSelectedFunc(func() {
+			tui.showImportFileDialog()
+		})
+
 	exportBtn := tview.NewButton("Export Config\n(Ctrl+S)").
 		SetSelectedFunc(func() {
 			tui.exportConfig()
@@ -149,11 +166,56 @@ func (tui *TUI) createButtons() *tview.Flex {
 			tui.renameSelectedConfig()
 		})
 
+	shareBtn := tview.NewButton("Share Link\n(Ctrl+K)").
+		SetSelectedFunc(func() {
+			tui.shareSelectedConfig()
+		})
+
+	routingBtn := tview.NewButton("Routing\n(Ctrl+E)").
+		SetSelectedFunc(func() {
+			tui.showRouting()
+		})
+
+	groupsBtn := tview.NewButton("Groups\n(Ctrl+G)").
+		SetSelectedFunc(func() {
+			tui.showGroups()
+		})
+
+	chainsBtn := tview.NewButton("Chains\n(Ctrl+N)").
+		SetSelectedFunc(func() {
+			tui.showChains()
+		})
+
+	connectionsBtn := tview.NewButton("Connections\n(Ctrl+W)").
+		SetSelectedFunc(func() {
+			tui.showConnections()
+		})
+
+	logsBtn := tview.NewButton("Logs\n(Ctrl+Y)").
+		SetSelectedFunc(func() {
+			tui.showLogs()
+		})
+
+	subscriptionsBtn := tview.NewButton("Subscriptions\n(Ctrl+U)").
+		SetSelectedFunc(func() {
+			tui.showSubscriptions()
+		})
+
+	settingsBtn := tview.NewButton("Settings\n(Ctrl+P)").
+		SetSelectedFunc(func() {
+			tui.showSettings()
+		})
+
 	refreshBtn := tview.NewButton("Refresh\n(Ctrl+F)").
 		SetSelectedFunc(func() {
 			tui.refreshConfigurations()
 		})
 
+	latencyBtn := tview.NewButton("Test Latency\n(Ctrl+T)").
+		SetSelectedFunc(func() {
+			tui.testLatency()
+		})
+
 	clearBtn := tview.NewButton("Clear\n(Ctrl+L)").
 		SetSelectedFunc(func() {
 			tui.clearUI()
@@ -166,6 +228,8 @@ func (tui *TUI) createButtons() *tview.Flex {
 
 	return tview.NewFlex().
 		AddItem(addConfigBtn, 0, 1, false).
+		AddItem(bulkImportBtn, 0, 1, false).
+		AddItem(importFileBtn, 0, 1, false).
 		AddItem(exportBtn, 0, 1, false).
 		AddItem(connectBtn, 0, 1, false).
 		AddItem(disconnectBtn, 0, 1, false).
@@ -173,7 +237,16 @@ func (tui *TUI) createButtons() *tview.Flex {
 		AddItem(tview.NewBox(), 1, 0, false). // <--- Spacer, 1 column wide
 
 		AddItem(renameBtn, 0, 1, false).
+		AddItem(shareBtn, 0, 1, false).
+		AddItem(routingBtn, 0, 1, false).
+		AddItem(groupsBtn, 0, 1, false).
+		AddItem(chainsBtn, 0, 1, false).
+		AddItem(connectionsBtn, 0, 1, false).
+		AddItem(logsBtn, 0, 1, false).
+		AddItem(subscriptionsBtn, 0, 1, false).
+		AddItem(settingsBtn, 0, 1, false).
 		AddItem(refreshBtn, 0, 1, false).
+		AddItem(latencyBtn, 0, 1, false).
 		AddItem(clearBtn, 0, 1, false).
 		AddItem(quitBtn, 0, 1, false)
 }
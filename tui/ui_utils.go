@@ -1,16 +1,14 @@
 package tui
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
 	"time"
 
-	"tui_proxy_client/parser"
-
 	"github.com/gdamore/tcell/v2"
 )
 
@@ -29,25 +27,54 @@ func (tui *TUI) updateStatus(message string, color tcell.Color) {
 	tui.statusText.SetText(message).SetTextColor(color)
 }
 
-// updateConnectionStatus checks port 1080 and updates UI connection state
+// updateConnectionStatus checks the supervised client and the proxy port and updates UI connection state
 func (tui *TUI) updateConnectionStatus() {
-	portInUse := tui.isPort1080InUse()
+	port := tui.proxyPort()
+	portInUse := tui.isProxyPortInUse()
+	running := tui.supervisor.Running() != nil
+
+	restarts := ""
+	if tui.restarts > 0 {
+		restarts = fmt.Sprintf(" - Restarts: %d/%d", tui.restarts, tui.restartPolicy.MaxRetries)
+	}
+
+	traffic := ""
+	if text := tui.traffic.String(); text != "" {
+		traffic = " - " + text
+	}
 
 	switch {
-	case tui.isConnected && portInUse:
-		tui.connectionStatus.SetText(fmt.Sprintf("Status: Connected to %s (%s) - Port 1080 Active", tui.connectedConfig, tui.clientType)).
+	case tui.isConnected && running && portInUse:
+		tui.connectionStatus.SetText(fmt.Sprintf("Status: Connected to %s (%s) - Port %d Active%s%s", tui.connectedConfig, tui.clientType, port, restarts, traffic)).
 			SetTextColor(tcell.ColorGreen)
 
-	case tui.isConnected && !portInUse:
+	case tui.isConnected && running:
+		tui.connectionStatus.SetText(fmt.Sprintf("Status: Connected to %s (%s) - Waiting for Port %d%s", tui.connectedConfig, tui.clientType, port, restarts)).
+			SetTextColor(tcell.ColorYellow)
+
+	case tui.isConnected && tui.supervisor.Restarting():
+		tui.connectionStatus.SetText(fmt.Sprintf("Status: Restarting %s (%s)%s", tui.connectedConfig, tui.clientType, restarts)).
+			SetTextColor(tcell.ColorYellow)
+
+	case tui.isConnected:
 		tui.isConnected = false
 		tui.clientType, tui.connectedConfig = "", ""
-		tui.connectionStatus.SetText("Status: Not Connected (Port 1080 Free)").
-			SetTextColor(tcell.ColorRed)
+		tui.setNotConnectedStatus(portInUse)
 
 	default:
-		tui.connectionStatus.SetText("Status: Not Connected (Port 1080 Free)").
-			SetTextColor(tcell.ColorRed)
+		tui.setNotConnectedStatus(portInUse)
+	}
+}
+
+// setNotConnectedStatus shows the disconnected state and whether another program holds the port
+func (tui *TUI) setNotConnectedStatus(portInUse bool) {
+	if portInUse {
+		tui.connectionStatus.SetText(fmt.Sprintf("Status: Not Connected (Port %d In Use by Another Program)", tui.proxyPort())).
+			SetTextColor(tcell.ColorYellow)
+		return
 	}
+	tui.connectionStatus.SetText(fmt.Sprintf("Status: Not Connected (Port %d Free)", tui.proxyPort())).
+		SetTextColor(tcell.ColorRed)
 }
 
 // parseProxyLink converts a proxy link into a SingBox JSON config and displays it
@@ -58,26 +85,11 @@ func (tui *TUI) parseProxyLink() {
 		return
 	}
 
-	// Detect protocol and parse accordingly
-	var config interface{}
-	var protocol string
-	var err error
-
-	switch {
-	case strings.HasPrefix(proxyLink, "vmess://"):
-		protocol = "vmess"
-		config, err = parser.VMessToSingBox(proxyLink)
-	case strings.HasPrefix(proxyLink, "ss://"):
-		protocol = "shadowsocks"
-		config, err = parser.SSToSingBox(proxyLink)
-	case strings.HasPrefix(proxyLink, "vless://"):
-		protocol = "vless"
-		config, err = parser.VLESSToSingBox(proxyLink)
-	default:
-		tui.updateStatus("Error: Invalid proxy link format. Must start with 'vmess://', 'ss://', or 'vless://'", tcell.ColorRed)
+	protocol, config, err := parseLinkForSingBox(proxyLink)
+	if errors.Is(err, errUnsupportedLink) {
+		tui.updateStatus("Error: Invalid proxy link format. Must start with 'vmess://', 'ss://', 'vless://', 'trojan://', 'hysteria2://', or 'tuic://'", tcell.ColorRed)
 		return
 	}
-
 	if err != nil {
 		tui.updateStatus(fmt.Sprintf("Error parsing %s: %v", protocol, err), tcell.ColorRed)
 		return
@@ -118,25 +130,15 @@ func (tui *TUI) handlePaste() {
 	}
 }
 
-// isPort1080InUse checks if TCP port 1080 is active
-func (tui *TUI) isPort1080InUse() bool {
-	return tui.runPortCheck("lsof -ti:1080 2>/dev/null")
-}
-
-// runPortCheck executes a shell command to check port usage
-func (tui *TUI) runPortCheck(cmdStr string) bool {
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "sh", "-c", cmdStr)
-	output, err := cmd.Output()
-	if err != nil {
-		return false
+// isProxyPortInUse checks if the local proxy port of the running client, or
+// the configured one when disconnected, is active
+func (tui *TUI) isProxyPortInUse() bool {
+	in := tui.activeInbound()
+	// A supervised client may still be starting; binding its port could take it away
+	if tui.supervisor.Running() != nil || tui.supervisor.Restarting() {
+		return portAccepting(in.Listen, in.SocksPort)
 	}
-
-	portOutput := strings.TrimSpace(string(output))
-
-	return len(portOutput) > 0
+	return portInUse(in.Listen, in.SocksPort)
 }
 
 // copyToClipboard copies text to clipboard depending on OS